@@ -1,9 +1,15 @@
 package controller
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/citation"
 	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/label"
 	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
-	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/marc"
 	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -24,7 +30,7 @@ func (c *controller) createBook(ctx *fiber.Ctx) error {
 }
 
 func (c *controller) findAllBooks(ctx *fiber.Ctx) error {
-	filter := new(model.QueryParam)
+	filter := new(book.BookQuery)
 	if err := ctx.QueryParser(filter); err != nil {
 		return exception.Handler(ctx, err)
 	}
@@ -43,7 +49,9 @@ func (c *controller) findBookByID(ctx *fiber.Ctx) error {
 		return exception.ErrorBadRequest("Invalid ID")
 	}
 
-	book, err := c.BookService.FindByID(ctx.Context(), *id)
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	book, err := c.BookService.FindByID(ctx.Context(), *id, *lib.StrToUUID(claims.Issuer))
 	if err != nil {
 		return exception.Handler(ctx, err)
 	}
@@ -82,3 +90,163 @@ func (c *controller) deleteBook(ctx *fiber.Ctx) error {
 
 	return lib.OK(ctx)
 }
+
+func (c *controller) exportCatalog(ctx *fiber.Ctx) error {
+	return sendAttachment(ctx, "catalog", "xlsx", lib.ExcelContentType, func(w io.Writer) error {
+		return c.BookService.ExportCatalog(ctx.Context(), w)
+	})
+}
+
+func (c *controller) bulkUpdateBooks(ctx *fiber.Ctx) error {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("file is required"))
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Failed to read file"))
+	}
+	defer file.Close()
+
+	res, err := c.BookService.BulkUpdate(ctx.Context(), file)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) suggestBooks(ctx *fiber.Ctx) error {
+	res, err := c.BookService.Suggest(ctx.Context(), ctx.Query("q"), ctx.QueryInt("limit"))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) findBookAvailability(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	loc, err := c.location(ctx)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.BorrowService.Availability(ctx.Context(), *id, ctx.QueryInt("days"), loc)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) importMarcBooks(ctx *fiber.Ctx) error {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("file is required"))
+	}
+
+	api := new(book.MarcImportRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Failed to read file"))
+	}
+	defer file.Close()
+
+	res, err := c.BookService.ImportMarc(ctx.Context(), file, api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) exportBookMarcXML(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	var buf bytes.Buffer
+	if err := c.BookService.ExportMarcXML(ctx.Context(), *id, &buf); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	ctx.Set(fiber.HeaderContentType, marc.XMLContentType)
+	return ctx.Send(buf.Bytes())
+}
+
+func (c *controller) citeBook(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	return sendCitation(ctx, func(format citation.Format, w io.Writer) error {
+		return c.BookService.Cite(ctx.Context(), *id, format, w)
+	})
+}
+
+// citeBooks cite a search result, the same filters as the book list are accepted
+func (c *controller) citeBooks(ctx *fiber.Ctx) error {
+	filter := new(book.BookQuery)
+	if err := ctx.QueryParser(filter); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return sendCitation(ctx, func(format citation.Format, w io.Writer) error {
+		return c.BookService.CiteAll(ctx.Context(), filter, format, w)
+	})
+}
+
+// sendCitation send citations in the format of ?format=, bibtex by default
+func sendCitation(ctx *fiber.Ctx, write func(format citation.Format, w io.Writer) error) error {
+	format, err := citation.Lookup(ctx.Query("format", "bibtex"))
+	if err != nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest(err.Error()))
+	}
+
+	var buf bytes.Buffer
+	if err := write(format, &buf); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	ctx.Set(fiber.HeaderContentType, format.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=citation.%s", format.Extension))
+	return ctx.Send(buf.Bytes())
+}
+
+func (c *controller) findLabelLayouts(ctx *fiber.Ctx) error {
+	res := make([]label.Layout, 0)
+	for _, name := range label.LayoutNames() {
+		layout, _ := label.LookupLayout(name)
+		res = append(res, layout)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) printBookLabels(ctx *fiber.Ctx) error {
+	api := new(book.LabelRequest)
+	if err := ctx.BodyParser(api); err != nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid request body"))
+	}
+
+	var buf bytes.Buffer
+	if err := c.BookService.Labels(ctx.Context(), api, &buf); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	ctx.Set(fiber.HeaderContentType, label.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, "inline; filename=labels.pdf")
+	return ctx.Send(buf.Bytes())
+}
@@ -1,14 +1,14 @@
 package controller
 
 import (
-	"fmt"
-	"strconv"
-	"time"
+	"io"
 
 	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exporter"
 	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
 	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 func (c *controller) borrowBook(ctx *fiber.Ctx) error {
@@ -17,15 +17,21 @@ func (c *controller) borrowBook(ctx *fiber.Ctx) error {
 		return exception.Handler(ctx, exception.ErrorBadRequest(err.Error()))
 	}
 
-	claims := ctx.Locals("claims").(*lib.Claims)
-	req.UserID = *lib.StrToUUID(claims.Issuer)
+	if err := c.setBorrower(ctx, req); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	loc, err := c.location(ctx)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
 
 	res, err := c.BorrowService.Borrow(ctx.Context(), req)
 	if err != nil {
 		return exception.Handler(ctx, err)
 	}
 
-	return lib.Created(ctx, res)
+	return lib.Created(ctx, res.In(loc))
 }
 
 func (c *controller) returnBook(ctx *fiber.Ctx) error {
@@ -34,8 +40,9 @@ func (c *controller) returnBook(ctx *fiber.Ctx) error {
 		return exception.Handler(ctx, exception.ErrorBadRequest(err.Error()))
 	}
 
-	claims := ctx.Locals("claims").(*lib.Claims)
-	req.UserID = *lib.StrToUUID(claims.Issuer)
+	if err := c.setBorrower(ctx, req); err != nil {
+		return exception.Handler(ctx, err)
+	}
 
 	if err := c.BorrowService.Return(ctx.Context(), req); err != nil {
 		return exception.Handler(ctx, err)
@@ -44,10 +51,45 @@ func (c *controller) returnBook(ctx *fiber.Ctx) error {
 	return lib.OK(ctx)
 }
 
-func (c *controller) findAllBorrows(ctx *fiber.Ctx) error {
+// setBorrower set the caller as borrower, or the holder of the scanned card when the caller is staff
+func (c *controller) setBorrower(ctx *fiber.Ctx, req *book.BorrowRequest) error {
+	claims := ctx.Locals("claims").(*lib.Claims)
+	req.UserID = *lib.StrToUUID(claims.Issuer)
+
+	if claims.IsAdmin && req.CardNumber != "" {
+		u, err := c.UserService.FindByCardNumber(ctx.Context(), req.CardNumber)
+		if err != nil {
+			return err
+		}
+		req.UserID = u.ID
+	}
+
+	return nil
+}
+
+// parseBorrowQuery parse borrow filter with dates in the caller zone
+func (c *controller) parseBorrowQuery(ctx *fiber.Ctx) (*book.BorrowQuery, error) {
 	filter := new(book.BorrowQuery)
 	if err := ctx.QueryParser(filter); err != nil {
-		return exception.Handler(ctx, exception.ErrorBadRequest(err.Error()))
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	loc, err := c.location(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := filter.Localize(loc); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	return filter, nil
+}
+
+func (c *controller) findAllBorrows(ctx *fiber.Ctx) error {
+	filter, err := c.parseBorrowQuery(ctx)
+	if err != nil {
+		return exception.Handler(ctx, err)
 	}
 
 	claims := ctx.Locals("claims").(*lib.Claims)
@@ -64,21 +106,82 @@ func (c *controller) findAllBorrows(ctx *fiber.Ctx) error {
 }
 
 func (c *controller) generateBorrowExcel(ctx *fiber.Ctx) error {
-	filter := new(book.BorrowQuery)
-	if err := ctx.QueryParser(filter); err != nil {
+	return c.exportBorrowsAs(ctx, "xlsx", false)
+}
+
+// exportBorrows export borrows in the requested format, non admin only get their own borrows
+func (c *controller) exportBorrows(ctx *fiber.Ctx) error {
+	return c.exportBorrowsAs(ctx, ctx.Query("format", "xlsx"), true)
+}
+
+func (c *controller) exportBorrowsAs(ctx *fiber.Ctx, format string, scoped bool) error {
+	filter, err := c.parseBorrowQuery(ctx)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+	if scoped && !claims.IsAdmin {
+		filter.UserID = *lib.StrToUUID(claims.Issuer)
+	}
+
+	f, err := exporter.Lookup(format)
+	if err != nil {
 		return exception.Handler(ctx, exception.ErrorBadRequest(err.Error()))
 	}
 
-	timezone, _ := strconv.Atoi(ctx.Query("timezone"))
+	return sendAttachment(ctx, "borrows", f.Extension, f.ContentType, func(w io.Writer) error {
+		return c.BorrowService.Export(ctx.Context(), filter, f, w, nil)
+	})
+}
+
+func (c *controller) createBorrowExport(ctx *fiber.Ctx) error {
+	filter, err := c.parseBorrowQuery(ctx)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+	if !claims.IsAdmin {
+		filter.UserID = *lib.StrToUUID(claims.Issuer)
+	}
+
+	res, err := c.ExportService.Create(ctx.Context(), *lib.StrToUUID(claims.Issuer), filter, ctx.Query("format", "xlsx"))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Created(ctx, res)
+}
+
+func (c *controller) findBorrowExport(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.ExportService.FindByID(ctx.Context(), *id, *lib.StrToUUID(claims.Issuer), claims.IsAdmin)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) downloadBorrowExport(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
 
-	file, err := c.BorrowService.GenerateExcel(ctx.Context(), filter, timezone)
+	path, err := c.ExportService.Download(ctx.Context(), *id, *lib.StrToUUID(claims.Issuer), claims.IsAdmin)
 	if err != nil {
 		return exception.Handler(ctx, err)
 	}
 
-	name := "excel-" + time.Now().Format("20060102150405") + ".xlsx"
-	ctx.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s", name))
-	ctx.Set(fiber.HeaderContentLength, fmt.Sprint(file.Len()))
-	return ctx.SendStream(file)
+	return ctx.Download(path)
 }
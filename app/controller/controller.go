@@ -1,9 +1,30 @@
 package controller
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/dikyayodihamzah/library-management-api/app/service/booksvc"
 	"github.com/dikyayodihamzah/library-management-api/app/service/borrowsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/exportsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/feedsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/locationsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/notificationsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/oaisvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/readinglistsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/recommendationsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/reportsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/reviewsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/schedulersvc"
 	"github.com/dikyayodihamzah/library-management-api/app/service/usersvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/worksvc"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
 	"github.com/dikyayodihamzah/library-management-api/pkg/middleware"
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,20 +34,53 @@ type Controller interface {
 }
 
 type controller struct {
-	UserService   usersvc.UserService
-	BookService   booksvc.BookService
-	BorrowService borrowsvc.BorrowService
+	UserService           usersvc.UserService
+	BookService           booksvc.BookService
+	BorrowService         borrowsvc.BorrowService
+	ReportService         reportsvc.ReportService
+	ExportService         exportsvc.ExportService
+	ReviewService         reviewsvc.ReviewService
+	ReadingListService    readinglistsvc.ReadingListService
+	RecommendationService recommendationsvc.RecommendationService
+	WorkService           worksvc.WorkService
+	LocationService       locationsvc.LocationService
+	OAIService            oaisvc.OAIService
+	FeedService           feedsvc.FeedService
+	NotificationService   notificationsvc.NotificationService
+	SchedulerService      schedulersvc.SchedulerService
 }
 
 func New(
 	userService usersvc.UserService,
 	bookService booksvc.BookService,
 	borrowService borrowsvc.BorrowService,
+	reportService reportsvc.ReportService,
+	exportService exportsvc.ExportService,
+	reviewService reviewsvc.ReviewService,
+	readingListService readinglistsvc.ReadingListService,
+	recommendationService recommendationsvc.RecommendationService,
+	workService worksvc.WorkService,
+	locationService locationsvc.LocationService,
+	oaiService oaisvc.OAIService,
+	feedService feedsvc.FeedService,
+	notificationService notificationsvc.NotificationService,
+	schedulerService schedulersvc.SchedulerService,
 ) Controller {
 	return &controller{
-		UserService:   userService,
-		BookService:   bookService,
-		BorrowService: borrowService,
+		UserService:           userService,
+		BookService:           bookService,
+		BorrowService:         borrowService,
+		ReportService:         reportService,
+		ExportService:         exportService,
+		ReviewService:         reviewService,
+		ReadingListService:    readingListService,
+		RecommendationService: recommendationService,
+		WorkService:           workService,
+		LocationService:       locationService,
+		OAIService:            oaiService,
+		FeedService:           feedService,
+		NotificationService:   notificationService,
+		SchedulerService:      schedulerService,
 	}
 }
 
@@ -35,20 +89,193 @@ func (c *controller) Routes(app *fiber.App) {
 	app.Post("/login", c.login)
 	app.Post("/logout", c.logout)
 
-	userAPI := app.Group("/users").Use(middleware.IsAuthenticated, middleware.IsAdmin)
-	userAPI.Get("/", c.findAllUsers)
-	userAPI.Post("/assign-admin/:id", c.assignAdmin)
+	// catalog harvesting is public
+	app.Get("/oai", c.oai)
+	app.Post("/oai", c.oai)
+	app.Get("/feeds/new-arrivals.atom", c.newArrivalsFeed)
+
+	// calendar clients cannot send a JWT, the feed is protected by its own token
+	app.Get("/users/me/loans.ics", c.findMyLoanCalendar)
+
+	userAPI := app.Group("/users").Use(middleware.IsAuthenticated)
+	userAPI.Get("/", middleware.IsAdmin, c.findAllUsers)
+	userAPI.Get("/me", c.findMyProfile)
+	userAPI.Put("/me", c.updateMyProfile)
+	userAPI.Get("/me/history", c.findMyHistory)
+	userAPI.Get("/me/recommendations", c.findMyRecommendations)
+	userAPI.Post("/me/calendar-token", c.createCalendarToken)
+	userAPI.Delete("/me/calendar-token", c.revokeCalendarToken)
+	userAPI.Get("/me/card", c.findMyCard)
+	userAPI.Get("/me/notification-preferences", c.findMyNotificationPreferences)
+	userAPI.Put("/me/notification-preferences", c.updateMyNotificationPreferences)
+	userAPI.Get("/card/:number", middleware.IsAdmin, c.findUserByCard)
+	userAPI.Post("/assign-admin/:id", middleware.IsAdmin, c.assignAdmin)
 
 	bookAPI := app.Group("/books").Use(middleware.IsAuthenticated)
 	bookAPI.Post("/", middleware.IsAdmin, c.createBook)
 	bookAPI.Get("/", c.findAllBooks)
+	bookAPI.Get("/suggest", c.suggestBooks)
+	bookAPI.Get("/cite", c.citeBooks)
+	bookAPI.Get("/export.xlsx", middleware.IsAdmin, c.exportCatalog)
+	bookAPI.Post("/bulk-update", middleware.IsAdmin, c.bulkUpdateBooks)
+	bookAPI.Post("/import/marc", middleware.IsAdmin, c.importMarcBooks)
+	bookAPI.Get("/labels/layouts", middleware.IsAdmin, c.findLabelLayouts)
+	bookAPI.Post("/labels", middleware.IsAdmin, c.printBookLabels)
+	bookAPI.Get("/:id.marcxml", c.exportBookMarcXML)
 	bookAPI.Get("/:id", c.findBookByID)
 	bookAPI.Put("/:id", middleware.IsAdmin, c.updateBook)
 	bookAPI.Delete("/:id", middleware.IsAdmin, c.deleteBook)
+	bookAPI.Get("/:id/reviews", c.findBookReviews)
+	bookAPI.Post("/:id/reviews", c.createReview)
+	bookAPI.Get("/:id/also-borrowed", c.findAlsoBorrowed)
+	bookAPI.Get("/:id/availability", c.findBookAvailability)
+	bookAPI.Get("/:id/cite", c.citeBook)
+
+	reviewAPI := app.Group("/reviews").Use(middleware.IsAuthenticated)
+	reviewAPI.Get("/", middleware.IsAdmin, c.findAllReviews)
+	reviewAPI.Put("/:id", c.updateReview)
+	reviewAPI.Delete("/:id", c.deleteReview)
+	reviewAPI.Put("/:id/moderate", middleware.IsAdmin, c.moderateReview)
+
+	// shared lists are public, registered before the group so login is not required
+	app.Get("/lists/shared/:token", c.findSharedReadingList)
+
+	listAPI := app.Group("/lists").Use(middleware.IsAuthenticated)
+	listAPI.Get("/", c.findMyReadingLists)
+	listAPI.Post("/", c.createReadingList)
+	listAPI.Get("/:id", c.findReadingListByID)
+	listAPI.Put("/:id", c.updateReadingList)
+	listAPI.Delete("/:id", c.deleteReadingList)
+	listAPI.Post("/:id/items", c.addReadingListItem)
+	listAPI.Put("/:id/items", c.reorderReadingList)
+	listAPI.Delete("/:id/items/:bookId", c.removeReadingListItem)
+	listAPI.Get("/:id/cite", c.citeReadingList)
+
+	seriesAPI := app.Group("/series").Use(middleware.IsAuthenticated)
+	seriesAPI.Post("/", middleware.IsAdmin, c.createSeries)
+	seriesAPI.Get("/", c.findAllSeries)
+	seriesAPI.Get("/:id", c.findSeriesByID)
+	seriesAPI.Put("/:id", middleware.IsAdmin, c.updateSeries)
+	seriesAPI.Delete("/:id", middleware.IsAdmin, c.deleteSeries)
+
+	workAPI := app.Group("/works").Use(middleware.IsAuthenticated)
+	workAPI.Post("/", middleware.IsAdmin, c.createWork)
+	workAPI.Get("/", c.findAllWorks)
+	workAPI.Get("/:id", c.findWorkByID)
+	workAPI.Put("/:id", middleware.IsAdmin, c.updateWork)
+	workAPI.Delete("/:id", middleware.IsAdmin, c.deleteWork)
+
+	locationAPI := app.Group("/locations").Use(middleware.IsAuthenticated)
+	locationAPI.Post("/", middleware.IsAdmin, c.createLocation)
+	locationAPI.Get("/", c.findAllLocations)
+	locationAPI.Get("/:id", c.findLocationByID)
+	locationAPI.Put("/:id", middleware.IsAdmin, c.updateLocation)
+	locationAPI.Delete("/:id", middleware.IsAdmin, c.deleteLocation)
+
+	shelfAPI := app.Group("/shelves").Use(middleware.IsAuthenticated)
+	shelfAPI.Get("/:id/books", c.findShelfBooks)
 
 	borrowAPI := app.Group("/borrows").Use(middleware.IsAuthenticated)
 	borrowAPI.Post("/", c.borrowBook)
 	borrowAPI.Post("/return", c.returnBook)
 	borrowAPI.Get("/", c.findAllBorrows)
 	borrowAPI.Get("/excel", c.generateBorrowExcel)
+	borrowAPI.Get("/export", c.exportBorrows)
+	borrowAPI.Post("/exports", c.createBorrowExport)
+	borrowAPI.Get("/exports/:id", c.findBorrowExport)
+	borrowAPI.Get("/exports/:id/download", c.downloadBorrowExport)
+
+	reportAPI := app.Group("/reports").Use(middleware.IsAuthenticated, middleware.IsAdmin)
+	reportAPI.Get("/most-borrowed", c.mostBorrowedReport)
+	reportAPI.Get("/revenue", c.revenueReport)
+	reportAPI.Get("/members", c.memberReport)
+	reportAPI.Get("/return-time", c.returnTimeReport)
+	reportAPI.Get("/utilization", c.utilizationReport)
+
+	notificationAPI := app.Group("/notifications").Use(middleware.IsAuthenticated)
+	notificationAPI.Get("/", c.findAllNotifications)
+	notificationAPI.Post("/:id/retry", middleware.IsAdmin, c.retryNotification)
+
+	jobAPI := app.Group("/jobs").Use(middleware.IsAuthenticated, middleware.IsAdmin)
+	jobAPI.Get("/", c.findAllJobs)
+	jobAPI.Get("/runs", c.findAllJobRuns)
+	jobAPI.Post("/:name/trigger", c.triggerJob)
+	jobAPI.Post("/:name/pause", c.pauseJob)
+	jobAPI.Post("/:name/resume", c.resumeJob)
+}
+
+// location resolve zone of the request from ?tz= override,
+// then legacy ?timezone= hour offset, then profile preference of the caller
+func (c *controller) location(ctx *fiber.Ctx) (*time.Location, error) {
+	if tz := ctx.Query("tz"); tz != "" {
+		loc, err := lib.LoadLocation(tz)
+		if err != nil {
+			return nil, exception.ErrorBadRequest("tz must be a valid IANA timezone, e.g. Asia/Jakarta")
+		}
+		return loc, nil
+	}
+
+	if offset := ctx.Query("timezone"); offset != "" {
+		hours, err := strconv.Atoi(offset)
+		if err != nil {
+			return nil, exception.ErrorBadRequest("timezone must be an hour offset")
+		}
+
+		loc, err := lib.OffsetLocation(hours)
+		if err != nil {
+			return nil, exception.ErrorBadRequest("timezone must be an hour offset")
+		}
+		return loc, nil
+	}
+
+	claims, ok := ctx.Locals("claims").(*lib.Claims)
+	if !ok {
+		return lib.DefaultLocation(), nil
+	}
+
+	return c.UserService.Location(ctx.Context(), *lib.StrToUUID(claims.Issuer))
+}
+
+func attachmentName(name, ext string) string {
+	return strings.ReplaceAll(strings.ToLower(name), " ", "-") + "-" + time.Now().Format("20060102150405") + "." + ext
+}
+
+// sendExcel send excel buffer as attachment
+func sendExcel(ctx *fiber.Ctx, name string, file *bytes.Buffer) error {
+	ctx.Set(fiber.HeaderContentType, lib.ExcelContentType)
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s", attachmentName(name, "xlsx")))
+	ctx.Set(fiber.HeaderContentLength, fmt.Sprint(file.Len()))
+	return ctx.SendStream(file)
+}
+
+// sendAttachment write a generated file to a temporary file first and stream it as attachment,
+// so large files never have to be held in memory
+func sendAttachment(ctx *fiber.Ctx, name, ext, contentType string, write func(w io.Writer) error) error {
+	file, err := os.CreateTemp("", "attachment-*."+ext)
+	if err != nil {
+		return exception.Handler(ctx, exception.ErrorInternal("Failed to create file"))
+	}
+
+	// unlink right away, the open file is removed once the response is sent
+	os.Remove(file.Name())
+
+	if err := write(file); err != nil {
+		file.Close()
+		return exception.Handler(ctx, err)
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		file.Close()
+		return exception.Handler(ctx, exception.ErrorInternal("Failed to read file"))
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return exception.Handler(ctx, exception.ErrorInternal("Failed to read file"))
+	}
+
+	ctx.Set(fiber.HeaderContentType, contentType)
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s", attachmentName(name, ext)))
+	return ctx.SendStream(file, int(size))
 }
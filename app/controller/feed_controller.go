@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/feed"
+	"github.com/gofiber/fiber/v2"
+)
+
+// newArrivalsFeed serve the newest books as Atom,
+// readers polling an unchanged feed get 304 through ETag or Last-Modified
+func (c *controller) newArrivalsFeed(ctx *fiber.Ctx) error {
+	filter := new(book.BookQuery)
+	if err := ctx.QueryParser(filter); err != nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid query"))
+	}
+
+	res, updated, err := c.FeedService.NewArrivals(ctx.Context(), filter, ctx.BaseURL()+ctx.OriginalURL())
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	body, err := xml.Marshal(res)
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusInternalServerError)
+	}
+	body = append([]byte(xml.Header), body...)
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	ctx.Set(fiber.HeaderETag, etag)
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	if !updated.IsZero() {
+		ctx.Set(fiber.HeaderLastModified, updated.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(ctx, etag, updated) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	ctx.Set(fiber.HeaderContentType, feed.ContentType)
+	return ctx.Send(body)
+}
+
+// notModified evaluate conditional headers, If-None-Match take precedence over If-Modified-Since (RFC 9110)
+func notModified(ctx *fiber.Ctx, etag string, modified time.Time) bool {
+	if noneMatch := ctx.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		for _, tag := range strings.Split(noneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	since, err := http.ParseTime(ctx.Get(fiber.HeaderIfModifiedSince))
+	if err != nil || modified.IsZero() {
+		return false
+	}
+
+	// http dates have no sub second part
+	return !modified.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/job"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (c *controller) findAllJobs(ctx *fiber.Ctx) error {
+	res, err := c.SchedulerService.List(ctx.Context())
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) findAllJobRuns(ctx *fiber.Ctx) error {
+	filter := new(job.RunQuery)
+	if err := ctx.QueryParser(filter); err != nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest(err.Error()))
+	}
+
+	res, total, err := c.SchedulerService.Runs(ctx.Context(), filter)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Page(ctx, total, res)
+}
+
+// triggerJob start a run in background, poll the run history for its outcome
+func (c *controller) triggerJob(ctx *fiber.Ctx) error {
+	res, err := c.SchedulerService.Trigger(ctx.Context(), ctx.Params("name"))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Created(ctx, res)
+}
+
+func (c *controller) pauseJob(ctx *fiber.Ctx) error {
+	res, err := c.SchedulerService.Pause(ctx.Context(), ctx.Params("name"))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) resumeJob(ctx *fiber.Ctx) error {
+	res, err := c.SchedulerService.Resume(ctx.Context(), ctx.Params("name"))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/location"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (c *controller) createLocation(ctx *fiber.Ctx) error {
+	api := new(location.LocationRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.LocationService.Create(ctx.Context(), api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Created(ctx, res)
+}
+
+func (c *controller) findAllLocations(ctx *fiber.Ctx) error {
+	filter := new(location.LocationQuery)
+	if err := ctx.QueryParser(filter); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	locations, total, err := c.LocationService.FindAll(ctx.Context(), filter)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Page(ctx, total, locations)
+}
+
+func (c *controller) findLocationByID(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	res, err := c.LocationService.FindByID(ctx.Context(), *id)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) updateLocation(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	api := new(location.LocationRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.LocationService.Update(ctx.Context(), *id, api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) deleteLocation(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	if err := c.LocationService.Delete(ctx.Context(), *id); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx)
+}
+
+func (c *controller) findShelfBooks(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	filter := new(book.BookQuery)
+	if err := ctx.QueryParser(filter); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	books, total, err := c.BookService.FindByShelf(ctx.Context(), *id, filter)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Page(ctx, total, books)
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/notification"
+	"github.com/gofiber/fiber/v2"
+)
+
+// findAllNotifications list the delivery log, non admin only see their own notifications
+func (c *controller) findAllNotifications(ctx *fiber.Ctx) error {
+	filter := new(notification.NotificationQuery)
+	if err := ctx.QueryParser(filter); err != nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest(err.Error()))
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+	if !claims.IsAdmin {
+		filter.UserID = *lib.StrToUUID(claims.Issuer)
+	}
+
+	res, total, err := c.NotificationService.FindAll(ctx.Context(), filter)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Page(ctx, total, res)
+}
+
+func (c *controller) retryNotification(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	res, err := c.NotificationService.Retry(ctx.Context(), *id)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) findMyNotificationPreferences(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.NotificationService.Preferences(ctx.Context(), *lib.StrToUUID(claims.Issuer))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) updateMyNotificationPreferences(ctx *fiber.Ctx) error {
+	api := new(notification.PreferenceRequest)
+	if err := ctx.BodyParser(api); err != nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest(err.Error()))
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.NotificationService.UpdatePreferences(ctx.Context(), *lib.StrToUUID(claims.Issuer), api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
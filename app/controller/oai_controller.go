@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"encoding/xml"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// oai answer OAI-PMH requests sent as query string or url encoded form,
+// protocol errors are reported inside the response with status 200
+func (c *controller) oai(ctx *fiber.Ctx) error {
+	args := make(map[string][]string)
+	collect := func(key, value []byte) {
+		args[string(key)] = append(args[string(key)], string(value))
+	}
+
+	if ctx.Method() == fiber.MethodPost {
+		ctx.Request().PostArgs().VisitAll(collect)
+	} else {
+		ctx.Request().URI().QueryArgs().VisitAll(collect)
+	}
+
+	res := c.OAIService.Handle(ctx.Context(), ctx.BaseURL()+ctx.Path(), args)
+
+	body, err := xml.Marshal(res)
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	ctx.Set(fiber.HeaderContentType, "text/xml; charset=utf-8")
+	return ctx.Send(append([]byte(xml.Header), body...))
+}
@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"io"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/citation"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/readinglist"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (c *controller) createReadingList(ctx *fiber.Ctx) error {
+	api := new(readinglist.ListRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.ReadingListService.Create(ctx.Context(), *lib.StrToUUID(claims.Issuer), api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Created(ctx, res)
+}
+
+func (c *controller) findMyReadingLists(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.ReadingListService.FindMine(ctx.Context(), *lib.StrToUUID(claims.Issuer))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) findReadingListByID(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.ReadingListService.FindByID(ctx.Context(), *id, *lib.StrToUUID(claims.Issuer))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+// findSharedReadingList show a public list through its share link, no login required
+func (c *controller) findSharedReadingList(ctx *fiber.Ctx) error {
+	res, err := c.ReadingListService.FindShared(ctx.Context(), ctx.Params("token"))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) updateReadingList(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	api := new(readinglist.ListRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.ReadingListService.Update(ctx.Context(), *id, *lib.StrToUUID(claims.Issuer), api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) deleteReadingList(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	if err := c.ReadingListService.Delete(ctx.Context(), *id, *lib.StrToUUID(claims.Issuer)); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx)
+}
+
+func (c *controller) addReadingListItem(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	api := new(readinglist.ItemRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.ReadingListService.AddItem(ctx.Context(), *id, *lib.StrToUUID(claims.Issuer), api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) removeReadingListItem(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	bookID := lib.StrToUUID(ctx.Params("bookId"))
+	if bookID == nil || *bookID == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid book ID"))
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.ReadingListService.RemoveItem(ctx.Context(), *id, *lib.StrToUUID(claims.Issuer), *bookID)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) reorderReadingList(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	api := new(readinglist.ReorderRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.ReadingListService.Reorder(ctx.Context(), *id, *lib.StrToUUID(claims.Issuer), api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) citeReadingList(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	return sendCitation(ctx, func(format citation.Format, w io.Writer) error {
+		return c.ReadingListService.Cite(ctx.Context(), *id, *lib.StrToUUID(claims.Issuer), format, w)
+	})
+}
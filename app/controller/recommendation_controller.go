@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (c *controller) findAlsoBorrowed(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	res, err := c.RecommendationService.AlsoBorrowed(ctx.Context(), *id, ctx.QueryInt("limit"))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) findMyRecommendations(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.RecommendationService.ForUser(ctx.Context(), *lib.StrToUUID(claims.Issuer), ctx.QueryInt("limit"))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
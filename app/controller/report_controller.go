@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/report"
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendReport send report rows as json, or as excel file when export=xlsx
+func sendReport[T report.Row](ctx *fiber.Ctx, name string, rows []T) error {
+	if ctx.Query("export") != "xlsx" {
+		return lib.OK(ctx, rows)
+	}
+
+	headers, values := report.ToSheet(rows)
+	file, err := lib.WriteExcel(name, headers, values)
+	if err != nil {
+		return exception.Handler(ctx, exception.ErrorInternal("Failed to write excel"))
+	}
+
+	return sendExcel(ctx, name, file)
+}
+
+func parseReportQuery(ctx *fiber.Ctx) (*report.ReportQuery, error) {
+	filter := new(report.ReportQuery)
+	if err := ctx.QueryParser(filter); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	return filter, nil
+}
+
+func (c *controller) mostBorrowedReport(ctx *fiber.Ctx) error {
+	filter, err := parseReportQuery(ctx)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.ReportService.MostBorrowed(ctx.Context(), filter)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return sendReport(ctx, "Most Borrowed", res)
+}
+
+func (c *controller) revenueReport(ctx *fiber.Ctx) error {
+	filter, err := parseReportQuery(ctx)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.ReportService.Revenue(ctx.Context(), filter)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return sendReport(ctx, "Revenue", res)
+}
+
+func (c *controller) memberReport(ctx *fiber.Ctx) error {
+	filter, err := parseReportQuery(ctx)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.ReportService.Members(ctx.Context(), filter)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	if ctx.Query("export") == "xlsx" {
+		return sendReport(ctx, "Members", res.Periods)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) returnTimeReport(ctx *fiber.Ctx) error {
+	filter, err := parseReportQuery(ctx)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.ReportService.ReturnTime(ctx.Context(), filter)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return sendReport(ctx, "Return Time", res)
+}
+
+func (c *controller) utilizationReport(ctx *fiber.Ctx) error {
+	filter, err := parseReportQuery(ctx)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.ReportService.Utilization(ctx.Context(), filter)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return sendReport(ctx, "Utilization", res)
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/review"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (c *controller) createReview(ctx *fiber.Ctx) error {
+	bookID := lib.StrToUUID(ctx.Params("id"))
+	if bookID == nil || *bookID == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	api := new(review.ReviewRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.ReviewService.Create(ctx.Context(), *bookID, *lib.StrToUUID(claims.Issuer), api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Created(ctx, res)
+}
+
+// findBookReviews list published reviews of a book
+func (c *controller) findBookReviews(ctx *fiber.Ctx) error {
+	bookID := lib.StrToUUID(ctx.Params("id"))
+	if bookID == nil || *bookID == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	filter := new(review.ReviewQuery)
+	if err := ctx.QueryParser(filter); err != nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest(err.Error()))
+	}
+	filter.BookID = *bookID
+	filter.Status = constant.ReviewStatus_Published
+
+	reviews, total, err := c.ReviewService.FindAll(ctx.Context(), filter)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Page(ctx, total, reviews)
+}
+
+// findAllReviews list reviews of every status for moderation
+func (c *controller) findAllReviews(ctx *fiber.Ctx) error {
+	filter := new(review.ReviewQuery)
+	if err := ctx.QueryParser(filter); err != nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest(err.Error()))
+	}
+
+	reviews, total, err := c.ReviewService.FindAll(ctx.Context(), filter)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Page(ctx, total, reviews)
+}
+
+func (c *controller) updateReview(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	api := new(review.ReviewRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.ReviewService.Update(ctx.Context(), *id, *lib.StrToUUID(claims.Issuer), api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) deleteReview(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	if err := c.ReviewService.Delete(ctx.Context(), *id, *lib.StrToUUID(claims.Issuer), claims.IsAdmin); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx)
+}
+
+func (c *controller) moderateReview(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	api := new(review.ModerateRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.ReviewService.Moderate(ctx.Context(), *id, api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
@@ -1,10 +1,14 @@
 package controller
 
 import (
+	"bytes"
+	"fmt"
 	"time"
 
 	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/ical"
 	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/membercard"
 	"github.com/dikyayodihamzah/library-management-api/pkg/model"
 	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/user"
 	"github.com/gofiber/fiber/v2"
@@ -98,3 +102,111 @@ func (c *controller) assignAdmin(ctx *fiber.Ctx) error {
 
 	return lib.OK(ctx, res)
 }
+
+func (c *controller) findMyHistory(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	loc, err := c.location(ctx)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.BorrowService.History(ctx.Context(), *lib.StrToUUID(claims.Issuer), loc)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) findMyProfile(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.UserService.Profile(ctx.Context(), *lib.StrToUUID(claims.Issuer))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) updateMyProfile(ctx *fiber.Ctx) error {
+	api := new(user.ProfileRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.UserService.UpdateProfile(ctx.Context(), *lib.StrToUUID(claims.Issuer), api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) createCalendarToken(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	res, err := c.UserService.CreateCalendarToken(ctx.Context(), *lib.StrToUUID(claims.Issuer))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Created(ctx, res)
+}
+
+func (c *controller) revokeCalendarToken(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	if err := c.UserService.RevokeCalendarToken(ctx.Context(), *lib.StrToUUID(claims.Issuer)); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx)
+}
+
+func (c *controller) findMyLoanCalendar(ctx *fiber.Ctx) error {
+	u, err := c.UserService.FindByCalendarToken(ctx.Context(), ctx.Query("token"))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	var buf bytes.Buffer
+	if err := c.BorrowService.LoanCalendar(ctx.Context(), u.ID, &buf); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	ctx.Set(fiber.HeaderContentType, ical.ContentType)
+	ctx.Set(fiber.HeaderCacheControl, "private, max-age=900")
+	return ctx.Send(buf.Bytes())
+}
+
+// findMyCard send printable membership card in the format of ?format=, pdf by default
+func (c *controller) findMyCard(ctx *fiber.Ctx) error {
+	format, err := membercard.Lookup(ctx.Query("format", "pdf"))
+	if err != nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest(err.Error()))
+	}
+
+	claims := ctx.Locals("claims").(*lib.Claims)
+
+	var buf bytes.Buffer
+	if err := c.UserService.Card(ctx.Context(), *lib.StrToUUID(claims.Issuer), format, &buf); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	ctx.Set(fiber.HeaderContentType, format.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=card.%s", format.Extension))
+	return ctx.Send(buf.Bytes())
+}
+
+func (c *controller) findUserByCard(ctx *fiber.Ctx) error {
+	res, err := c.UserService.FindByCardNumber(ctx.Context(), ctx.Params("number"))
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (c *controller) createSeries(ctx *fiber.Ctx) error {
+	api := new(book.SeriesRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.WorkService.CreateSeries(ctx.Context(), api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Created(ctx, res)
+}
+
+func (c *controller) findAllSeries(ctx *fiber.Ctx) error {
+	filter := new(model.QueryParam)
+	if err := ctx.QueryParser(filter); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	series, total, err := c.WorkService.FindAllSeries(ctx.Context(), filter)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Page(ctx, total, series)
+}
+
+func (c *controller) findSeriesByID(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	res, err := c.WorkService.FindSeriesByID(ctx.Context(), *id)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) updateSeries(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	api := new(book.SeriesRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.WorkService.UpdateSeries(ctx.Context(), *id, api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) deleteSeries(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	if err := c.WorkService.DeleteSeries(ctx.Context(), *id); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx)
+}
+
+func (c *controller) createWork(ctx *fiber.Ctx) error {
+	api := new(book.WorkRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.WorkService.Create(ctx.Context(), api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Created(ctx, res)
+}
+
+func (c *controller) findAllWorks(ctx *fiber.Ctx) error {
+	filter := new(model.QueryParam)
+	if err := ctx.QueryParser(filter); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	works, total, err := c.WorkService.FindAll(ctx.Context(), filter)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.Page(ctx, total, works)
+}
+
+func (c *controller) findWorkByID(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	res, err := c.WorkService.FindByID(ctx.Context(), *id)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) updateWork(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	api := new(book.WorkRequest)
+	if err := lib.BodyParser(ctx, api); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	res, err := c.WorkService.Update(ctx.Context(), *id, api)
+	if err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx, res)
+}
+
+func (c *controller) deleteWork(ctx *fiber.Ctx) error {
+	id := lib.StrToUUID(ctx.Params("id"))
+	if id == nil || *id == uuid.Nil {
+		return exception.Handler(ctx, exception.ErrorBadRequest("Invalid ID"))
+	}
+
+	if err := c.WorkService.Delete(ctx.Context(), *id); err != nil {
+		return exception.Handler(ctx, err)
+	}
+
+	return lib.OK(ctx)
+}
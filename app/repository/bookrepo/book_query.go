@@ -2,21 +2,26 @@ package bookrepo
 
 import (
 	"fmt"
+	"strings"
 
-	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
 	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/google/uuid"
 )
 
 var SortBookMap = map[string]string{
-	"title":      "title",
-	"author":     "author",
-	"genre":      "genre",
-	"rating":     "rating",
-	"price":      "price_idr",
-	"created_at": "created_at",
+	"title":          "title",
+	"author":         "author",
+	"genre":          "genre",
+	"rating":         "rating",
+	"average_rating": "rating_average", // computed from member reviews
+	"rating_count":   "rating_count",
+	"price":          "price_idr",
+	"created_at":     "created_at",
+	"call_number":    "call_number_sort", // shelf order
 }
 
-func filterBooks(queryStr string, filter *model.QueryParam) (string, []interface{}) {
+func filterBooks(queryStr string, filter *book.BookQuery) (string, []interface{}) {
 	if filter == nil {
 		return queryStr, make([]interface{}, 0)
 	}
@@ -28,5 +33,39 @@ func filterBooks(queryStr string, filter *model.QueryParam) (string, []interface
 		args = append(args, "%"+filter.Search+"%")
 	}
 
+	// books never updated are stamped by their creation
+	if filter.ModifiedFrom != nil {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("COALESCE(updated_at, created_at) >= $%d", len(args)+1)
+		args = append(args, *filter.ModifiedFrom)
+	}
+
+	if filter.ModifiedUntil != nil {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("COALESCE(updated_at, created_at) <= $%d", len(args)+1)
+		args = append(args, *filter.ModifiedUntil)
+	}
+
+	if filter.AfterCreatedAt != nil {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("(created_at, id) > ($%d, $%d)", len(args)+1, len(args)+2)
+		args = append(args, *filter.AfterCreatedAt, filter.AfterID)
+	}
+
+	if filter.ShelfID != uuid.Nil {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("shelf_id = $%d", len(args)+1)
+		args = append(args, filter.ShelfID)
+	}
+
+	if filter.Genre != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("LOWER(genre) = LOWER($%d)", len(args)+1)
+		args = append(args, filter.Genre)
+	}
+
+	if filter.Author != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("author ILIKE $%d", len(args)+1)
+		args = append(args, "%"+likeEscaper.Replace(filter.Author)+"%")
+	}
+
 	return queryStr, args
 }
+
+// likeEscaper escape LIKE wildcards so user input is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -3,7 +3,6 @@ package bookrepo
 import (
 	"context"
 
-	"github.com/dikyayodihamzah/library-management-api/pkg/model"
 	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
 	"github.com/dikyayodihamzah/library-management-api/pkg/query"
 	"github.com/google/uuid"
@@ -15,9 +14,15 @@ import (
 type BookRepository interface {
 	Create(c context.Context, tx pgx.Tx, b *book.Book) error
 
-	FindAll(c context.Context, filter *model.QueryParam) ([]book.Book, error)
-	Count(c context.Context, filter *model.QueryParam) (int, error)
+	FindAll(c context.Context, filter *book.BookQuery) ([]book.Book, error)
+	Count(c context.Context, filter *book.BookQuery) (int, error)
 	FindByID(c context.Context, id uuid.UUID) (*book.Book, error)
+	FindByISBN(c context.Context, isbn string) (*book.Book, error)
+	// FindByIDs return books in the order of ids, unknown ids are skipped
+	FindByIDs(c context.Context, ids []uuid.UUID) ([]book.Book, error)
+	// FindByIDsForUpdate return books locked until the transaction ends, unknown ids are skipped
+	FindByIDsForUpdate(c context.Context, tx pgx.Tx, ids []uuid.UUID) ([]book.Book, error)
+	Suggest(c context.Context, keyword string, limit int) ([]book.Suggestion, error)
 
 	Update(c context.Context, tx pgx.Tx, b *book.Book) error
 
@@ -39,10 +44,90 @@ func New(
 	}
 }
 
+const bookColumns = `
+		id,
+		isbn,
+		title,
+		author,
+		genre,
+		rating,
+		rating_average,
+		rating_count,
+		cover_url,
+		cover_color,
+		description,
+		total_copies,
+		available_copies,
+		video_url,
+		summary,
+		price_idr,
+		work_id,
+		publisher,
+		published_year,
+		language,
+		edition,
+		classification_scheme,
+		call_number,
+		call_number_sort,
+		shelf_id,
+		created_at,
+		updated_at`
+
+const selectBooks = `
+	SELECT` + bookColumns + `
+	FROM books`
+
+// collapsedBooks pick one edition per work, the one with most copies on the shelf and the newest first
+const collapsedBooks = `
+	SELECT DISTINCT ON (COALESCE(work_id, id)) *
+	FROM books`
+
+const collapsedOrder = `
+	ORDER BY COALESCE(work_id, id), available_copies DESC, published_year DESC NULLS LAST, created_at`
+
+func scanBook(row pgx.Row) (*book.Book, error) {
+	var b book.Book
+	err := row.Scan(
+		&b.ID,
+		&b.ISBN,
+		&b.Title,
+		&b.Author,
+		&b.Genre,
+		&b.Rating,
+		&b.RatingAverage,
+		&b.RatingCount,
+		&b.CoverURL,
+		&b.CoverColor,
+		&b.Description,
+		&b.TotalCopies,
+		&b.AvailableCopies,
+		&b.VideoURL,
+		&b.Summary,
+		&b.Price,
+		&b.WorkID,
+		&b.Publisher,
+		&b.PublishedYear,
+		&b.Language,
+		&b.Edition,
+		&b.ClassificationScheme,
+		&b.CallNumber,
+		&b.CallNumberSort,
+		&b.ShelfID,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
 func (r *bookRepository) Create(c context.Context, tx pgx.Tx, b *book.Book) error {
 	queryStr := `
 	INSERT INTO books (
 		id,
+		isbn,
 		title,
 		author,
 		genre,
@@ -55,11 +140,21 @@ func (r *bookRepository) Create(c context.Context, tx pgx.Tx, b *book.Book) erro
 		video_url,
 		summary,
 		price_idr,
+		work_id,
+		publisher,
+		published_year,
+		language,
+		edition,
+		classification_scheme,
+		call_number,
+		call_number_sort,
+		shelf_id,
 		created_at
-	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)`
 
 	if _, err := tx.Exec(c, queryStr,
 		b.ID,
+		b.ISBN,
 		b.Title,
 		b.Author,
 		b.Genre,
@@ -72,6 +167,15 @@ func (r *bookRepository) Create(c context.Context, tx pgx.Tx, b *book.Book) erro
 		b.VideoURL,
 		b.Summary,
 		b.Price,
+		b.WorkID,
+		b.Publisher,
+		b.PublishedYear,
+		b.Language,
+		b.Edition,
+		b.ClassificationScheme,
+		b.CallNumber,
+		b.CallNumberSort,
+		b.ShelfID,
 		b.CreatedAt,
 	); err != nil {
 		r.Logger.Errorw("failed to create book", "error", err)
@@ -82,26 +186,17 @@ func (r *bookRepository) Create(c context.Context, tx pgx.Tx, b *book.Book) erro
 	return nil
 }
 
-func (r *bookRepository) FindAll(c context.Context, filter *model.QueryParam) ([]book.Book, error) {
-	queryStr := `
-	SELECT
-		id,
-		title,
-		author,
-		genre,
-		rating,
-		cover_url,
-		cover_color,
-		description,
-		total_copies,
-		available_copies,
-		video_url,
-		summary,
-		price_idr,
-		created_at
-	FROM books`
+func (r *bookRepository) FindAll(c context.Context, filter *book.BookQuery) ([]book.Book, error) {
+	queryStr, args := filterBooks(selectBooks, filter)
 
-	queryStr, args := filterBooks(queryStr, filter)
+	// filters are applied before picking one edition per work, so any matching edition counts
+	if filter.CollapseEditions {
+		var inner string
+		inner, args = filterBooks(collapsedBooks, filter)
+		queryStr = `
+	SELECT` + bookColumns + `
+	FROM (` + inner + collapsedOrder + `) books`
+	}
 
 	// sort
 	queryStr, err := query.Sort(queryStr, filter.Sort, SortBookMap)
@@ -110,6 +205,11 @@ func (r *bookRepository) FindAll(c context.Context, filter *model.QueryParam) ([
 		return nil, err
 	}
 
+	// ties are broken by id so pages and harvesting keysets are stable
+	if filter.Sort != "" {
+		queryStr += ", id"
+	}
+
 	// pagination
 	queryStr = query.Paginate(queryStr, filter.Page, filter.Limit)
 
@@ -122,94 +222,199 @@ func (r *bookRepository) FindAll(c context.Context, filter *model.QueryParam) ([
 
 	books := make([]book.Book, 0)
 	for rows.Next() {
-		var b book.Book
-		err := rows.Scan(
-			&b.ID,
-			&b.Title,
-			&b.Author,
-			&b.Genre,
-			&b.Rating,
-			&b.CoverURL,
-			&b.CoverColor,
-			&b.Description,
-			&b.TotalCopies,
-			&b.AvailableCopies,
-			&b.VideoURL,
-			&b.Summary,
-			&b.Price,
-			&b.CreatedAt,
-		)
+		b, err := scanBook(rows)
 		if err != nil {
 			r.Logger.Errorw("failed to scan books", "error", err)
 			return nil, err
 		}
 
-		books = append(books, b)
+		books = append(books, *b)
 	}
 
 	return books, nil
 }
 
-func (r *bookRepository) Count(c context.Context, filter *model.QueryParam) (int, error) {
+func (r *bookRepository) Count(c context.Context, filter *book.BookQuery) (int, error) {
+	count := "COUNT(b.id)"
+	if filter.CollapseEditions {
+		count = "COUNT(DISTINCT COALESCE(b.work_id, b.id))"
+	}
+
 	queryStr := `
-	SELECT 
-		COUNT(b.id)
+	SELECT
+		` + count + `
 	FROM books b`
 
 	queryStr, args := filterBooks(queryStr, filter)
 
-	var count int
-	if err := r.DB.QueryRow(c, queryStr, args...).Scan(&count); err != nil {
+	var total int
+	if err := r.DB.QueryRow(c, queryStr, args...).Scan(&total); err != nil {
 		r.Logger.Errorw("failed to count books", "error", err)
 		return 0, err
 	}
 
-	return count, nil
+	return total, nil
 }
 
 func (r *bookRepository) FindByID(c context.Context, id uuid.UUID) (*book.Book, error) {
-	queryStr := `
-	SELECT
-		id,
-		title,
-		author,
-		genre,
-		rating,
-		cover_url,
-		cover_color,
-		description,
-		total_copies,
-		available_copies,
-		video_url,
-		summary,
-		price_idr,
-		created_at
-	FROM books
+	queryStr := selectBooks + `
 	WHERE id = $1`
 
-	var b book.Book
-	err := r.DB.QueryRow(c, queryStr, id).Scan(
-		&b.ID,
-		&b.Title,
-		&b.Author,
-		&b.Genre,
-		&b.Rating,
-		&b.CoverURL,
-		&b.CoverColor,
-		&b.Description,
-		&b.TotalCopies,
-		&b.AvailableCopies,
-		&b.VideoURL,
-		&b.Summary,
-		&b.Price,
-		&b.CreatedAt,
-	)
+	b, err := scanBook(r.DB.QueryRow(c, queryStr, id))
 	if err != nil {
 		r.Logger.Errorw("failed to get book", "error", err)
 		return nil, err
 	}
 
-	return &b, nil
+	return b, nil
+}
+
+func (r *bookRepository) FindByIDs(c context.Context, ids []uuid.UUID) ([]book.Book, error) {
+	queryStr := selectBooks + `
+	WHERE id = ANY($1)
+	ORDER BY array_position($1, id)`
+
+	rows, err := r.DB.Query(c, queryStr, ids)
+	if err != nil {
+		r.Logger.Errorw("failed to get books", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	books := make([]book.Book, 0)
+	for rows.Next() {
+		b, err := scanBook(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan books", "error", err)
+			return nil, err
+		}
+
+		books = append(books, *b)
+	}
+
+	return books, nil
+}
+
+func (r *bookRepository) FindByIDsForUpdate(c context.Context, tx pgx.Tx, ids []uuid.UUID) ([]book.Book, error) {
+	// rows are locked in id order so concurrent callers do not deadlock
+	queryStr := selectBooks + `
+	WHERE id = ANY($1)
+	ORDER BY id
+	FOR UPDATE`
+
+	rows, err := tx.Query(c, queryStr, ids)
+	if err != nil {
+		r.Logger.Errorw("failed to lock books", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	books := make([]book.Book, 0)
+	for rows.Next() {
+		b, err := scanBook(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan books", "error", err)
+			return nil, err
+		}
+
+		books = append(books, *b)
+	}
+
+	return books, nil
+}
+
+func (r *bookRepository) FindByISBN(c context.Context, isbn string) (*book.Book, error) {
+	queryStr := selectBooks + `
+	WHERE isbn = $1
+	LIMIT 1`
+
+	b, err := scanBook(r.DB.QueryRow(c, queryStr, isbn))
+	if err != nil {
+		r.Logger.Errorw("failed to get book by isbn", "error", err)
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Suggest find titles, authors and genres starting with or similar to keyword,
+// prefix matches come first and are ranked by number of borrows
+func (r *bookRepository) Suggest(c context.Context, keyword string, limit int) ([]book.Suggestion, error) {
+	queryStr := `
+	WITH matches AS (
+		SELECT
+			b.id,
+			b.title,
+			b.author,
+			b.genre,
+			(SELECT COUNT(*) FROM borrow_records br WHERE br.book_id = b.id) AS popularity
+		FROM books b
+		WHERE b.title ILIKE $2 OR b.title ILIKE $3 OR b.title % $1
+			OR b.author ILIKE $2 OR b.author ILIKE $3 OR b.author % $1
+			OR b.genre ILIKE $2 OR b.genre % $1
+	), candidates AS (
+		SELECT
+			'title' AS type,
+			title AS value,
+			id AS book_id,
+			(title ILIKE $2 OR title ILIKE $3) AS prefix,
+			similarity(title, $1) AS similarity,
+			popularity
+		FROM matches
+		WHERE title ILIKE $2 OR title ILIKE $3 OR title % $1
+		UNION ALL
+		SELECT
+			'author',
+			author,
+			NULL::uuid,
+			BOOL_OR(author ILIKE $2 OR author ILIKE $3),
+			MAX(similarity(author, $1)),
+			SUM(popularity)::bigint
+		FROM matches
+		WHERE author ILIKE $2 OR author ILIKE $3 OR author % $1
+		GROUP BY author
+		UNION ALL
+		SELECT
+			'genre',
+			genre,
+			NULL::uuid,
+			BOOL_OR(genre ILIKE $2),
+			MAX(similarity(genre, $1)),
+			SUM(popularity)::bigint
+		FROM matches
+		WHERE genre ILIKE $2 OR genre % $1
+		GROUP BY genre
+	)
+	SELECT
+		type,
+		value,
+		book_id,
+		popularity
+	FROM candidates
+	ORDER BY prefix DESC, popularity DESC, similarity DESC, value
+	LIMIT $4`
+
+	// match start of the value and start of any word in it
+	escaped := likeEscaper.Replace(keyword)
+	rows, err := r.DB.Query(c, queryStr, keyword, escaped+"%", "% "+escaped+"%", limit)
+	if err != nil {
+		r.Logger.Errorw("failed to get book suggestions", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := make([]book.Suggestion, 0)
+	for rows.Next() {
+		var s book.Suggestion
+		if err := rows.Scan(&s.Type, &s.Value, &s.BookID, &s.Popularity); err != nil {
+			r.Logger.Errorw("failed to scan book suggestions", "error", err)
+			return nil, err
+		}
+
+		suggestions = append(suggestions, s)
+	}
+
+	return suggestions, nil
 }
 
 func (r *bookRepository) Update(c context.Context, tx pgx.Tx, b *book.Book) error {
@@ -227,8 +432,19 @@ func (r *bookRepository) Update(c context.Context, tx pgx.Tx, b *book.Book) erro
 		available_copies = $9,
 		video_url = $10,
 		summary = $11,
-		price_idr = $12
-	WHERE id = $13`
+		price_idr = $12,
+		work_id = $13,
+		publisher = $14,
+		published_year = $15,
+		language = $16,
+		edition = $17,
+		classification_scheme = $18,
+		call_number = $19,
+		call_number_sort = $20,
+		shelf_id = $21,
+		isbn = $22,
+		updated_at = $23
+	WHERE id = $24`
 
 	if _, err := tx.Exec(c, queryStr,
 		b.Title,
@@ -243,6 +459,17 @@ func (r *bookRepository) Update(c context.Context, tx pgx.Tx, b *book.Book) erro
 		b.VideoURL,
 		b.Summary,
 		b.Price,
+		b.WorkID,
+		b.Publisher,
+		b.PublishedYear,
+		b.Language,
+		b.Edition,
+		b.ClassificationScheme,
+		b.CallNumber,
+		b.CallNumberSort,
+		b.ShelfID,
+		b.ISBN,
+		b.UpdatedAt,
 		b.ID,
 	); err != nil {
 		r.Logger.Errorw("failed to update book", "error", err)
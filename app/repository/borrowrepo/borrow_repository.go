@@ -3,9 +3,11 @@ package borrowrepo
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
 	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
@@ -15,7 +17,59 @@ type BorrowRepository interface {
 	Add(c context.Context, tx pgx.Tx, borrow ...book.BorrowRecord) error
 	FindAll(c context.Context, filter *book.BorrowQuery) ([]book.BorrowDTO, error)
 	Count(c context.Context, filter *book.BorrowQuery) (int, error)
+	Stream(c context.Context, filter *book.BorrowQuery, fn func(b *book.BorrowDTO) error) error
 	Update(c context.Context, tx pgx.Tx, borrow *book.BorrowRecord) error
+	HasReturned(c context.Context, userID, bookID uuid.UUID) (bool, error)
+	FindOpenDueDates(c context.Context, bookID uuid.UUID) ([]time.Time, error)
+
+	// FindDueSoon return open loans due between now and until which have not been reminded yet
+	FindDueSoon(c context.Context, now, until time.Time) ([]book.BorrowDTO, error)
+	// FindOverdue return open loans past their due date which have not been noticed yet
+	FindOverdue(c context.Context, now time.Time) ([]book.BorrowDTO, error)
+	MarkDueReminderSent(c context.Context, ids []uuid.UUID, sentAt time.Time) error
+	MarkOverdueNoticeSent(c context.Context, ids []uuid.UUID, sentAt time.Time) error
+
+	History(c context.Context, userID uuid.UUID, timezone string) ([]book.BorrowHistory, error)
+	FavouriteGenres(c context.Context, userID uuid.UUID, timezone string, limit int) (map[int][]book.GenreCount, error)
+}
+
+const selectBorrows = `
+	SELECT
+		br.id,
+		br.book_id,
+		br.user_id,
+		br.borrow_date,
+		br.due_date,
+		br.return_date,
+		br.status,
+		br.total_price,
+		br.created_at,
+		u.full_name,
+		b.title
+	FROM borrow_records br
+	INNER JOIN users u ON br.user_id = u.id
+	INNER JOIN books b ON br.book_id = b.id`
+
+func scanBorrow(row pgx.Row) (*book.BorrowDTO, error) {
+	var b book.BorrowDTO
+	err := row.Scan(
+		&b.ID,
+		&b.BookID,
+		&b.UserID,
+		&b.BorrowDate,
+		&b.DueDate,
+		&b.ReturnedDate,
+		&b.Status,
+		&b.TotalPrice,
+		&b.CreatedAt,
+		&b.UserName,
+		&b.BookTitle,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
 }
 
 type borrowRepository struct {
@@ -36,6 +90,7 @@ func New(
 func (r borrowRepository) Add(c context.Context, tx pgx.Tx, borrow ...book.BorrowRecord) error {
 	queryStr := `
 	INSERT INTO borrow_records (
+		id,
 		book_id, 
 		user_id, 
 		borrow_date, 
@@ -47,13 +102,14 @@ func (r borrowRepository) Add(c context.Context, tx pgx.Tx, borrow ...book.Borro
 
 	args := make([]interface{}, 0)
 	for i, b := range borrow {
-		n := i * 7
-		queryStr += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7)
+		n := i * 8
+		queryStr += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8)
 		if i < len(borrow)-1 {
 			queryStr += ", "
 		}
 
 		args = append(args,
+			b.ID,
 			b.BookID,
 			b.UserID,
 			b.BorrowDate,
@@ -73,24 +129,7 @@ func (r borrowRepository) Add(c context.Context, tx pgx.Tx, borrow ...book.Borro
 }
 
 func (r *borrowRepository) FindAll(c context.Context, filter *book.BorrowQuery) ([]book.BorrowDTO, error) {
-	queryStr := `
-	SELECT
-		br.id,
-		br.book_id,
-		br.user_id,
-		br.borrow_date,
-		br.due_date,
-		br.return_date,
-		br.status,
-		br.total_price,
-		br.created_at,
-		u.full_name,
-		b.title
-	FROM borrow_records br
-	INNER JOIN users u ON br.user_id = u.id
-	INNER JOIN books b ON br.book_id = b.id`
-
-	queryStr, args := filterBorrows(queryStr, filter)
+	queryStr, args := filterBorrows(selectBorrows, filter)
 
 	// sort
 	queryStr, err := query.Sort(queryStr, filter.Sort, SortBorrowMap)
@@ -111,31 +150,52 @@ func (r *borrowRepository) FindAll(c context.Context, filter *book.BorrowQuery)
 
 	borrows := make([]book.BorrowDTO, 0)
 	for rows.Next() {
-		var b book.BorrowDTO
-		err := rows.Scan(
-			&b.ID,
-			&b.BookID,
-			&b.UserID,
-			&b.BorrowDate,
-			&b.DueDate,
-			&b.ReturnedDate,
-			&b.Status,
-			&b.TotalPrice,
-			&b.CreatedAt,
-			&b.UserName,
-			&b.BookTitle,
-		)
+		b, err := scanBorrow(rows)
 		if err != nil {
 			r.Logger.Errorw("failed to scan borrows", "error", err)
 			return nil, err
 		}
 
-		borrows = append(borrows, b)
+		borrows = append(borrows, *b)
 	}
 
 	return borrows, nil
 }
 
+// Stream iterate every borrow record matching filter without pagination,
+// rows are read from the connection one at a time so memory stays bounded
+func (r *borrowRepository) Stream(c context.Context, filter *book.BorrowQuery, fn func(b *book.BorrowDTO) error) error {
+	queryStr, args := filterBorrows(selectBorrows, filter)
+
+	// sort
+	queryStr, err := query.Sort(queryStr, filter.Sort, SortBorrowMap)
+	if err != nil {
+		r.Logger.Errorw("failed to sort query", "error", err)
+		return err
+	}
+
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to stream borrows", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		b, err := scanBorrow(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan borrows", "error", err)
+			return err
+		}
+
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 func (r *borrowRepository) Count(c context.Context, filter *book.BorrowQuery) (int, error) {
 	queryStr := `
 	SELECT 
@@ -174,3 +234,211 @@ func (r *borrowRepository) Update(c context.Context, tx pgx.Tx, borrow *book.Bor
 
 	return nil
 }
+
+// HasReturned check whether user has returned the book at least once
+func (r *borrowRepository) HasReturned(c context.Context, userID, bookID uuid.UUID) (bool, error) {
+	queryStr := `
+	SELECT EXISTS (
+		SELECT 1 FROM borrow_records
+		WHERE user_id = $1 AND book_id = $2 AND status = 'RETURNED'
+	)`
+
+	var exists bool
+	if err := r.DB.QueryRow(c, queryStr, userID, bookID).Scan(&exists); err != nil {
+		r.Logger.Errorw("failed to check returned borrow", "error", err)
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// FindOpenDueDates return due dates of loans of the book which are not returned yet, earliest first
+func (r *borrowRepository) FindOpenDueDates(c context.Context, bookID uuid.UUID) ([]time.Time, error) {
+	queryStr := `
+	SELECT due_date
+	FROM borrow_records
+	WHERE book_id = $1 AND status = 'BORROWED'
+	ORDER BY due_date`
+
+	rows, err := r.DB.Query(c, queryStr, bookID)
+	if err != nil {
+		r.Logger.Errorw("failed to get open loans", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	dueDates := make([]time.Time, 0)
+	for rows.Next() {
+		var dueDate time.Time
+		if err := rows.Scan(&dueDate); err != nil {
+			r.Logger.Errorw("failed to scan open loans", "error", err)
+			return nil, err
+		}
+
+		dueDates = append(dueDates, dueDate)
+	}
+
+	return dueDates, nil
+}
+
+func (r *borrowRepository) findMany(c context.Context, queryStr string, args ...interface{}) ([]book.BorrowDTO, error) {
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to get borrows", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	borrows := make([]book.BorrowDTO, 0)
+	for rows.Next() {
+		b, err := scanBorrow(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan borrows", "error", err)
+			return nil, err
+		}
+
+		borrows = append(borrows, *b)
+	}
+
+	return borrows, rows.Err()
+}
+
+func (r *borrowRepository) FindDueSoon(c context.Context, now, until time.Time) ([]book.BorrowDTO, error) {
+	queryStr := selectBorrows + `
+	WHERE br.status = 'BORROWED'
+		AND br.due_date >= $1
+		AND br.due_date <= $2
+		AND br.due_reminder_sent_at IS NULL
+	ORDER BY br.user_id, br.due_date`
+
+	return r.findMany(c, queryStr, now, until)
+}
+
+func (r *borrowRepository) FindOverdue(c context.Context, now time.Time) ([]book.BorrowDTO, error) {
+	queryStr := selectBorrows + `
+	WHERE br.status = 'BORROWED'
+		AND br.due_date < $1
+		AND br.overdue_notice_sent_at IS NULL
+	ORDER BY br.user_id, br.due_date`
+
+	return r.findMany(c, queryStr, now)
+}
+
+func (r *borrowRepository) MarkDueReminderSent(c context.Context, ids []uuid.UUID, sentAt time.Time) error {
+	queryStr := `
+	UPDATE borrow_records
+	SET due_reminder_sent_at = $1
+	WHERE id = ANY($2)`
+
+	if _, err := r.DB.Exec(c, queryStr, sentAt, ids); err != nil {
+		r.Logger.Errorw("failed to mark due reminder sent", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *borrowRepository) MarkOverdueNoticeSent(c context.Context, ids []uuid.UUID, sentAt time.Time) error {
+	queryStr := `
+	UPDATE borrow_records
+	SET overdue_notice_sent_at = $1
+	WHERE id = ANY($2)`
+
+	if _, err := r.DB.Exec(c, queryStr, sentAt, ids); err != nil {
+		r.Logger.Errorw("failed to mark overdue notice sent", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *borrowRepository) History(c context.Context, userID uuid.UUID, timezone string) ([]book.BorrowHistory, error) {
+	queryStr := `
+	SELECT
+		EXTRACT(YEAR FROM br.borrow_date AT TIME ZONE $2)::INT AS year,
+		COUNT(br.id),
+		COUNT(DISTINCT br.book_id) FILTER (WHERE br.status = 'RETURNED'),
+		COALESCE(SUM(br.total_price), 0),
+		COALESCE(AVG(EXTRACT(EPOCH FROM (br.return_date - br.borrow_date)) / 86400) FILTER (WHERE br.return_date IS NOT NULL), 0),
+		COUNT(br.id) FILTER (WHERE br.return_date IS NOT NULL),
+		COUNT(br.id) FILTER (WHERE br.return_date IS NOT NULL AND br.return_date <= br.due_date)
+	FROM borrow_records br
+	WHERE br.user_id = $1
+	GROUP BY year
+	ORDER BY year DESC`
+
+	rows, err := r.DB.Query(c, queryStr, userID, timezone)
+	if err != nil {
+		r.Logger.Errorw("failed to get borrow history", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	histories := make([]book.BorrowHistory, 0)
+	for rows.Next() {
+		var h book.BorrowHistory
+		var onTime int
+		if err := rows.Scan(
+			&h.Year,
+			&h.TotalLoans,
+			&h.BooksRead,
+			&h.TotalSpent,
+			&h.AverageLoanDays,
+			&h.ReturnedLoans,
+			&onTime,
+		); err != nil {
+			r.Logger.Errorw("failed to scan borrow history", "error", err)
+			return nil, err
+		}
+
+		if h.ReturnedLoans > 0 {
+			h.OnTimeReturnRate = float64(onTime) / float64(h.ReturnedLoans)
+		}
+
+		histories = append(histories, h)
+	}
+
+	return histories, nil
+}
+
+func (r *borrowRepository) FavouriteGenres(c context.Context, userID uuid.UUID, timezone string, limit int) (map[int][]book.GenreCount, error) {
+	queryStr := `
+	SELECT year, genre, total
+	FROM (
+		SELECT
+			EXTRACT(YEAR FROM br.borrow_date AT TIME ZONE $3)::INT AS year,
+			b.genre,
+			COUNT(br.id) AS total,
+			ROW_NUMBER() OVER (
+				PARTITION BY EXTRACT(YEAR FROM br.borrow_date AT TIME ZONE $3)
+				ORDER BY COUNT(br.id) DESC, b.genre
+			) AS rank
+		FROM borrow_records br
+		INNER JOIN books b ON br.book_id = b.id
+		WHERE br.user_id = $1
+		GROUP BY year, b.genre
+	) g
+	WHERE rank <= $2
+	ORDER BY year DESC, rank`
+
+	rows, err := r.DB.Query(c, queryStr, userID, limit, timezone)
+	if err != nil {
+		r.Logger.Errorw("failed to get favourite genres", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	genres := make(map[int][]book.GenreCount)
+	for rows.Next() {
+		var year int
+		var g book.GenreCount
+		if err := rows.Scan(&year, &g.Genre, &g.Total); err != nil {
+			r.Logger.Errorw("failed to scan favourite genres", "error", err)
+			return nil, err
+		}
+
+		genres[year] = append(genres[year], g)
+	}
+
+	return genres, nil
+}
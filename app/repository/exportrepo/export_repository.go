@@ -0,0 +1,232 @@
+package exportrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/export"
+	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type ExportRepository interface {
+	Create(c context.Context, tx pgx.Tx, job *export.Job) error
+
+	// TryLock take the advisory lock of a job without waiting,
+	// the lock is held by a dedicated connection until unlock is called
+	TryLock(c context.Context, id uuid.UUID) (unlock func(), ok bool, err error)
+
+	FindByID(c context.Context, id uuid.UUID) (*export.Job, error)
+	FindByStatus(c context.Context, status ...string) ([]export.Job, error)
+	FindExpired(c context.Context, now time.Time) ([]export.Job, error)
+
+	Update(c context.Context, tx pgx.Tx, job *export.Job) error
+	UpdateProgress(c context.Context, id uuid.UUID, progress, total int) error
+}
+
+type exportRepository struct {
+	Logger *zap.SugaredLogger
+	DB     *pgxpool.Pool
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	db *pgxpool.Pool,
+) ExportRepository {
+	return &exportRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (r *exportRepository) TryLock(c context.Context, id uuid.UUID) (func(), bool, error) {
+	unlock, ok, err := transaction.TryLock(c, r.DB, "export:"+id.String())
+	if err != nil {
+		r.Logger.Errorw("failed to lock export job", "id", id, "error", err)
+		return nil, false, err
+	}
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	return func() {
+		if err := unlock(); err != nil {
+			r.Logger.Errorw("failed to unlock export job", "id", id, "error", err)
+		}
+	}, true, nil
+}
+
+const selectJob = `
+	SELECT
+		id,
+		user_id,
+		status,
+		format,
+		filter,
+		timezone,
+		progress,
+		total,
+		file_name,
+		error,
+		expires_at,
+		created_at,
+		updated_at
+	FROM export_jobs`
+
+func scanJob(row pgx.Row) (*export.Job, error) {
+	var j export.Job
+	err := row.Scan(
+		&j.ID,
+		&j.UserID,
+		&j.Status,
+		&j.Format,
+		&j.Filter,
+		&j.Timezone,
+		&j.Progress,
+		&j.Total,
+		&j.FileName,
+		&j.Error,
+		&j.ExpiresAt,
+		&j.CreatedAt,
+		&j.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &j, nil
+}
+
+func (r *exportRepository) Create(c context.Context, tx pgx.Tx, job *export.Job) error {
+	queryStr := `
+	INSERT INTO export_jobs (
+		id,
+		user_id,
+		status,
+		format,
+		filter,
+		timezone,
+		progress,
+		total,
+		file_name,
+		created_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	if _, err := tx.Exec(c, queryStr,
+		job.ID,
+		job.UserID,
+		job.Status,
+		job.Format,
+		job.Filter,
+		job.Timezone,
+		job.Progress,
+		job.Total,
+		job.FileName,
+		job.CreatedAt,
+	); err != nil {
+		r.Logger.Errorw("failed to create export job", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *exportRepository) FindByID(c context.Context, id uuid.UUID) (*export.Job, error) {
+	queryStr := selectJob + `
+	WHERE id = $1`
+
+	job, err := scanJob(r.DB.QueryRow(c, queryStr, id))
+	if err != nil {
+		r.Logger.Errorw("failed to get export job", "error", err)
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (r *exportRepository) findMany(c context.Context, queryStr string, args ...interface{}) ([]export.Job, error) {
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to get export jobs", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]export.Job, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan export jobs", "error", err)
+			return nil, err
+		}
+
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, nil
+}
+
+func (r *exportRepository) FindByStatus(c context.Context, status ...string) ([]export.Job, error) {
+	queryStr := selectJob + `
+	WHERE status = ANY($1)
+	ORDER BY created_at`
+
+	return r.findMany(c, queryStr, status)
+}
+
+func (r *exportRepository) FindExpired(c context.Context, now time.Time) ([]export.Job, error) {
+	queryStr := selectJob + `
+	WHERE status = 'FINISHED' AND expires_at < $1`
+
+	return r.findMany(c, queryStr, now)
+}
+
+func (r *exportRepository) Update(c context.Context, tx pgx.Tx, job *export.Job) error {
+	queryStr := `
+	UPDATE export_jobs
+	SET
+		status = $1,
+		progress = $2,
+		total = $3,
+		error = $4,
+		expires_at = $5,
+		updated_at = $6
+	WHERE id = $7`
+
+	if _, err := tx.Exec(c, queryStr,
+		job.Status,
+		job.Progress,
+		job.Total,
+		job.Error,
+		job.ExpiresAt,
+		job.UpdatedAt,
+		job.ID,
+	); err != nil {
+		r.Logger.Errorw("failed to update export job", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateProgress update job progress outside of transaction so it is visible while running
+func (r *exportRepository) UpdateProgress(c context.Context, id uuid.UUID, progress, total int) error {
+	queryStr := `
+	UPDATE export_jobs
+	SET
+		progress = $1,
+		total = $2,
+		updated_at = NOW()
+	WHERE id = $3`
+
+	if _, err := r.DB.Exec(c, queryStr, progress, total, id); err != nil {
+		r.Logger.Errorw("failed to update export job progress", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package jobrepo
+
+import (
+	"fmt"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/job"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+)
+
+var SortRunMap = map[string]string{
+	"job_name":    "job_name",
+	"status":      "status",
+	"started_at":  "started_at",
+	"finished_at": "finished_at",
+}
+
+func filterRuns(queryStr string, filter *job.RunQuery) (string, []interface{}) {
+	if filter == nil {
+		return queryStr, make([]interface{}, 0)
+	}
+
+	var args []interface{}
+
+	if filter.JobName != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("job_name = $%d", len(args)+1)
+		args = append(args, filter.JobName)
+	}
+
+	if filter.Status != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("status = $%d", len(args)+1)
+		args = append(args, filter.Status)
+	}
+
+	if filter.Trigger != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("trigger = $%d", len(args)+1)
+		args = append(args, filter.Trigger)
+	}
+
+	return queryStr, args
+}
@@ -0,0 +1,307 @@
+package jobrepo
+
+import (
+	"context"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/job"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type JobRepository interface {
+	// TryLock take the advisory lock of a job without waiting,
+	// the lock is held by a dedicated connection until unlock is called
+	TryLock(c context.Context, name string) (unlock func(), ok bool, err error)
+
+	CreateRun(c context.Context, run *job.Run) error
+	FinishRun(c context.Context, run *job.Run) error
+	// FailInterrupted close runs left RUNNING by a replica that stopped mid run,
+	// only safe while holding the lock of the job
+	FailInterrupted(c context.Context, name string) error
+
+	FindAllRuns(c context.Context, filter *job.RunQuery) ([]job.Run, error)
+	CountRuns(c context.Context, filter *job.RunQuery) (int, error)
+	FindLastRuns(c context.Context) (map[string]job.Run, error)
+
+	FindPaused(c context.Context) (map[string]bool, error)
+	IsPaused(c context.Context, name string) (bool, error)
+	SetPaused(c context.Context, tx pgx.Tx, name string, paused bool) error
+}
+
+type jobRepository struct {
+	Logger *zap.SugaredLogger
+	DB     *pgxpool.Pool
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	db *pgxpool.Pool,
+) JobRepository {
+	return &jobRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+const selectRuns = `
+	SELECT
+		id,
+		job_name,
+		trigger,
+		status,
+		result,
+		error,
+		started_at,
+		finished_at
+	FROM job_runs`
+
+func scanRun(row pgx.Row) (*job.Run, error) {
+	var run job.Run
+	err := row.Scan(
+		&run.ID,
+		&run.JobName,
+		&run.Trigger,
+		&run.Status,
+		&run.Result,
+		&run.Error,
+		&run.StartedAt,
+		&run.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+func (r *jobRepository) TryLock(c context.Context, name string) (func(), bool, error) {
+	unlock, ok, err := transaction.TryLock(c, r.DB, "job:"+name)
+	if err != nil {
+		r.Logger.Errorw("failed to lock job", "job", name, "error", err)
+		return nil, false, err
+	}
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	return func() {
+		if err := unlock(); err != nil {
+			r.Logger.Errorw("failed to unlock job", "job", name, "error", err)
+		}
+	}, true, nil
+}
+
+func (r *jobRepository) CreateRun(c context.Context, run *job.Run) error {
+	queryStr := `
+	INSERT INTO job_runs (
+		id,
+		job_name,
+		trigger,
+		status,
+		started_at
+	) VALUES ($1, $2, $3, $4, $5)`
+
+	if _, err := r.DB.Exec(c, queryStr,
+		run.ID,
+		run.JobName,
+		run.Trigger,
+		run.Status,
+		run.StartedAt,
+	); err != nil {
+		r.Logger.Errorw("failed to create job run", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *jobRepository) FinishRun(c context.Context, run *job.Run) error {
+	queryStr := `
+	UPDATE job_runs
+	SET
+		status = $1,
+		result = $2,
+		error = $3,
+		finished_at = $4
+	WHERE id = $5`
+
+	if _, err := r.DB.Exec(c, queryStr,
+		run.Status,
+		run.Result,
+		run.Error,
+		run.FinishedAt,
+		run.ID,
+	); err != nil {
+		r.Logger.Errorw("failed to finish job run", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *jobRepository) FailInterrupted(c context.Context, name string) error {
+	queryStr := `
+	UPDATE job_runs
+	SET
+		status = $1,
+		error = 'interrupted',
+		finished_at = NOW()
+	WHERE job_name = $2 AND status = $3`
+
+	if _, err := r.DB.Exec(c, queryStr, constant.JobStatus_Failed, name, constant.JobStatus_Running); err != nil {
+		r.Logger.Errorw("failed to close interrupted job runs", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *jobRepository) FindAllRuns(c context.Context, filter *job.RunQuery) ([]job.Run, error) {
+	queryStr, args := filterRuns(selectRuns, filter)
+
+	// sort
+	queryStr, err := query.Sort(queryStr, filter.Sort, SortRunMap)
+	if err != nil {
+		r.Logger.Errorw("failed to sort query", "error", err)
+		return nil, err
+	}
+
+	// pagination
+	queryStr = query.Paginate(queryStr, filter.Page, filter.Limit)
+
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to get job runs", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make([]job.Run, 0)
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan job runs", "error", err)
+			return nil, err
+		}
+
+		runs = append(runs, *run)
+	}
+
+	return runs, rows.Err()
+}
+
+func (r *jobRepository) CountRuns(c context.Context, filter *job.RunQuery) (int, error) {
+	queryStr := `
+	SELECT
+		COUNT(id)
+	FROM job_runs`
+
+	queryStr, args := filterRuns(queryStr, filter)
+
+	var count int
+	if err := r.DB.QueryRow(c, queryStr, args...).Scan(&count); err != nil {
+		r.Logger.Errorw("failed to count job runs", "error", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// FindLastRuns return the latest run of every job, keyed by job name
+func (r *jobRepository) FindLastRuns(c context.Context) (map[string]job.Run, error) {
+	queryStr := `
+	SELECT DISTINCT ON (job_name)
+		id,
+		job_name,
+		trigger,
+		status,
+		result,
+		error,
+		started_at,
+		finished_at
+	FROM job_runs
+	ORDER BY job_name, started_at DESC`
+
+	rows, err := r.DB.Query(c, queryStr)
+	if err != nil {
+		r.Logger.Errorw("failed to get last job runs", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make(map[string]job.Run)
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan last job runs", "error", err)
+			return nil, err
+		}
+
+		runs[run.JobName] = *run
+	}
+
+	return runs, rows.Err()
+}
+
+func (r *jobRepository) FindPaused(c context.Context) (map[string]bool, error) {
+	rows, err := r.DB.Query(c, `SELECT name, paused FROM scheduled_jobs`)
+	if err != nil {
+		r.Logger.Errorw("failed to get scheduled jobs", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	paused := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		var p bool
+		if err := rows.Scan(&name, &p); err != nil {
+			r.Logger.Errorw("failed to scan scheduled jobs", "error", err)
+			return nil, err
+		}
+
+		paused[name] = p
+	}
+
+	return paused, rows.Err()
+}
+
+func (r *jobRepository) IsPaused(c context.Context, name string) (bool, error) {
+	queryStr := `
+	SELECT COALESCE((
+		SELECT paused FROM scheduled_jobs
+		WHERE name = $1
+	), FALSE)`
+
+	var paused bool
+	if err := r.DB.QueryRow(c, queryStr, name).Scan(&paused); err != nil {
+		r.Logger.Errorw("failed to check paused job", "error", err)
+		return false, err
+	}
+
+	return paused, nil
+}
+
+func (r *jobRepository) SetPaused(c context.Context, tx pgx.Tx, name string, paused bool) error {
+	queryStr := `
+	INSERT INTO scheduled_jobs (
+		name,
+		paused,
+		updated_at
+	) VALUES ($1, $2, NOW())
+	ON CONFLICT (name) DO UPDATE SET
+		paused = EXCLUDED.paused,
+		updated_at = EXCLUDED.updated_at`
+
+	if _, err := tx.Exec(c, queryStr, name, paused); err != nil {
+		r.Logger.Errorw("failed to update scheduled job", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package locationrepo
+
+import (
+	"fmt"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/location"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/google/uuid"
+)
+
+var SortLocationMap = map[string]string{
+	"code":       "code",
+	"name":       "name",
+	"type":       "type",
+	"created_at": "created_at",
+}
+
+func filterLocations(queryStr string, filter *location.LocationQuery) (string, []interface{}) {
+	if filter == nil {
+		return queryStr, make([]interface{}, 0)
+	}
+
+	var args []interface{}
+
+	if filter.Search != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("(code ILIKE $%d OR name ILIKE $%d)", len(args)+1, len(args)+1)
+		args = append(args, "%"+filter.Search+"%")
+	}
+
+	if filter.ParentID != uuid.Nil {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("parent_id = $%d", len(args)+1)
+		args = append(args, filter.ParentID)
+	}
+
+	if filter.Type != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("type = $%d", len(args)+1)
+		args = append(args, filter.Type)
+	}
+
+	return queryStr, args
+}
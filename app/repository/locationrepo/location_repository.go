@@ -0,0 +1,223 @@
+package locationrepo
+
+import (
+	"context"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/location"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type LocationRepository interface {
+	Create(c context.Context, tx pgx.Tx, l *location.Location) error
+
+	FindAll(c context.Context, filter *location.LocationQuery) ([]location.Location, error)
+	Count(c context.Context, filter *location.LocationQuery) (int, error)
+	FindByID(c context.Context, id uuid.UUID) (*location.Location, error)
+	// FindPath return location and its ancestors, floor first
+	FindPath(c context.Context, id uuid.UUID) ([]location.Location, error)
+
+	Update(c context.Context, tx pgx.Tx, l *location.Location) error
+	Delete(c context.Context, tx pgx.Tx, id uuid.UUID) error
+}
+
+type locationRepository struct {
+	Logger *zap.SugaredLogger
+	DB     *pgxpool.Pool
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	db *pgxpool.Pool,
+) LocationRepository {
+	return &locationRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+const selectLocations = `
+	SELECT
+		id,
+		parent_id,
+		type,
+		code,
+		name,
+		created_at,
+		updated_at
+	FROM locations`
+
+func scanLocation(row pgx.Row) (*location.Location, error) {
+	var l location.Location
+	err := row.Scan(
+		&l.ID,
+		&l.ParentID,
+		&l.Type,
+		&l.Code,
+		&l.Name,
+		&l.CreatedAt,
+		&l.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+func (r *locationRepository) Create(c context.Context, tx pgx.Tx, l *location.Location) error {
+	queryStr := `
+	INSERT INTO locations (
+		id,
+		parent_id,
+		type,
+		code,
+		name,
+		created_at
+	) VALUES ($1, $2, $3, $4, $5, $6)`
+
+	if _, err := tx.Exec(c, queryStr,
+		l.ID,
+		l.ParentID,
+		l.Type,
+		l.Code,
+		l.Name,
+		l.CreatedAt,
+	); err != nil {
+		r.Logger.Errorw("failed to create location", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *locationRepository) findMany(c context.Context, queryStr string, args ...interface{}) ([]location.Location, error) {
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to get locations", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := make([]location.Location, 0)
+	for rows.Next() {
+		l, err := scanLocation(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan locations", "error", err)
+			return nil, err
+		}
+
+		locations = append(locations, *l)
+	}
+
+	return locations, nil
+}
+
+func (r *locationRepository) FindAll(c context.Context, filter *location.LocationQuery) ([]location.Location, error) {
+	queryStr, args := filterLocations(selectLocations, filter)
+
+	// sort
+	queryStr, err := query.Sort(queryStr, filter.Sort, SortLocationMap)
+	if err != nil {
+		r.Logger.Errorw("failed to sort query", "error", err)
+		return nil, err
+	}
+
+	// pagination
+	queryStr = query.Paginate(queryStr, filter.Page, filter.Limit)
+
+	return r.findMany(c, queryStr, args...)
+}
+
+func (r *locationRepository) Count(c context.Context, filter *location.LocationQuery) (int, error) {
+	queryStr := `
+	SELECT
+		COUNT(id)
+	FROM locations`
+
+	queryStr, args := filterLocations(queryStr, filter)
+
+	var total int
+	if err := r.DB.QueryRow(c, queryStr, args...).Scan(&total); err != nil {
+		r.Logger.Errorw("failed to count locations", "error", err)
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *locationRepository) FindByID(c context.Context, id uuid.UUID) (*location.Location, error) {
+	queryStr := selectLocations + `
+	WHERE id = $1`
+
+	l, err := scanLocation(r.DB.QueryRow(c, queryStr, id))
+	if err != nil {
+		r.Logger.Errorw("failed to get location", "error", err)
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (r *locationRepository) FindPath(c context.Context, id uuid.UUID) ([]location.Location, error) {
+	queryStr := `
+	WITH RECURSIVE path AS (
+		SELECT l.*, 0 AS depth
+		FROM locations l
+		WHERE l.id = $1
+		UNION ALL
+		SELECT l.*, p.depth + 1
+		FROM locations l
+		INNER JOIN path p ON l.id = p.parent_id
+	)
+	SELECT
+		id,
+		parent_id,
+		type,
+		code,
+		name,
+		created_at,
+		updated_at
+	FROM path
+	ORDER BY depth DESC`
+
+	return r.findMany(c, queryStr, id)
+}
+
+func (r *locationRepository) Update(c context.Context, tx pgx.Tx, l *location.Location) error {
+	queryStr := `
+	UPDATE locations
+	SET
+		code = $1,
+		name = $2,
+		updated_at = $3
+	WHERE id = $4`
+
+	if _, err := tx.Exec(c, queryStr,
+		l.Code,
+		l.Name,
+		l.UpdatedAt,
+		l.ID,
+	); err != nil {
+		r.Logger.Errorw("failed to update location", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *locationRepository) Delete(c context.Context, tx pgx.Tx, id uuid.UUID) error {
+	queryStr := `
+	DELETE FROM locations
+	WHERE id = $1`
+
+	if _, err := tx.Exec(c, queryStr, id); err != nil {
+		r.Logger.Errorw("failed to delete location", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package notificationrepo
+
+import (
+	"fmt"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/notification"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/google/uuid"
+)
+
+var SortNotificationMap = map[string]string{
+	"type":       "type",
+	"status":     "status",
+	"attempts":   "attempts",
+	"sent_at":    "sent_at",
+	"created_at": "created_at",
+}
+
+func filterNotifications(queryStr string, filter *notification.NotificationQuery) (string, []interface{}) {
+	if filter == nil {
+		return queryStr, make([]interface{}, 0)
+	}
+
+	var args []interface{}
+
+	if filter.Search != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("(recipient ILIKE $%d OR subject ILIKE $%d)", len(args)+1, len(args)+1)
+		args = append(args, "%"+filter.Search+"%")
+	}
+
+	if filter.UserID != uuid.Nil {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("user_id = $%d", len(args)+1)
+		args = append(args, filter.UserID)
+	}
+
+	if filter.Type != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("type = $%d", len(args)+1)
+		args = append(args, filter.Type)
+	}
+
+	if filter.Channel != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("channel = $%d", len(args)+1)
+		args = append(args, filter.Channel)
+	}
+
+	if filter.Status != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("status = $%d", len(args)+1)
+		args = append(args, filter.Status)
+	}
+
+	return queryStr, args
+}
@@ -0,0 +1,328 @@
+package notificationrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/notification"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type NotificationRepository interface {
+	Create(c context.Context, tx pgx.Tx, n *notification.Notification) error
+
+	FindAll(c context.Context, filter *notification.NotificationQuery) ([]notification.Notification, error)
+	Count(c context.Context, filter *notification.NotificationQuery) (int, error)
+	FindByID(c context.Context, id uuid.UUID) (*notification.Notification, error)
+
+	// ClaimDue lease at most limit pending notifications due for delivery,
+	// a claimed row is hidden from other workers until the lease ends
+	ClaimDue(c context.Context, limit int, lease time.Duration) ([]notification.Notification, error)
+	// UpdateDelivery save outcome of a delivery attempt
+	UpdateDelivery(c context.Context, n *notification.Notification) error
+
+	FindPreferences(c context.Context, userID uuid.UUID) ([]notification.Preference, error)
+	UpsertPreferences(c context.Context, tx pgx.Tx, userID uuid.UUID, prefs []notification.Preference) error
+	IsEnabled(c context.Context, userID uuid.UUID, notifType, channel string) (bool, error)
+}
+
+type notificationRepository struct {
+	Logger *zap.SugaredLogger
+	DB     *pgxpool.Pool
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	db *pgxpool.Pool,
+) NotificationRepository {
+	return &notificationRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+const notificationColumns = `
+		id,
+		user_id,
+		type,
+		channel,
+		recipient,
+		subject,
+		body_text,
+		body_html,
+		status,
+		attempts,
+		last_error,
+		next_attempt_at,
+		sent_at,
+		created_at,
+		updated_at`
+
+const selectNotifications = `
+	SELECT` + notificationColumns + `
+	FROM notifications`
+
+func scanNotification(row pgx.Row) (*notification.Notification, error) {
+	var n notification.Notification
+	err := row.Scan(
+		&n.ID,
+		&n.UserID,
+		&n.Type,
+		&n.Channel,
+		&n.Recipient,
+		&n.Subject,
+		&n.BodyText,
+		&n.BodyHTML,
+		&n.Status,
+		&n.Attempts,
+		&n.LastError,
+		&n.NextAttemptAt,
+		&n.SentAt,
+		&n.CreatedAt,
+		&n.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &n, nil
+}
+
+func (r *notificationRepository) Create(c context.Context, tx pgx.Tx, n *notification.Notification) error {
+	queryStr := `
+	INSERT INTO notifications (
+		id,
+		user_id,
+		type,
+		channel,
+		recipient,
+		subject,
+		body_text,
+		body_html,
+		status,
+		attempts,
+		next_attempt_at,
+		created_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	if _, err := tx.Exec(c, queryStr,
+		n.ID,
+		n.UserID,
+		n.Type,
+		n.Channel,
+		n.Recipient,
+		n.Subject,
+		n.BodyText,
+		n.BodyHTML,
+		n.Status,
+		n.Attempts,
+		n.NextAttemptAt,
+		n.CreatedAt,
+	); err != nil {
+		r.Logger.Errorw("failed to create notification", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) FindAll(c context.Context, filter *notification.NotificationQuery) ([]notification.Notification, error) {
+	queryStr, args := filterNotifications(selectNotifications, filter)
+
+	// sort
+	queryStr, err := query.Sort(queryStr, filter.Sort, SortNotificationMap)
+	if err != nil {
+		r.Logger.Errorw("failed to sort query", "error", err)
+		return nil, err
+	}
+
+	// pagination
+	queryStr = query.Paginate(queryStr, filter.Page, filter.Limit)
+
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to get notifications", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := make([]notification.Notification, 0)
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan notifications", "error", err)
+			return nil, err
+		}
+
+		notifications = append(notifications, *n)
+	}
+
+	return notifications, nil
+}
+
+func (r *notificationRepository) Count(c context.Context, filter *notification.NotificationQuery) (int, error) {
+	queryStr := `
+	SELECT
+		COUNT(id)
+	FROM notifications`
+
+	queryStr, args := filterNotifications(queryStr, filter)
+
+	var count int
+	if err := r.DB.QueryRow(c, queryStr, args...).Scan(&count); err != nil {
+		r.Logger.Errorw("failed to count notifications", "error", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *notificationRepository) FindByID(c context.Context, id uuid.UUID) (*notification.Notification, error) {
+	queryStr := selectNotifications + `
+	WHERE id = $1`
+
+	n, err := scanNotification(r.DB.QueryRow(c, queryStr, id))
+	if err != nil {
+		r.Logger.Errorw("failed to get notification", "error", err)
+		return nil, err
+	}
+
+	return n, nil
+}
+
+func (r *notificationRepository) ClaimDue(c context.Context, limit int, lease time.Duration) ([]notification.Notification, error) {
+	// skip locked rows so replicas never claim the same notification
+	queryStr := `
+	UPDATE notifications
+	SET
+		next_attempt_at = NOW() + make_interval(secs => $3::FLOAT8)
+	WHERE id IN (
+		SELECT id FROM notifications
+		WHERE status = $1 AND next_attempt_at <= NOW()
+		ORDER BY next_attempt_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING` + notificationColumns
+
+	rows, err := r.DB.Query(c, queryStr, constant.NotifStatus_Pending, limit, lease.Seconds())
+	if err != nil {
+		r.Logger.Errorw("failed to claim notifications", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := make([]notification.Notification, 0)
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan notifications", "error", err)
+			return nil, err
+		}
+
+		notifications = append(notifications, *n)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (r *notificationRepository) UpdateDelivery(c context.Context, n *notification.Notification) error {
+	queryStr := `
+	UPDATE notifications
+	SET
+		status = $1,
+		attempts = $2,
+		last_error = $3,
+		next_attempt_at = $4,
+		sent_at = $5,
+		updated_at = NOW()
+	WHERE id = $6`
+
+	if _, err := r.DB.Exec(c, queryStr,
+		n.Status,
+		n.Attempts,
+		n.LastError,
+		n.NextAttemptAt,
+		n.SentAt,
+		n.ID,
+	); err != nil {
+		r.Logger.Errorw("failed to update notification", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) FindPreferences(c context.Context, userID uuid.UUID) ([]notification.Preference, error) {
+	queryStr := `
+	SELECT
+		type,
+		channel,
+		enabled
+	FROM notification_preferences
+	WHERE user_id = $1`
+
+	rows, err := r.DB.Query(c, queryStr, userID)
+	if err != nil {
+		r.Logger.Errorw("failed to get notification preferences", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	prefs := make([]notification.Preference, 0)
+	for rows.Next() {
+		var p notification.Preference
+		if err := rows.Scan(&p.Type, &p.Channel, &p.Enabled); err != nil {
+			r.Logger.Errorw("failed to scan notification preferences", "error", err)
+			return nil, err
+		}
+
+		prefs = append(prefs, p)
+	}
+
+	return prefs, rows.Err()
+}
+
+func (r *notificationRepository) UpsertPreferences(c context.Context, tx pgx.Tx, userID uuid.UUID, prefs []notification.Preference) error {
+	queryStr := `
+	INSERT INTO notification_preferences (
+		user_id,
+		type,
+		channel,
+		enabled,
+		updated_at
+	) VALUES ($1, $2, $3, $4, NOW())
+	ON CONFLICT (user_id, type, channel) DO UPDATE SET
+		enabled = EXCLUDED.enabled,
+		updated_at = EXCLUDED.updated_at`
+
+	for _, p := range prefs {
+		if _, err := tx.Exec(c, queryStr, userID, p.Type, p.Channel, p.Enabled); err != nil {
+			r.Logger.Errorw("failed to save notification preference", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) IsEnabled(c context.Context, userID uuid.UUID, notifType, channel string) (bool, error) {
+	queryStr := `
+	SELECT COALESCE((
+		SELECT enabled FROM notification_preferences
+		WHERE user_id = $1 AND type = $2 AND channel = $3
+	), TRUE)`
+
+	var enabled bool
+	if err := r.DB.QueryRow(c, queryStr, userID, notifType, channel).Scan(&enabled); err != nil {
+		r.Logger.Errorw("failed to check notification preference", "error", err)
+		return false, err
+	}
+
+	return enabled, nil
+}
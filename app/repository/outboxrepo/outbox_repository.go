@@ -0,0 +1,166 @@
+package outboxrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/event"
+	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type OutboxRepository interface {
+	// Add write events in the transaction of the change they describe
+	Add(c context.Context, tx pgx.Tx, events ...event.Event) error
+
+	// TryLock take the relay lock, only the holder may publish events
+	TryLock(c context.Context) (unlock func(), ok bool, err error)
+	// FindUnpublished return events in seq order, events are held back while a transaction older than their own
+	// is still running so the relay does not run ahead of writers which have not committed yet
+	FindUnpublished(c context.Context, limit int) ([]event.Event, error)
+	MarkPublished(c context.Context, seqs []int64, publishedAt time.Time) error
+	// DeletePublished remove events published before the given time
+	DeletePublished(c context.Context, before time.Time) (int, error)
+}
+
+type outboxRepository struct {
+	Logger *zap.SugaredLogger
+	DB     *pgxpool.Pool
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	db *pgxpool.Pool,
+) OutboxRepository {
+	return &outboxRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (r *outboxRepository) Add(c context.Context, tx pgx.Tx, events ...event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	queryStr := `
+	INSERT INTO outbox_events (
+		id,
+		type,
+		aggregate_type,
+		aggregate_id,
+		payload,
+		occurred_at
+	) VALUES ($1, $2, $3, $4, $5, $6)`
+
+	for _, e := range events {
+		if _, err := tx.Exec(c, queryStr,
+			e.ID,
+			e.Type,
+			e.AggregateType,
+			e.AggregateID,
+			e.Payload,
+			e.OccurredAt,
+		); err != nil {
+			r.Logger.Errorw("failed to add outbox event", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) TryLock(c context.Context) (func(), bool, error) {
+	unlock, ok, err := transaction.TryLock(c, r.DB, "outbox:relay")
+	if err != nil {
+		r.Logger.Errorw("failed to lock outbox relay", "error", err)
+		return nil, false, err
+	}
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	return func() {
+		if err := unlock(); err != nil {
+			r.Logger.Errorw("failed to unlock outbox relay", "error", err)
+		}
+	}, true, nil
+}
+
+func (r *outboxRepository) FindUnpublished(c context.Context, limit int) ([]event.Event, error) {
+	queryStr := `
+	SELECT
+		seq,
+		id,
+		type,
+		aggregate_type,
+		aggregate_id,
+		payload,
+		occurred_at,
+		published_at
+	FROM outbox_events
+	WHERE published_at IS NULL
+	AND xact_id < pg_snapshot_xmin(pg_current_snapshot())
+	ORDER BY seq
+	LIMIT $1`
+
+	rows, err := r.DB.Query(c, queryStr, limit)
+	if err != nil {
+		r.Logger.Errorw("failed to get outbox events", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]event.Event, 0)
+	for rows.Next() {
+		var e event.Event
+		if err := rows.Scan(
+			&e.Seq,
+			&e.ID,
+			&e.Type,
+			&e.AggregateType,
+			&e.AggregateID,
+			&e.Payload,
+			&e.OccurredAt,
+			&e.PublishedAt,
+		); err != nil {
+			r.Logger.Errorw("failed to scan outbox events", "error", err)
+			return nil, err
+		}
+
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *outboxRepository) MarkPublished(c context.Context, seqs []int64, publishedAt time.Time) error {
+	queryStr := `
+	UPDATE outbox_events
+	SET published_at = $1
+	WHERE seq = ANY($2)`
+
+	if _, err := r.DB.Exec(c, queryStr, publishedAt, seqs); err != nil {
+		r.Logger.Errorw("failed to mark outbox events published", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) DeletePublished(c context.Context, before time.Time) (int, error) {
+	queryStr := `
+	DELETE FROM outbox_events
+	WHERE published_at < $1`
+
+	tag, err := r.DB.Exec(c, queryStr, before)
+	if err != nil {
+		r.Logger.Errorw("failed to delete published outbox events", "error", err)
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,321 @@
+package readinglistrepo
+
+import (
+	"context"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/readinglist"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type ReadingListRepository interface {
+	Create(c context.Context, tx pgx.Tx, list *readinglist.ReadingList) error
+
+	FindByUser(c context.Context, userID uuid.UUID) ([]readinglist.ReadingList, error)
+	FindByID(c context.Context, id uuid.UUID) (*readinglist.ReadingList, error)
+	FindByShareToken(c context.Context, token string) (*readinglist.ReadingList, error)
+	FindItems(c context.Context, listID uuid.UUID) ([]readinglist.Item, error)
+
+	// FindContaining return lists of user which contain the book
+	FindContaining(c context.Context, userID, bookID uuid.UUID) ([]model.SimpleResponse, error)
+
+	Update(c context.Context, tx pgx.Tx, list *readinglist.ReadingList) error
+	Delete(c context.Context, tx pgx.Tx, id uuid.UUID) error
+
+	AddItem(c context.Context, tx pgx.Tx, listID, bookID uuid.UUID) error
+	RemoveItem(c context.Context, tx pgx.Tx, listID, bookID uuid.UUID) error
+	Reorder(c context.Context, tx pgx.Tx, listID uuid.UUID, bookIDs []uuid.UUID) error
+}
+
+type readingListRepository struct {
+	Logger *zap.SugaredLogger
+	DB     *pgxpool.Pool
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	db *pgxpool.Pool,
+) ReadingListRepository {
+	return &readingListRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+const selectLists = `
+	SELECT
+		l.id,
+		l.user_id,
+		l.name,
+		l.is_default,
+		l.visibility,
+		l.share_token,
+		(SELECT COUNT(*) FROM reading_list_items i WHERE i.list_id = l.id),
+		l.created_at,
+		l.updated_at
+	FROM reading_lists l`
+
+func scanList(row pgx.Row) (*readinglist.ReadingList, error) {
+	var l readinglist.ReadingList
+	err := row.Scan(
+		&l.ID,
+		&l.UserID,
+		&l.Name,
+		&l.IsDefault,
+		&l.Visibility,
+		&l.ShareToken,
+		&l.TotalItems,
+		&l.CreatedAt,
+		&l.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+func (r *readingListRepository) Create(c context.Context, tx pgx.Tx, list *readinglist.ReadingList) error {
+	queryStr := `
+	INSERT INTO reading_lists (
+		id,
+		user_id,
+		name,
+		is_default,
+		visibility,
+		share_token,
+		created_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	if _, err := tx.Exec(c, queryStr,
+		list.ID,
+		list.UserID,
+		list.Name,
+		list.IsDefault,
+		list.Visibility,
+		list.ShareToken,
+		list.CreatedAt,
+	); err != nil {
+		r.Logger.Errorw("failed to create reading list", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *readingListRepository) FindByUser(c context.Context, userID uuid.UUID) ([]readinglist.ReadingList, error) {
+	queryStr := selectLists + `
+	WHERE l.user_id = $1
+	ORDER BY l.is_default DESC, l.created_at`
+
+	rows, err := r.DB.Query(c, queryStr, userID)
+	if err != nil {
+		r.Logger.Errorw("failed to get reading lists", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := make([]readinglist.ReadingList, 0)
+	for rows.Next() {
+		l, err := scanList(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan reading lists", "error", err)
+			return nil, err
+		}
+
+		lists = append(lists, *l)
+	}
+
+	return lists, nil
+}
+
+func (r *readingListRepository) FindByID(c context.Context, id uuid.UUID) (*readinglist.ReadingList, error) {
+	queryStr := selectLists + `
+	WHERE l.id = $1`
+
+	l, err := scanList(r.DB.QueryRow(c, queryStr, id))
+	if err != nil {
+		r.Logger.Errorw("failed to get reading list", "error", err)
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (r *readingListRepository) FindByShareToken(c context.Context, token string) (*readinglist.ReadingList, error) {
+	queryStr := selectLists + `
+	WHERE l.share_token = $1`
+
+	l, err := scanList(r.DB.QueryRow(c, queryStr, token))
+	if err != nil {
+		r.Logger.Errorw("failed to get reading list", "error", err)
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (r *readingListRepository) FindItems(c context.Context, listID uuid.UUID) ([]readinglist.Item, error) {
+	queryStr := `
+	SELECT
+		i.book_id,
+		b.title,
+		b.author,
+		b.cover_url,
+		i.position,
+		i.added_at
+	FROM reading_list_items i
+	INNER JOIN books b ON i.book_id = b.id
+	WHERE i.list_id = $1
+	ORDER BY i.position, i.added_at`
+
+	rows, err := r.DB.Query(c, queryStr, listID)
+	if err != nil {
+		r.Logger.Errorw("failed to get reading list items", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]readinglist.Item, 0)
+	for rows.Next() {
+		var i readinglist.Item
+		if err := rows.Scan(
+			&i.BookID,
+			&i.Title,
+			&i.Author,
+			&i.CoverURL,
+			&i.Position,
+			&i.AddedAt,
+		); err != nil {
+			r.Logger.Errorw("failed to scan reading list items", "error", err)
+			return nil, err
+		}
+
+		items = append(items, i)
+	}
+
+	return items, nil
+}
+
+func (r *readingListRepository) FindContaining(c context.Context, userID, bookID uuid.UUID) ([]model.SimpleResponse, error) {
+	queryStr := `
+	SELECT
+		l.id,
+		l.name
+	FROM reading_lists l
+	INNER JOIN reading_list_items i ON i.list_id = l.id
+	WHERE l.user_id = $1 AND i.book_id = $2
+	ORDER BY l.is_default DESC, l.created_at`
+
+	rows, err := r.DB.Query(c, queryStr, userID, bookID)
+	if err != nil {
+		r.Logger.Errorw("failed to get reading lists of book", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := make([]model.SimpleResponse, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			r.Logger.Errorw("failed to scan reading lists of book", "error", err)
+			return nil, err
+		}
+
+		lists = append(lists, model.SimpleResponse{ID: id, Name: name})
+	}
+
+	return lists, nil
+}
+
+func (r *readingListRepository) Update(c context.Context, tx pgx.Tx, list *readinglist.ReadingList) error {
+	queryStr := `
+	UPDATE reading_lists
+	SET
+		name = $1,
+		visibility = $2,
+		share_token = $3,
+		updated_at = $4
+	WHERE id = $5`
+
+	if _, err := tx.Exec(c, queryStr,
+		list.Name,
+		list.Visibility,
+		list.ShareToken,
+		list.UpdatedAt,
+		list.ID,
+	); err != nil {
+		r.Logger.Errorw("failed to update reading list", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *readingListRepository) Delete(c context.Context, tx pgx.Tx, id uuid.UUID) error {
+	queryStr := `
+	DELETE FROM reading_lists
+	WHERE id = $1`
+
+	if _, err := tx.Exec(c, queryStr, id); err != nil {
+		r.Logger.Errorw("failed to delete reading list", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// AddItem append book at the end of the list, adding a book twice keep its position
+func (r *readingListRepository) AddItem(c context.Context, tx pgx.Tx, listID, bookID uuid.UUID) error {
+	queryStr := `
+	INSERT INTO reading_list_items (
+		list_id,
+		book_id,
+		position
+	) VALUES (
+		$1,
+		$2,
+		(SELECT COALESCE(MAX(position), 0) + 1 FROM reading_list_items WHERE list_id = $1)
+	)
+	ON CONFLICT (list_id, book_id) DO NOTHING`
+
+	if _, err := tx.Exec(c, queryStr, listID, bookID); err != nil {
+		r.Logger.Errorw("failed to add reading list item", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *readingListRepository) RemoveItem(c context.Context, tx pgx.Tx, listID, bookID uuid.UUID) error {
+	queryStr := `
+	DELETE FROM reading_list_items
+	WHERE list_id = $1 AND book_id = $2`
+
+	if _, err := tx.Exec(c, queryStr, listID, bookID); err != nil {
+		r.Logger.Errorw("failed to remove reading list item", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Reorder set position of items following order of bookIDs
+func (r *readingListRepository) Reorder(c context.Context, tx pgx.Tx, listID uuid.UUID, bookIDs []uuid.UUID) error {
+	queryStr := `
+	UPDATE reading_list_items i
+	SET position = o.position
+	FROM UNNEST($2::uuid[]) WITH ORDINALITY AS o (book_id, position)
+	WHERE i.list_id = $1 AND i.book_id = o.book_id`
+
+	if _, err := tx.Exec(c, queryStr, listID, bookIDs); err != nil {
+		r.Logger.Errorw("failed to reorder reading list items", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package recommendationrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/recommendation"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type RecommendationRepository interface {
+	FindAlsoBorrowed(c context.Context, bookID uuid.UUID, limit int) ([]recommendation.Recommendation, error)
+	FindForUser(c context.Context, userID uuid.UUID, limit int) ([]recommendation.Recommendation, error)
+
+	// RefreshAlsoBorrowed replace co-occurrence table, keeping top n books per book
+	RefreshAlsoBorrowed(c context.Context, tx pgx.Tx, n int, now time.Time) error
+	// RefreshPersonal replace personal table, keeping top n books per member
+	RefreshPersonal(c context.Context, tx pgx.Tx, n int, now time.Time) error
+}
+
+type recommendationRepository struct {
+	Logger *zap.SugaredLogger
+	DB     *pgxpool.Pool
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	db *pgxpool.Pool,
+) RecommendationRepository {
+	return &recommendationRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (r *recommendationRepository) findMany(c context.Context, queryStr string, args ...interface{}) ([]recommendation.Recommendation, error) {
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to get recommendations", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]recommendation.Recommendation, 0)
+	for rows.Next() {
+		var rc recommendation.Recommendation
+		if err := rows.Scan(
+			&rc.BookID,
+			&rc.Title,
+			&rc.Author,
+			&rc.Genre,
+			&rc.CoverURL,
+			&rc.CoverColor,
+			&rc.Score,
+			&rc.Reason,
+		); err != nil {
+			r.Logger.Errorw("failed to scan recommendations", "error", err)
+			return nil, err
+		}
+
+		res = append(res, rc)
+	}
+
+	return res, nil
+}
+
+func (r *recommendationRepository) FindAlsoBorrowed(c context.Context, bookID uuid.UUID, limit int) ([]recommendation.Recommendation, error) {
+	queryStr := `
+	SELECT
+		b.id,
+		b.title,
+		b.author,
+		b.genre,
+		b.cover_url,
+		b.cover_color,
+		r.score,
+		$3::text
+	FROM book_recommendations r
+	INNER JOIN books b ON r.recommended_book_id = b.id
+	WHERE r.book_id = $1
+	ORDER BY r.score DESC, b.title
+	LIMIT $2`
+
+	return r.findMany(c, queryStr, bookID, limit, constant.RecommendationReason_AlsoBorrowed)
+}
+
+func (r *recommendationRepository) FindForUser(c context.Context, userID uuid.UUID, limit int) ([]recommendation.Recommendation, error) {
+	queryStr := `
+	SELECT
+		b.id,
+		b.title,
+		b.author,
+		b.genre,
+		b.cover_url,
+		b.cover_color,
+		r.score,
+		r.reason
+	FROM user_recommendations r
+	INNER JOIN books b ON r.book_id = b.id
+	WHERE r.user_id = $1
+		AND NOT EXISTS (
+			SELECT 1 FROM borrow_records br
+			WHERE br.user_id = r.user_id AND br.book_id = r.book_id
+		)
+	ORDER BY r.score DESC, b.title
+	LIMIT $2`
+
+	return r.findMany(c, queryStr, userID, limit)
+}
+
+func (r *recommendationRepository) RefreshAlsoBorrowed(c context.Context, tx pgx.Tx, n int, now time.Time) error {
+	if _, err := tx.Exec(c, `DELETE FROM book_recommendations`); err != nil {
+		r.Logger.Errorw("failed to clear book recommendations", "error", err)
+		return err
+	}
+
+	// pair every book with other books borrowed by the same members
+	queryStr := `
+	INSERT INTO book_recommendations (
+		book_id,
+		recommended_book_id,
+		score,
+		refreshed_at
+	)
+	SELECT
+		book_id,
+		recommended_book_id,
+		score,
+		$2::timestamptz
+	FROM (
+		SELECT
+			a.book_id,
+			b.book_id AS recommended_book_id,
+			COUNT(DISTINCT a.user_id) AS score,
+			ROW_NUMBER() OVER (
+				PARTITION BY a.book_id
+				ORDER BY COUNT(DISTINCT a.user_id) DESC, b.book_id
+			) AS rank
+		FROM borrow_records a
+		INNER JOIN borrow_records b ON a.user_id = b.user_id AND a.book_id <> b.book_id
+		GROUP BY a.book_id, b.book_id
+	) pairs
+	WHERE rank <= $1`
+
+	if _, err := tx.Exec(c, queryStr, n, now); err != nil {
+		r.Logger.Errorw("failed to refresh book recommendations", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *recommendationRepository) RefreshPersonal(c context.Context, tx pgx.Tx, n int, now time.Time) error {
+	if _, err := tx.Exec(c, `DELETE FROM user_recommendations`); err != nil {
+		r.Logger.Errorw("failed to clear user recommendations", "error", err)
+		return err
+	}
+
+	// an author the member has read weighs twice as much as a genre,
+	// books the member already borrowed are never recommended
+	queryStr := `
+	WITH history AS (
+		SELECT
+			br.user_id,
+			b.genre,
+			b.author,
+			COUNT(br.id) AS total
+		FROM borrow_records br
+		INNER JOIN books b ON br.book_id = b.id
+		GROUP BY br.user_id, b.genre, b.author
+	), candidates AS (
+		SELECT h.user_id, b.id AS book_id, SUM(h.total) AS genre_score, 0 AS author_score
+		FROM history h
+		INNER JOIN books b ON b.genre = h.genre
+		GROUP BY h.user_id, b.id
+		UNION ALL
+		SELECT h.user_id, b.id AS book_id, 0 AS genre_score, SUM(h.total) AS author_score
+		FROM history h
+		INNER JOIN books b ON b.author = h.author
+		GROUP BY h.user_id, b.id
+	), scored AS (
+		SELECT
+			c.user_id,
+			c.book_id,
+			SUM(c.genre_score) + 2 * SUM(c.author_score) AS score,
+			CASE WHEN SUM(c.author_score) > 0 THEN $3::text ELSE $4::text END AS reason
+		FROM candidates c
+		WHERE NOT EXISTS (
+			SELECT 1 FROM borrow_records br
+			WHERE br.user_id = c.user_id AND br.book_id = c.book_id
+		)
+		GROUP BY c.user_id, c.book_id
+	)
+	INSERT INTO user_recommendations (
+		user_id,
+		book_id,
+		score,
+		reason,
+		refreshed_at
+	)
+	SELECT
+		user_id,
+		book_id,
+		score,
+		reason,
+		$2::timestamptz
+	FROM (
+		SELECT
+			*,
+			ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY score DESC, book_id) AS rank
+		FROM scored
+	) ranked
+	WHERE rank <= $1`
+
+	if _, err := tx.Exec(c, queryStr,
+		n,
+		now,
+		constant.RecommendationReason_SameAuthor,
+		constant.RecommendationReason_SameGenre,
+	); err != nil {
+		r.Logger.Errorw("failed to refresh user recommendations", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package reportrepo
+
+import (
+	"fmt"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/report"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+)
+
+var GroupByMap = map[string]string{
+	"day":   "day",
+	"week":  "week",
+	"month": "month",
+}
+
+func filterPeriod(queryStr, column string, filter *report.ReportQuery, args []interface{}) (string, []interface{}) {
+	if filter == nil {
+		return queryStr, args
+	}
+
+	if !filter.StartDate.IsZero() {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("%s >= $%d", column, len(args)+1)
+		args = append(args, filter.StartDate)
+	}
+
+	if !filter.EndDate.IsZero() {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("%s <= $%d", column, len(args)+1)
+		args = append(args, filter.EndDate)
+	}
+
+	return queryStr, args
+}
+
+func interval(groupBy string) string {
+	return fmt.Sprintf("'1 %s'::INTERVAL", GroupByMap[groupBy])
+}
@@ -0,0 +1,295 @@
+package reportrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/report"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type ReportRepository interface {
+	MostBorrowed(c context.Context, filter *report.ReportQuery) ([]report.MostBorrowed, error)
+	Revenue(c context.Context, filter *report.ReportQuery) ([]report.Revenue, error)
+	MemberSummary(c context.Context, asOf time.Time, dormantDays int) (*report.MemberSummary, error)
+	MemberActivity(c context.Context, filter *report.ReportQuery) ([]report.MemberActivity, error)
+	ReturnTime(c context.Context, filter *report.ReportQuery) ([]report.ReturnTime, error)
+	Utilization(c context.Context, filter *report.ReportQuery) ([]report.Utilization, error)
+}
+
+type reportRepository struct {
+	Logger *zap.SugaredLogger
+	DB     *pgxpool.Pool
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	db *pgxpool.Pool,
+) ReportRepository {
+	return &reportRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (r *reportRepository) MostBorrowed(c context.Context, filter *report.ReportQuery) ([]report.MostBorrowed, error) {
+	period := "NULL::TIMESTAMPTZ"
+	if filter.GroupBy != "" {
+		period = fmt.Sprintf("date_trunc('%s', br.borrow_date)", GroupByMap[filter.GroupBy])
+	}
+
+	queryStr := `
+	FROM borrow_records br
+	INNER JOIN books b ON br.book_id = b.id`
+
+	queryStr, args := filterPeriod(queryStr, "br.borrow_date", filter, nil)
+
+	queryStr = fmt.Sprintf(`
+	SELECT period, book_id, title, author, total, revenue
+	FROM (
+		SELECT
+			%[1]s AS period,
+			b.id AS book_id,
+			b.title,
+			b.author,
+			COUNT(br.id) AS total,
+			COALESCE(SUM(br.total_price), 0) AS revenue,
+			ROW_NUMBER() OVER (
+				PARTITION BY %[1]s
+				ORDER BY COUNT(br.id) DESC, b.title
+			) AS rank
+		%[2]s
+		GROUP BY period, b.id, b.title, b.author
+	) r
+	WHERE rank <= $%[3]d
+	ORDER BY period, rank`, period, queryStr, len(args)+1)
+	args = append(args, filter.Limit)
+
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to get most borrowed report", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]report.MostBorrowed, 0)
+	for rows.Next() {
+		var m report.MostBorrowed
+		if err := rows.Scan(
+			&m.Period,
+			&m.BookID,
+			&m.Title,
+			&m.Author,
+			&m.TotalBorrows,
+			&m.Revenue,
+		); err != nil {
+			r.Logger.Errorw("failed to scan most borrowed report", "error", err)
+			return nil, err
+		}
+
+		res = append(res, m)
+	}
+
+	return res, nil
+}
+
+func (r *reportRepository) Revenue(c context.Context, filter *report.ReportQuery) ([]report.Revenue, error) {
+	queryStr := fmt.Sprintf(`
+	SELECT
+		date_trunc('%s', br.borrow_date) AS period,
+		COUNT(br.id),
+		COALESCE(SUM(br.total_price), 0)
+	FROM borrow_records br`, GroupByMap[filter.GroupBy])
+
+	queryStr, args := filterPeriod(queryStr, "br.borrow_date", filter, nil)
+	queryStr += `
+	GROUP BY period
+	ORDER BY period`
+
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to get revenue report", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]report.Revenue, 0)
+	for rows.Next() {
+		var rv report.Revenue
+		if err := rows.Scan(&rv.Period, &rv.TotalBorrows, &rv.Revenue); err != nil {
+			r.Logger.Errorw("failed to scan revenue report", "error", err)
+			return nil, err
+		}
+
+		res = append(res, rv)
+	}
+
+	return res, nil
+}
+
+func (r *reportRepository) MemberSummary(c context.Context, asOf time.Time, dormantDays int) (*report.MemberSummary, error) {
+	queryStr := `
+	SELECT
+		COUNT(u.id),
+		COUNT(u.id) FILTER (WHERE u.last_activity_date >= $1::TIMESTAMPTZ - make_interval(days => $2))
+	FROM users u
+	WHERE u.created_at <= $1`
+
+	res := report.MemberSummary{DormantDays: dormantDays}
+	if err := r.DB.QueryRow(c, queryStr, asOf, dormantDays).Scan(
+		&res.TotalMembers,
+		&res.ActiveMembers,
+	); err != nil {
+		r.Logger.Errorw("failed to get member summary", "error", err)
+		return nil, err
+	}
+	res.DormantMembers = res.TotalMembers - res.ActiveMembers
+
+	return &res, nil
+}
+
+func (r *reportRepository) MemberActivity(c context.Context, filter *report.ReportQuery) ([]report.MemberActivity, error) {
+	queryStr := fmt.Sprintf(`
+	SELECT
+		p.period,
+		COUNT(u.id) FILTER (WHERE u.created_at >= p.period AND u.created_at < p.period + %[2]s),
+		COUNT(u.id) FILTER (WHERE u.last_activity_date >= p.period AND u.last_activity_date < p.period + %[2]s)
+	FROM generate_series(date_trunc('%[1]s', $1::TIMESTAMPTZ), $2::TIMESTAMPTZ, %[2]s) AS p(period)
+	LEFT JOIN users u ON u.created_at < p.period + %[2]s
+	GROUP BY p.period
+	ORDER BY p.period`, GroupByMap[filter.GroupBy], interval(filter.GroupBy))
+
+	rows, err := r.DB.Query(c, queryStr, filter.StartDate, filter.EndDate)
+	if err != nil {
+		r.Logger.Errorw("failed to get member activity report", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]report.MemberActivity, 0)
+	for rows.Next() {
+		var m report.MemberActivity
+		if err := rows.Scan(&m.Period, &m.NewMembers, &m.ActiveMembers); err != nil {
+			r.Logger.Errorw("failed to scan member activity report", "error", err)
+			return nil, err
+		}
+
+		res = append(res, m)
+	}
+
+	return res, nil
+}
+
+func (r *reportRepository) ReturnTime(c context.Context, filter *report.ReportQuery) ([]report.ReturnTime, error) {
+	queryStr := fmt.Sprintf(`
+	SELECT
+		date_trunc('%s', br.return_date) AS period,
+		COUNT(br.id),
+		COALESCE(AVG(EXTRACT(EPOCH FROM (br.return_date - br.borrow_date)) / 86400), 0),
+		COUNT(br.id) FILTER (WHERE br.return_date <= br.due_date)
+	FROM borrow_records br
+	WHERE br.return_date IS NOT NULL`, GroupByMap[filter.GroupBy])
+
+	queryStr, args := filterPeriod(queryStr, "br.return_date", filter, nil)
+	queryStr += `
+	GROUP BY period
+	ORDER BY period`
+
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to get return time report", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]report.ReturnTime, 0)
+	for rows.Next() {
+		var rt report.ReturnTime
+		if err := rows.Scan(
+			&rt.Period,
+			&rt.ReturnedLoans,
+			&rt.AverageDays,
+			&rt.OnTimeReturns,
+		); err != nil {
+			r.Logger.Errorw("failed to scan return time report", "error", err)
+			return nil, err
+		}
+
+		if rt.ReturnedLoans > 0 {
+			rt.OnTimeReturnPct = float64(rt.OnTimeReturns) / float64(rt.ReturnedLoans) * 100
+		}
+
+		res = append(res, rt)
+	}
+
+	return res, nil
+}
+
+func (r *reportRepository) Utilization(c context.Context, filter *report.ReportQuery) ([]report.Utilization, error) {
+	// books are paged before joining periods, so a page hold every period of its books
+	booksStr := `
+		SELECT id, title, total_copies
+		FROM books`
+
+	args := []interface{}{filter.StartDate, filter.EndDate}
+	if filter.BookID != uuid.Nil {
+		booksStr = query.ClauseBuilder(booksStr) + fmt.Sprintf("id = $%d", len(args)+1)
+		args = append(args, filter.BookID)
+	}
+
+	booksStr = query.Paginate(booksStr+`
+		ORDER BY title, id`, filter.Page, filter.Limit)
+
+	// loans are counted as on loan when they are still open at the end of each period
+	queryStr := fmt.Sprintf(`
+	SELECT
+		p.period,
+		b.id,
+		b.title,
+		b.total_copies,
+		COUNT(br.id)
+	FROM generate_series(date_trunc('%[1]s', $1::TIMESTAMPTZ), $2::TIMESTAMPTZ, %[2]s) AS p(period)
+	CROSS JOIN (%[3]s
+	) b
+	LEFT JOIN borrow_records br ON br.book_id = b.id
+		AND br.borrow_date < p.period + %[2]s
+		AND (br.return_date IS NULL OR br.return_date >= p.period + %[2]s)
+	GROUP BY p.period, b.id, b.title, b.total_copies
+	ORDER BY p.period, b.title, b.id`, GroupByMap[filter.GroupBy], interval(filter.GroupBy), booksStr)
+
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to get utilization report", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]report.Utilization, 0)
+	for rows.Next() {
+		var u report.Utilization
+		if err := rows.Scan(
+			&u.Period,
+			&u.BookID,
+			&u.Title,
+			&u.TotalCopies,
+			&u.OnLoan,
+		); err != nil {
+			r.Logger.Errorw("failed to scan utilization report", "error", err)
+			return nil, err
+		}
+
+		u.AvailableCopies = max(u.TotalCopies-u.OnLoan, 0)
+		if u.TotalCopies > 0 {
+			u.Availability = float64(u.AvailableCopies) / float64(u.TotalCopies)
+			u.Utilization = float64(u.OnLoan) / float64(u.TotalCopies)
+		}
+
+		res = append(res, u)
+	}
+
+	return res, nil
+}
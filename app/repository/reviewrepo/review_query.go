@@ -0,0 +1,47 @@
+package reviewrepo
+
+import (
+	"fmt"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/review"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/google/uuid"
+)
+
+var SortReviewMap = map[string]string{
+	"rating":     "r.rating",
+	"status":     "r.status",
+	"user_name":  "u.full_name",
+	"book_title": "b.title",
+	"created_at": "r.created_at",
+}
+
+func filterReviews(queryStr string, filter *review.ReviewQuery) (string, []interface{}) {
+	if filter == nil {
+		return queryStr, make([]interface{}, 0)
+	}
+
+	var args []interface{}
+
+	if filter.Search != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("r.content ILIKE $%d", len(args)+1)
+		args = append(args, "%"+filter.Search+"%")
+	}
+
+	if filter.BookID != uuid.Nil {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("r.book_id = $%d", len(args)+1)
+		args = append(args, filter.BookID)
+	}
+
+	if filter.UserID != uuid.Nil {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("r.user_id = $%d", len(args)+1)
+		args = append(args, filter.UserID)
+	}
+
+	if filter.Status != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("r.status = $%d", len(args)+1)
+		args = append(args, filter.Status)
+	}
+
+	return queryStr, args
+}
@@ -0,0 +1,252 @@
+package reviewrepo
+
+import (
+	"context"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/review"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type ReviewRepository interface {
+	Create(c context.Context, tx pgx.Tx, r *review.Review) error
+
+	FindAll(c context.Context, filter *review.ReviewQuery) ([]review.ReviewDTO, error)
+	Count(c context.Context, filter *review.ReviewQuery) (int, error)
+	FindByID(c context.Context, id uuid.UUID) (*review.ReviewDTO, error)
+	Exists(c context.Context, bookID, userID uuid.UUID) (bool, error)
+
+	Update(c context.Context, tx pgx.Tx, r *review.Review) error
+	Delete(c context.Context, tx pgx.Tx, id uuid.UUID) error
+
+	// RefreshBookRating recompute rating_average and rating_count of a book from published reviews
+	RefreshBookRating(c context.Context, tx pgx.Tx, bookID uuid.UUID) error
+}
+
+type reviewRepository struct {
+	Logger *zap.SugaredLogger
+	DB     *pgxpool.Pool
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	db *pgxpool.Pool,
+) ReviewRepository {
+	return &reviewRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+const selectReviews = `
+	SELECT
+		r.id,
+		r.book_id,
+		r.user_id,
+		r.rating,
+		r.content,
+		r.status,
+		r.moderation_note,
+		r.created_at,
+		r.updated_at,
+		u.full_name,
+		b.title
+	FROM book_reviews r
+	INNER JOIN users u ON r.user_id = u.id
+	INNER JOIN books b ON r.book_id = b.id`
+
+func scanReview(row pgx.Row) (*review.ReviewDTO, error) {
+	var r review.ReviewDTO
+	err := row.Scan(
+		&r.ID,
+		&r.BookID,
+		&r.UserID,
+		&r.Rating,
+		&r.Content,
+		&r.Status,
+		&r.ModerationNote,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+		&r.UserName,
+		&r.BookTitle,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+func (r *reviewRepository) Create(c context.Context, tx pgx.Tx, rv *review.Review) error {
+	queryStr := `
+	INSERT INTO book_reviews (
+		id,
+		book_id,
+		user_id,
+		rating,
+		content,
+		status,
+		created_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	if _, err := tx.Exec(c, queryStr,
+		rv.ID,
+		rv.BookID,
+		rv.UserID,
+		rv.Rating,
+		rv.Content,
+		rv.Status,
+		rv.CreatedAt,
+	); err != nil {
+		r.Logger.Errorw("failed to create review", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *reviewRepository) FindAll(c context.Context, filter *review.ReviewQuery) ([]review.ReviewDTO, error) {
+	queryStr, args := filterReviews(selectReviews, filter)
+
+	// sort
+	queryStr, err := query.Sort(queryStr, filter.Sort, SortReviewMap)
+	if err != nil {
+		r.Logger.Errorw("failed to sort query", "error", err)
+		return nil, err
+	}
+
+	// pagination
+	queryStr = query.Paginate(queryStr, filter.Page, filter.Limit)
+
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to get reviews", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := make([]review.ReviewDTO, 0)
+	for rows.Next() {
+		rv, err := scanReview(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan reviews", "error", err)
+			return nil, err
+		}
+
+		reviews = append(reviews, *rv)
+	}
+
+	return reviews, nil
+}
+
+func (r *reviewRepository) Count(c context.Context, filter *review.ReviewQuery) (int, error) {
+	queryStr := `
+	SELECT
+		COUNT(r.id)
+	FROM book_reviews r`
+
+	queryStr, args := filterReviews(queryStr, filter)
+
+	var count int
+	if err := r.DB.QueryRow(c, queryStr, args...).Scan(&count); err != nil {
+		r.Logger.Errorw("failed to count reviews", "error", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *reviewRepository) FindByID(c context.Context, id uuid.UUID) (*review.ReviewDTO, error) {
+	queryStr := selectReviews + `
+	WHERE r.id = $1`
+
+	rv, err := scanReview(r.DB.QueryRow(c, queryStr, id))
+	if err != nil {
+		r.Logger.Errorw("failed to get review", "error", err)
+		return nil, err
+	}
+
+	return rv, nil
+}
+
+func (r *reviewRepository) Exists(c context.Context, bookID, userID uuid.UUID) (bool, error) {
+	queryStr := `
+	SELECT EXISTS (
+		SELECT 1 FROM book_reviews
+		WHERE book_id = $1 AND user_id = $2
+	)`
+
+	var exists bool
+	if err := r.DB.QueryRow(c, queryStr, bookID, userID).Scan(&exists); err != nil {
+		r.Logger.Errorw("failed to check review", "error", err)
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *reviewRepository) Update(c context.Context, tx pgx.Tx, rv *review.Review) error {
+	queryStr := `
+	UPDATE book_reviews
+	SET
+		rating = $1,
+		content = $2,
+		status = $3,
+		moderation_note = $4,
+		updated_at = $5
+	WHERE id = $6`
+
+	if _, err := tx.Exec(c, queryStr,
+		rv.Rating,
+		rv.Content,
+		rv.Status,
+		rv.ModerationNote,
+		rv.UpdatedAt,
+		rv.ID,
+	); err != nil {
+		r.Logger.Errorw("failed to update review", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *reviewRepository) Delete(c context.Context, tx pgx.Tx, id uuid.UUID) error {
+	queryStr := `
+	DELETE FROM book_reviews
+	WHERE id = $1`
+
+	if _, err := tx.Exec(c, queryStr, id); err != nil {
+		r.Logger.Errorw("failed to delete review", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *reviewRepository) RefreshBookRating(c context.Context, tx pgx.Tx, bookID uuid.UUID) error {
+	queryStr := `
+	UPDATE books b
+	SET
+		rating_average = s.average,
+		rating_count = s.total
+	FROM (
+		SELECT
+			COALESCE(ROUND(AVG(rating), 2), 0) AS average,
+			COUNT(id) AS total
+		FROM book_reviews
+		WHERE book_id = $1 AND status = $2
+	) s
+	WHERE b.id = $1`
+
+	if _, err := tx.Exec(c, queryStr, bookID, constant.ReviewStatus_Published); err != nil {
+		r.Logger.Errorw("failed to refresh book rating", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/user"
 	"github.com/dikyayodihamzah/library-management-api/pkg/query"
 	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
@@ -25,6 +26,10 @@ type UserRepository interface {
 
 	// update
 	Update(c context.Context, tx pgx.Tx, user *user.User) error
+	// UpdateCalendarToken replace hash of the calendar feed token, nil revoke the feed
+	UpdateCalendarToken(c context.Context, tx pgx.Tx, id uuid.UUID, hash *string) error
+	// UpdateCardNumber assign library card number to a user created before cards existed
+	UpdateCardNumber(c context.Context, tx pgx.Tx, id uuid.UUID, number string) error
 
 	// delete
 	SoftDelete(c context.Context, tx pgx.Tx, NIKs ...string) error
@@ -55,8 +60,10 @@ func (ur *userRepository) Create(c context.Context, tx pgx.Tx, user *user.User)
 		password,
 		role,
 		last_activity_date,
-		created_at
-	) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+		created_at,
+		timezone,
+		card_number
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''))`
 
 	if _, err := tx.Exec(c, queryStr,
 		user.ID,
@@ -66,6 +73,8 @@ func (ur *userRepository) Create(c context.Context, tx pgx.Tx, user *user.User)
 		user.Role,
 		user.LastActivityDate,
 		user.CreatedAt,
+		user.Timezone,
+		user.CardNumber,
 	); err != nil {
 		ur.Logger.Errorw("failed to create user", "error", err)
 		return err
@@ -82,7 +91,9 @@ func (ur *userRepository) FindAll(c context.Context, filter *model.QueryParam) (
 		email,
 		role,
 		last_activity_date,
-		created_at
+		created_at,
+		COALESCE(timezone, ''),
+		COALESCE(card_number, '')
 	FROM users`
 
 	// filter
@@ -118,6 +129,8 @@ func (ur *userRepository) FindAll(c context.Context, filter *model.QueryParam) (
 			&user.Role,
 			&user.LastActivityDate,
 			&user.CreatedAt,
+			&user.Timezone,
+			&user.CardNumber,
 		); err != nil {
 			ur.Logger.Errorw("error on Find User", "error", err.Error())
 			return nil, err
@@ -155,7 +168,9 @@ func (ur *userRepository) FindByColumn(c context.Context, column string, value i
 		password,
 		role,
 		last_activity_date,
-		created_at
+		created_at,
+		COALESCE(timezone, ''),
+		COALESCE(card_number, '')
 	FROM users
 	WHERE %s = $1`, column)
 
@@ -168,6 +183,8 @@ func (ur *userRepository) FindByColumn(c context.Context, column string, value i
 		&user.Role,
 		&user.LastActivityDate,
 		&user.CreatedAt,
+		&user.Timezone,
+		&user.CardNumber,
 	); err != nil {
 		utils.Debug(err)
 		ur.Logger.Errorw("error on find User by NIK ", "error", err.Error())
@@ -184,7 +201,8 @@ func (ur *userRepository) Update(c context.Context, tx pgx.Tx, user *user.User)
 		email = $3,
 		password = $4,
 		role = $5,
-		last_activity_date = $6
+		last_activity_date = $6,
+		timezone = NULLIF($7, '')
 	WHERE id = $1`
 
 	if _, err := tx.Exec(c, queryStr,
@@ -194,6 +212,7 @@ func (ur *userRepository) Update(c context.Context, tx pgx.Tx, user *user.User)
 		user.Password,
 		user.Role,
 		user.LastActivityDate,
+		user.Timezone,
 	); err != nil {
 		ur.Logger.Errorw("failed to update user", "error", err)
 		return err
@@ -202,6 +221,34 @@ func (ur *userRepository) Update(c context.Context, tx pgx.Tx, user *user.User)
 	return nil
 }
 
+func (ur *userRepository) UpdateCalendarToken(c context.Context, tx pgx.Tx, id uuid.UUID, hash *string) error {
+	queryStr := `
+	UPDATE users SET
+		calendar_token_hash = $2
+	WHERE id = $1`
+
+	if _, err := tx.Exec(c, queryStr, id, hash); err != nil {
+		ur.Logger.Errorw("failed to update calendar token", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (ur *userRepository) UpdateCardNumber(c context.Context, tx pgx.Tx, id uuid.UUID, number string) error {
+	queryStr := `
+	UPDATE users SET
+		card_number = $2
+	WHERE id = $1`
+
+	if _, err := tx.Exec(c, queryStr, id, number); err != nil {
+		ur.Logger.Errorw("failed to update card number", "error", err)
+		return err
+	}
+
+	return nil
+}
+
 func (ur *userRepository) SoftDelete(c context.Context, tx pgx.Tx, NIKs ...string) error {
 	queryStr := `
 	UPDATE users SET
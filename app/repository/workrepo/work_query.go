@@ -0,0 +1,50 @@
+package workrepo
+
+import (
+	"fmt"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+)
+
+var SortSeriesMap = map[string]string{
+	"name":       "name",
+	"created_at": "created_at",
+}
+
+var SortWorkMap = map[string]string{
+	"title":         "w.title",
+	"author":        "w.author",
+	"volume_number": "w.volume_number",
+	"created_at":    "w.created_at",
+}
+
+func filterSeries(queryStr string, filter *model.QueryParam) (string, []interface{}) {
+	if filter == nil {
+		return queryStr, make([]interface{}, 0)
+	}
+
+	var args []interface{}
+
+	if filter.Search != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("name ILIKE $%d", len(args)+1)
+		args = append(args, "%"+filter.Search+"%")
+	}
+
+	return queryStr, args
+}
+
+func filterWorks(queryStr string, filter *model.QueryParam) (string, []interface{}) {
+	if filter == nil {
+		return queryStr, make([]interface{}, 0)
+	}
+
+	var args []interface{}
+
+	if filter.Search != "" {
+		queryStr = query.ClauseBuilder(queryStr) + fmt.Sprintf("(w.title ILIKE $%d OR w.author ILIKE $%d)", len(args)+1, len(args)+1)
+		args = append(args, "%"+filter.Search+"%")
+	}
+
+	return queryStr, args
+}
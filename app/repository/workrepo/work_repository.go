@@ -0,0 +1,442 @@
+package workrepo
+
+import (
+	"context"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type WorkRepository interface {
+	CreateSeries(c context.Context, tx pgx.Tx, s *book.Series) error
+	FindAllSeries(c context.Context, filter *model.QueryParam) ([]book.Series, error)
+	CountSeries(c context.Context, filter *model.QueryParam) (int, error)
+	FindSeriesByID(c context.Context, id uuid.UUID) (*book.Series, error)
+	UpdateSeries(c context.Context, tx pgx.Tx, s *book.Series) error
+	DeleteSeries(c context.Context, tx pgx.Tx, id uuid.UUID) error
+
+	Create(c context.Context, tx pgx.Tx, w *book.Work) error
+	FindAll(c context.Context, filter *model.QueryParam) ([]book.Work, error)
+	Count(c context.Context, filter *model.QueryParam) (int, error)
+	FindByID(c context.Context, id uuid.UUID) (*book.Work, error)
+	FindBySeries(c context.Context, seriesID uuid.UUID) ([]book.Work, error)
+	Update(c context.Context, tx pgx.Tx, w *book.Work) error
+	Delete(c context.Context, tx pgx.Tx, id uuid.UUID) error
+
+	// FindEditions return books of the work, the most available first
+	FindEditions(c context.Context, workID uuid.UUID) ([]book.Edition, error)
+	// FindNextVolume return the work following volume in the series
+	FindNextVolume(c context.Context, seriesID uuid.UUID, volume int) (*book.NextVolume, error)
+}
+
+type workRepository struct {
+	Logger *zap.SugaredLogger
+	DB     *pgxpool.Pool
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	db *pgxpool.Pool,
+) WorkRepository {
+	return &workRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+const selectSeries = `
+	SELECT
+		id,
+		name,
+		description,
+		created_at,
+		updated_at
+	FROM series`
+
+func scanSeries(row pgx.Row) (*book.Series, error) {
+	var s book.Series
+	err := row.Scan(
+		&s.ID,
+		&s.Name,
+		&s.Description,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+const selectWorks = `
+	SELECT
+		w.id,
+		w.title,
+		w.author,
+		w.series_id,
+		w.volume_number,
+		s.name,
+		w.created_at,
+		w.updated_at
+	FROM works w
+	LEFT JOIN series s ON w.series_id = s.id`
+
+func scanWork(row pgx.Row) (*book.Work, error) {
+	var w book.Work
+	err := row.Scan(
+		&w.ID,
+		&w.Title,
+		&w.Author,
+		&w.SeriesID,
+		&w.VolumeNumber,
+		&w.SeriesName,
+		&w.CreatedAt,
+		&w.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func (r *workRepository) CreateSeries(c context.Context, tx pgx.Tx, s *book.Series) error {
+	queryStr := `
+	INSERT INTO series (
+		id,
+		name,
+		description,
+		created_at
+	) VALUES ($1, $2, $3, $4)`
+
+	if _, err := tx.Exec(c, queryStr,
+		s.ID,
+		s.Name,
+		s.Description,
+		s.CreatedAt,
+	); err != nil {
+		r.Logger.Errorw("failed to create series", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *workRepository) FindAllSeries(c context.Context, filter *model.QueryParam) ([]book.Series, error) {
+	queryStr, args := filterSeries(selectSeries, filter)
+
+	// sort
+	queryStr, err := query.Sort(queryStr, filter.Sort, SortSeriesMap)
+	if err != nil {
+		r.Logger.Errorw("failed to sort query", "error", err)
+		return nil, err
+	}
+
+	// pagination
+	queryStr = query.Paginate(queryStr, filter.Page, filter.Limit)
+
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to get series", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	series := make([]book.Series, 0)
+	for rows.Next() {
+		s, err := scanSeries(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan series", "error", err)
+			return nil, err
+		}
+
+		series = append(series, *s)
+	}
+
+	return series, nil
+}
+
+func (r *workRepository) CountSeries(c context.Context, filter *model.QueryParam) (int, error) {
+	queryStr := `
+	SELECT
+		COUNT(id)
+	FROM series`
+
+	queryStr, args := filterSeries(queryStr, filter)
+
+	var total int
+	if err := r.DB.QueryRow(c, queryStr, args...).Scan(&total); err != nil {
+		r.Logger.Errorw("failed to count series", "error", err)
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *workRepository) FindSeriesByID(c context.Context, id uuid.UUID) (*book.Series, error) {
+	queryStr := selectSeries + `
+	WHERE id = $1`
+
+	s, err := scanSeries(r.DB.QueryRow(c, queryStr, id))
+	if err != nil {
+		r.Logger.Errorw("failed to get series", "error", err)
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (r *workRepository) UpdateSeries(c context.Context, tx pgx.Tx, s *book.Series) error {
+	queryStr := `
+	UPDATE series
+	SET
+		name = $1,
+		description = $2,
+		updated_at = $3
+	WHERE id = $4`
+
+	if _, err := tx.Exec(c, queryStr,
+		s.Name,
+		s.Description,
+		s.UpdatedAt,
+		s.ID,
+	); err != nil {
+		r.Logger.Errorw("failed to update series", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *workRepository) DeleteSeries(c context.Context, tx pgx.Tx, id uuid.UUID) error {
+	queryStr := `
+	DELETE FROM series
+	WHERE id = $1`
+
+	if _, err := tx.Exec(c, queryStr, id); err != nil {
+		r.Logger.Errorw("failed to delete series", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *workRepository) Create(c context.Context, tx pgx.Tx, w *book.Work) error {
+	queryStr := `
+	INSERT INTO works (
+		id,
+		title,
+		author,
+		series_id,
+		volume_number,
+		created_at
+	) VALUES ($1, $2, $3, $4, $5, $6)`
+
+	if _, err := tx.Exec(c, queryStr,
+		w.ID,
+		w.Title,
+		w.Author,
+		w.SeriesID,
+		w.VolumeNumber,
+		w.CreatedAt,
+	); err != nil {
+		r.Logger.Errorw("failed to create work", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *workRepository) FindAll(c context.Context, filter *model.QueryParam) ([]book.Work, error) {
+	queryStr, args := filterWorks(selectWorks, filter)
+
+	// sort
+	queryStr, err := query.Sort(queryStr, filter.Sort, SortWorkMap)
+	if err != nil {
+		r.Logger.Errorw("failed to sort query", "error", err)
+		return nil, err
+	}
+
+	// pagination
+	queryStr = query.Paginate(queryStr, filter.Page, filter.Limit)
+
+	return r.findMany(c, queryStr, args...)
+}
+
+func (r *workRepository) findMany(c context.Context, queryStr string, args ...interface{}) ([]book.Work, error) {
+	rows, err := r.DB.Query(c, queryStr, args...)
+	if err != nil {
+		r.Logger.Errorw("failed to get works", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	works := make([]book.Work, 0)
+	for rows.Next() {
+		w, err := scanWork(rows)
+		if err != nil {
+			r.Logger.Errorw("failed to scan works", "error", err)
+			return nil, err
+		}
+
+		works = append(works, *w)
+	}
+
+	return works, nil
+}
+
+func (r *workRepository) Count(c context.Context, filter *model.QueryParam) (int, error) {
+	queryStr := `
+	SELECT
+		COUNT(w.id)
+	FROM works w`
+
+	queryStr, args := filterWorks(queryStr, filter)
+
+	var total int
+	if err := r.DB.QueryRow(c, queryStr, args...).Scan(&total); err != nil {
+		r.Logger.Errorw("failed to count works", "error", err)
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *workRepository) FindByID(c context.Context, id uuid.UUID) (*book.Work, error) {
+	queryStr := selectWorks + `
+	WHERE w.id = $1`
+
+	w, err := scanWork(r.DB.QueryRow(c, queryStr, id))
+	if err != nil {
+		r.Logger.Errorw("failed to get work", "error", err)
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (r *workRepository) FindBySeries(c context.Context, seriesID uuid.UUID) ([]book.Work, error) {
+	queryStr := selectWorks + `
+	WHERE w.series_id = $1
+	ORDER BY w.volume_number NULLS LAST, w.title`
+
+	return r.findMany(c, queryStr, seriesID)
+}
+
+func (r *workRepository) Update(c context.Context, tx pgx.Tx, w *book.Work) error {
+	queryStr := `
+	UPDATE works
+	SET
+		title = $1,
+		author = $2,
+		series_id = $3,
+		volume_number = $4,
+		updated_at = $5
+	WHERE id = $6`
+
+	if _, err := tx.Exec(c, queryStr,
+		w.Title,
+		w.Author,
+		w.SeriesID,
+		w.VolumeNumber,
+		w.UpdatedAt,
+		w.ID,
+	); err != nil {
+		r.Logger.Errorw("failed to update work", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *workRepository) Delete(c context.Context, tx pgx.Tx, id uuid.UUID) error {
+	queryStr := `
+	DELETE FROM works
+	WHERE id = $1`
+
+	if _, err := tx.Exec(c, queryStr, id); err != nil {
+		r.Logger.Errorw("failed to delete work", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *workRepository) FindEditions(c context.Context, workID uuid.UUID) ([]book.Edition, error) {
+	queryStr := `
+	SELECT
+		id,
+		title,
+		publisher,
+		published_year,
+		language,
+		edition,
+		available_copies
+	FROM books
+	WHERE work_id = $1
+	ORDER BY available_copies DESC, published_year DESC NULLS LAST, created_at`
+
+	rows, err := r.DB.Query(c, queryStr, workID)
+	if err != nil {
+		r.Logger.Errorw("failed to get editions", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	editions := make([]book.Edition, 0)
+	for rows.Next() {
+		var e book.Edition
+		if err := rows.Scan(
+			&e.ID,
+			&e.Title,
+			&e.Publisher,
+			&e.PublishedYear,
+			&e.Language,
+			&e.Edition,
+			&e.AvailableCopies,
+		); err != nil {
+			r.Logger.Errorw("failed to scan editions", "error", err)
+			return nil, err
+		}
+
+		editions = append(editions, e)
+	}
+
+	return editions, nil
+}
+
+func (r *workRepository) FindNextVolume(c context.Context, seriesID uuid.UUID, volume int) (*book.NextVolume, error) {
+	queryStr := `
+	SELECT
+		w.id,
+		w.title,
+		w.volume_number,
+		(
+			SELECT b.id FROM books b
+			WHERE b.work_id = w.id
+			ORDER BY b.available_copies DESC, b.published_year DESC NULLS LAST
+			LIMIT 1
+		)
+	FROM works w
+	WHERE w.series_id = $1 AND w.volume_number > $2
+	ORDER BY w.volume_number
+	LIMIT 1`
+
+	var n book.NextVolume
+	if err := r.DB.QueryRow(c, queryStr, seriesID, volume).Scan(
+		&n.WorkID,
+		&n.Title,
+		&n.VolumeNumber,
+		&n.BookID,
+	); err != nil {
+		return nil, err
+	}
+
+	return &n, nil
+}
@@ -0,0 +1,396 @@
+package booksvc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exporter"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	catalogTitle = "Catalog"
+
+	// catalog sheet columns, the same keys are used to read an edited sheet back
+	catalogColumns = []exporter.Column{
+		{Key: "id", Name: "ID", Width: 38},
+		{Key: "title", Name: "Title", Width: 30},
+		{Key: "author", Name: "Author", Width: 25},
+		{Key: "genre", Name: "Genre", Width: 15},
+		{Key: "rating", Name: "Rating", Width: 8},
+		{Key: "description", Name: "Description", Width: 40},
+		{Key: "summary", Name: "Summary", Width: 40},
+		{Key: "total_copies", Name: "Total Copies", Width: 12},
+		{Key: "available_copies", Name: "Available Copies", Width: 12}, // read only, kept up to date by circulation
+		{Key: "price", Name: "Price (IDR)", Width: 12},
+		{Key: "cover_url", Name: "Cover URL", Width: 30},
+		{Key: "cover_color", Name: "Cover Color", Width: 12},
+		{Key: "video_url", Name: "Video URL", Width: 30},
+	}
+)
+
+// catalogRow hold a parsed sheet row
+type catalogRow struct {
+	row     int
+	id      *uuid.UUID
+	req     book.BookRequest
+	invalid string
+}
+
+// ExportCatalog write every book into a xlsx sheet which can be edited and sent back to BulkUpdate
+func (s *bookService) ExportCatalog(c context.Context, w io.Writer) error {
+	books, err := s.BookRepo.FindAll(c, &book.BookQuery{QueryParam: model.QueryParam{Sort: "title"}})
+	if err != nil {
+		return exception.ErrorInternal("Failed to get books")
+	}
+
+	format, _ := exporter.Lookup("xlsx")
+	file, err := format.New(w, catalogTitle, catalogColumns)
+	if err != nil {
+		return exception.ErrorInternal("Failed to create export file")
+	}
+
+	for _, b := range books {
+		if err := file.WriteRow(
+			b.ID.String(),
+			b.Title,
+			b.Author,
+			b.Genre,
+			b.Rating,
+			b.Description,
+			b.Summary,
+			b.TotalCopies,
+			b.AvailableCopies,
+			b.Price,
+			b.CoverURL,
+			b.CoverColor,
+			b.VideoURL,
+		); err != nil {
+			return exception.ErrorInternal("Failed to write export file")
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		return exception.ErrorInternal("Failed to write export file")
+	}
+
+	return nil
+}
+
+// BulkUpdate read a catalog sheet, diff every row against current data and apply all changes in one transaction,
+// rows without ID are created, invalid rows are rejected and never applied
+func (s *bookService) BulkUpdate(c context.Context, r io.Reader) (*book.BulkUpdateResult, error) {
+	rows, columns, err := readCatalog(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		if row.id != nil {
+			ids = append(ids, *row.id)
+		}
+	}
+
+	var res *book.BulkUpdateResult
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		// rows are locked until commit so loans and returns made meanwhile are not overwritten
+		books, err := s.BookRepo.FindByIDsForUpdate(c, tx, ids)
+		if err != nil {
+			return err
+		}
+
+		current := make(map[uuid.UUID]book.Book)
+		for _, b := range books {
+			current[b.ID] = b
+		}
+
+		var creates, updates []book.Book
+		res, creates, updates = s.diffCatalog(c, rows, columns, current)
+
+		for i := range creates {
+			if err := s.BookRepo.Create(c, tx, &creates[i]); err != nil {
+				return err
+			}
+		}
+
+		for i := range updates {
+			if err := s.BookRepo.Update(c, tx, &updates[i]); err != nil {
+				return err
+			}
+		}
+
+		if err := s.addEvents(c, tx, constant.Event_BookCreated, creates...); err != nil {
+			return err
+		}
+
+		return s.addEvents(c, tx, constant.Event_BookUpdated, updates...)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to update books")
+	}
+
+	return res, nil
+}
+
+// diffCatalog check every row against current data and return the books to create and update
+func (s *bookService) diffCatalog(
+	c context.Context,
+	rows []catalogRow,
+	columns map[string]bool,
+	current map[uuid.UUID]book.Book,
+) (*book.BulkUpdateResult, []book.Book, []book.Book) {
+	res := &book.BulkUpdateResult{
+		Created:   make([]book.BulkUpdateRow, 0),
+		Updated:   make([]book.BulkUpdateRow, 0),
+		Unchanged: make([]book.BulkUpdateRow, 0),
+		Rejected:  make([]book.BulkUpdateRow, 0),
+	}
+
+	// rows are only collected here, nothing is written before every row has been checked
+	creates := make([]book.Book, 0)
+	updates := make([]book.Book, 0)
+	seen := make(map[uuid.UUID]int)
+
+	for _, row := range rows {
+		result := book.BulkUpdateRow{Row: row.row, ID: row.id, Title: row.req.Title}
+
+		// cells which could not be parsed
+		if row.invalid != "" {
+			result.Reason = row.invalid
+			res.Rejected = append(res.Rejected, result)
+			continue
+		}
+
+		// new book
+		if row.id == nil {
+			if err := s.validateRequest(c, &row.req); err != nil {
+				result.Reason = err.Error()
+				res.Rejected = append(res.Rejected, result)
+				continue
+			}
+			row.req.AvailableCopies = row.req.TotalCopies
+
+			b := newBook(&row.req)
+			result.ID = &b.ID
+			creates = append(creates, b)
+			res.Created = append(res.Created, result)
+			continue
+		}
+
+		if prev, ok := seen[*row.id]; ok {
+			result.Reason = fmt.Sprintf("duplicate of row %d", prev)
+			res.Rejected = append(res.Rejected, result)
+			continue
+		}
+		seen[*row.id] = row.row
+
+		b, ok := current[*row.id]
+		if !ok {
+			result.Reason = "book not found"
+			res.Rejected = append(res.Rejected, result)
+			continue
+		}
+
+		// edition and shelf data are not part of the sheet and are kept as is
+		row.req.ISBN = b.ISBN
+		row.req.WorkID = b.WorkID
+		row.req.Publisher = b.Publisher
+		row.req.PublishedYear = b.PublishedYear
+		row.req.Language = b.Language
+		row.req.Edition = b.Edition
+		row.req.ClassificationScheme = b.ClassificationScheme
+		row.req.CallNumber = b.CallNumber
+		row.req.ShelfID = b.ShelfID
+
+		// columns missing from the sheet are kept as is instead of being cleared
+		keepColumns(&b.BookRequest, &row.req, columns)
+
+		// available copies follow circulation, only copies added or removed in the sheet change them
+		row.req.AvailableCopies = b.AvailableCopies + row.req.TotalCopies - b.TotalCopies
+
+		changes := diffBook(&b.BookRequest, &row.req, columns)
+		if len(changes) == 0 {
+			res.Unchanged = append(res.Unchanged, result)
+			continue
+		}
+
+		if row.req.AvailableCopies < 0 {
+			result.Reason = fmt.Sprintf("total_copies must not be less than the %d copies on loan", b.TotalCopies-b.AvailableCopies)
+			res.Rejected = append(res.Rejected, result)
+			continue
+		}
+
+		if err := s.validateUpdate(c, &row.req); err != nil {
+			result.Reason = err.Error()
+			res.Rejected = append(res.Rejected, result)
+			continue
+		}
+
+		applyRequest(&b, &row.req)
+		b.CoverURL = row.req.CoverURL
+		b.CoverColor = row.req.CoverColor
+
+		result.Changes = changes
+		updates = append(updates, b)
+		res.Updated = append(res.Updated, result)
+	}
+
+	return res, creates, updates
+}
+
+// readCatalog parse rows of the first sheet, columns are matched by header name so they may be reordered or left out,
+// keys of the columns found in the sheet are returned along with the rows
+func readCatalog(r io.Reader) ([]catalogRow, map[string]bool, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, nil, exception.ErrorBadRequest("Invalid xlsx file")
+	}
+	defer file.Close()
+
+	sheet := file.GetSheetName(0)
+	cells, err := file.GetRows(sheet)
+	if err != nil || len(cells) == 0 {
+		return nil, nil, exception.ErrorBadRequest("Sheet is empty")
+	}
+
+	// map column key to cell index
+	index := make(map[string]int)
+	for i, header := range cells[0] {
+		for _, column := range catalogColumns {
+			if strings.EqualFold(strings.TrimSpace(header), column.Name) {
+				index[column.Key] = i
+			}
+		}
+	}
+
+	if _, ok := index["id"]; !ok {
+		return nil, nil, exception.ErrorBadRequest("Column 'ID' is required")
+	}
+
+	columns := make(map[string]bool)
+	for key := range index {
+		columns[key] = true
+	}
+
+	rows := make([]catalogRow, 0)
+	for i, cell := range cells[1:] {
+		value := func(key string) string {
+			j, ok := index[key]
+			if !ok || j >= len(cell) {
+				return ""
+			}
+			return strings.TrimSpace(cell[j])
+		}
+
+		// skip blank rows
+		if strings.TrimSpace(strings.Join(cell, "")) == "" {
+			continue
+		}
+
+		row := catalogRow{row: i + 2}
+
+		row.req = book.BookRequest{
+			Title:       value("title"),
+			Author:      value("author"),
+			Genre:       value("genre"),
+			Description: value("description"),
+			Summary:     value("summary"),
+			CoverURL:    value("cover_url"),
+			CoverColor:  value("cover_color"),
+			VideoURL:    value("video_url"),
+		}
+
+		numbers := map[string]*int{
+			"rating":       &row.req.Rating,
+			"total_copies": &row.req.TotalCopies,
+			"price":        &row.req.Price,
+		}
+
+		for _, column := range catalogColumns {
+			dest, ok := numbers[column.Key]
+			if !ok || value(column.Key) == "" {
+				continue
+			}
+
+			n, err := strconv.Atoi(value(column.Key))
+			if err != nil {
+				row.invalid = column.Key + " must be a number"
+				break
+			}
+			*dest = n
+		}
+
+		if id := value("id"); id != "" {
+			parsed, err := uuid.Parse(id)
+			if err != nil {
+				row.invalid = "Invalid ID"
+			} else {
+				row.id = &parsed
+			}
+		}
+
+		// invalid rows are kept so they are reported as rejected
+		rows = append(rows, row)
+	}
+
+	return rows, columns, nil
+}
+
+// keepColumns copy current values into the request for every column which is not in the sheet
+func keepColumns(current, req *book.BookRequest, columns map[string]bool) {
+	fields := map[string]func(){
+		"title":        func() { req.Title = current.Title },
+		"author":       func() { req.Author = current.Author },
+		"genre":        func() { req.Genre = current.Genre },
+		"rating":       func() { req.Rating = current.Rating },
+		"description":  func() { req.Description = current.Description },
+		"summary":      func() { req.Summary = current.Summary },
+		"total_copies": func() { req.TotalCopies = current.TotalCopies },
+		"price":        func() { req.Price = current.Price },
+		"cover_url":    func() { req.CoverURL = current.CoverURL },
+		"cover_color":  func() { req.CoverColor = current.CoverColor },
+		"video_url":    func() { req.VideoURL = current.VideoURL },
+	}
+
+	for key, keep := range fields {
+		if !columns[key] {
+			keep()
+		}
+	}
+}
+
+// diffBook return keys of columns in the sheet whose value differ between current data and request
+func diffBook(current, req *book.BookRequest, columns map[string]bool) []string {
+	fields := map[string]bool{
+		"title":        current.Title != req.Title,
+		"author":       current.Author != req.Author,
+		"genre":        current.Genre != req.Genre,
+		"rating":       current.Rating != req.Rating,
+		"description":  current.Description != req.Description,
+		"summary":      current.Summary != req.Summary,
+		"total_copies": current.TotalCopies != req.TotalCopies,
+		"price":        current.Price != req.Price,
+		"cover_url":    current.CoverURL != req.CoverURL,
+		"cover_color":  current.CoverColor != req.CoverColor,
+		"video_url":    current.VideoURL != req.VideoURL,
+	}
+
+	// keep sheet column order
+	changes := make([]string, 0)
+	for _, column := range catalogColumns {
+		if columns[column.Key] && fields[column.Key] {
+			changes = append(changes, column.Key)
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,58 @@
+package booksvc
+
+import (
+	"context"
+	"io"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/bookrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/citation"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/google/uuid"
+)
+
+// maximum number of books cited at once from a search result
+var maxCitations = 1000
+
+// Cite write citation of a single book
+func (s *bookService) Cite(c context.Context, id uuid.UUID, format citation.Format, w io.Writer) error {
+	b, err := s.BookRepo.FindByID(c, id)
+	if err != nil {
+		return exception.ErrorNotFound("Book not found")
+	}
+
+	if err := format.Write(w, []citation.Work{b.Citation()}); err != nil {
+		return exception.ErrorInternal("Failed to write citation")
+	}
+
+	return nil
+}
+
+// CiteAll write citations of a search result, page and limit of the filter are honoured
+// and the whole result is cited when no limit is given
+func (s *bookService) CiteAll(c context.Context, filter *book.BookQuery, format citation.Format, w io.Writer) error {
+	// validate filter
+	if filter.Sort == "" {
+		filter.Sort = "title"
+	}
+
+	if _, _, err := query.ValidateSort(filter.Sort, bookrepo.SortBookMap); err != nil {
+		return exception.ErrorBadRequest(err.Error())
+	}
+
+	if filter.Limit == 0 || filter.Limit > maxCitations {
+		filter.Page, filter.Limit = max(filter.Page, 1), maxCitations
+	}
+
+	books, err := s.BookRepo.FindAll(c, filter)
+	if err != nil {
+		return exception.ErrorInternal("Failed to get books")
+	}
+
+	if err := format.Write(w, book.Citations(books)); err != nil {
+		return exception.ErrorInternal("Failed to write citation")
+	}
+
+	return nil
+}
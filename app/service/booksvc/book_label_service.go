@@ -0,0 +1,97 @@
+package booksvc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/label"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/google/uuid"
+)
+
+// Labels write a PDF sheet with spine labels of the requested books,
+// barcodes hold the ISBN so they scan at checkout, or the book ID when there is none
+func (s *bookService) Labels(c context.Context, req *book.LabelRequest, w io.Writer) error {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return exception.ErrorBadRequest(err.Error())
+	}
+
+	if req.Copies == 0 {
+		req.Copies = 1
+	}
+
+	if req.Barcode == "" {
+		req.Barcode = label.Code128
+	}
+
+	layout, err := s.labelLayout(req)
+	if err != nil {
+		return exception.ErrorBadRequest(err.Error())
+	}
+
+	if req.Skip >= layout.PerPage() {
+		return exception.ErrorBadRequest(fmt.Sprintf("skip must be less than %d labels per sheet", layout.PerPage()))
+	}
+
+	books, err := s.BookRepo.FindByIDs(c, req.BookIDs)
+	if err != nil {
+		return exception.ErrorInternal("Failed to get books")
+	}
+
+	found := make(map[uuid.UUID]book.Book)
+	for _, b := range books {
+		found[b.ID] = b
+	}
+
+	// keep the order of the request, a book listed twice is printed twice
+	labels := make([]label.Label, 0)
+	for _, id := range req.BookIDs {
+		b, ok := found[id]
+		if !ok {
+			return exception.ErrorNotFound(fmt.Sprintf("Book with ID %s not found", id))
+		}
+
+		code := b.ISBN
+		if code == "" {
+			code = b.ID.String()
+		}
+
+		// subtitle does not fit on a spine label
+		title, _, _ := strings.Cut(b.Title, ":")
+
+		for range req.Copies {
+			labels = append(labels, label.Label{
+				CallNumber: b.CallNumber,
+				Title:      strings.TrimSpace(title),
+				Code:       code,
+			})
+		}
+	}
+
+	if err := label.Write(w, layout, req.Barcode, labels, req.Skip); err != nil {
+		return exception.ErrorInternal("Failed to write labels")
+	}
+
+	return nil
+}
+
+func (s *bookService) labelLayout(req *book.LabelRequest) (label.Layout, error) {
+	if req.CustomLayout != nil {
+		layout := *req.CustomLayout
+		if layout.Name == "" {
+			layout.Name = "custom"
+		}
+
+		return layout, layout.Check()
+	}
+
+	if req.Layout == "" {
+		req.Layout = label.DefaultLayout
+	}
+
+	return label.LookupLayout(req.Layout)
+}
@@ -0,0 +1,245 @@
+package booksvc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/callnumber"
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/marc"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// first four digit year of 260/264 $c, e.g. "c1997." or "[2016]"
+	yearPattern = regexp.MustCompile(`\d{4}`)
+
+	// import at most this many records per file
+	maxMarcRecords = 5000
+)
+
+// ImportMarc create a book for every MARC21 or MARCXML record of the file,
+// records which cannot be mapped are rejected and the others are created in one transaction
+func (s *bookService) ImportMarc(c context.Context, r io.Reader, req *book.MarcImportRequest) (*book.MarcImportResult, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	if req.TotalCopies == 0 {
+		req.TotalCopies = 1
+	}
+
+	if req.Rating == 0 {
+		req.Rating = 3
+	}
+
+	res := &book.MarcImportResult{
+		Created:  make([]book.MarcImportRow, 0),
+		Rejected: make([]book.MarcImportRow, 0),
+	}
+
+	creates := make([]book.Book, 0)
+	seen := make(map[string]int)
+	reader := marc.NewReader(r)
+
+	for index := 1; ; index++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		// a broken record is skipped, anything else means the file cannot be read further
+		var recordErr *marc.RecordError
+		if errors.As(err, &recordErr) {
+			res.Rejected = append(res.Rejected, book.MarcImportRow{Record: index, Errors: []string{recordErr.Err.Error()}})
+			continue
+		}
+
+		if err != nil {
+			return nil, exception.ErrorBadRequest("Failed to read MARC file: " + err.Error())
+		}
+
+		if index > maxMarcRecords {
+			return nil, exception.ErrorBadRequest("MARC file must not contain more than " + strconv.Itoa(maxMarcRecords) + " records")
+		}
+
+		bookReq, errs := bookFromRecord(record, req)
+		row := book.MarcImportRow{Record: index, Title: bookReq.Title, Errors: errs}
+
+		if len(errs) == 0 {
+			if err := s.validateRequest(c, &bookReq); err != nil {
+				row.Errors = append(row.Errors, err.Error())
+			}
+		}
+
+		// the same isbn is only imported once
+		if bookReq.ISBN != "" && len(row.Errors) == 0 {
+			if prev, ok := seen[bookReq.ISBN]; ok {
+				row.Errors = append(row.Errors, "isbn is a duplicate of record "+strconv.Itoa(prev))
+			} else if _, err := s.BookRepo.FindByISBN(c, bookReq.ISBN); err == nil {
+				row.Errors = append(row.Errors, "isbn already exists in catalog")
+			}
+			seen[bookReq.ISBN] = index
+		}
+
+		if len(row.Errors) > 0 {
+			res.Rejected = append(res.Rejected, row)
+			continue
+		}
+
+		bookReq.AvailableCopies = bookReq.TotalCopies
+		b := newBook(&bookReq)
+		row.ID = &b.ID
+		creates = append(creates, b)
+		res.Created = append(res.Created, row)
+	}
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		for i := range creates {
+			if err := s.BookRepo.Create(c, tx, &creates[i]); err != nil {
+				return err
+			}
+		}
+
+		return s.addEvents(c, tx, constant.Event_BookCreated, creates...)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to import books")
+	}
+
+	return res, nil
+}
+
+// ExportMarcXML write a book as a MARCXML record
+func (s *bookService) ExportMarcXML(c context.Context, id uuid.UUID, w io.Writer) error {
+	b, err := s.BookRepo.FindByID(c, id)
+	if err != nil {
+		return exception.ErrorNotFound("Book not found")
+	}
+
+	if err := marc.WriteXML(w, recordFromBook(b)); err != nil {
+		return exception.ErrorInternal("Failed to write MARC record")
+	}
+
+	return nil
+}
+
+// bookFromRecord map 020 isbn, 100 author, 245 title, 520 summary and 650 genre,
+// plus 250 edition and 260/264 publication when present, into a book request
+func bookFromRecord(r *marc.Record, req *book.MarcImportRequest) (book.BookRequest, []string) {
+	errs := make([]string, 0)
+
+	b := book.BookRequest{
+		TotalCopies: req.TotalCopies,
+		Rating:      req.Rating,
+		Price:       req.Price,
+	}
+
+	// qualifiers follow the number, e.g. "9780747532699 (hbk.)"
+	if isbn := strings.Fields(r.Subfield("020", "a")); len(isbn) > 0 {
+		b.ISBN = lib.NormalizeISBN(isbn[0])
+	}
+
+	b.Title = marc.TrimPunctuation(r.Subfield("245", "a"))
+	if subtitle := marc.TrimPunctuation(r.Subfield("245", "b")); subtitle != "" {
+		b.Title += ": " + subtitle
+	}
+	if b.Title == "" {
+		errs = append(errs, "245 $a title is missing")
+	}
+
+	b.Author = marc.TrimPunctuation(r.Subfield("100", "a"))
+	if b.Author == "" {
+		b.Author = marc.TrimPunctuation(r.Subfield("110", "a"))
+	}
+	if b.Author == "" {
+		errs = append(errs, "100 $a author is missing")
+	}
+
+	b.Summary = strings.TrimSpace(r.Subfield("520", "a"))
+	b.Description = shorten(b.Summary, 255)
+	if b.Summary == "" {
+		errs = append(errs, "520 $a summary is missing")
+	}
+
+	b.Genre = marc.TrimPunctuation(r.Subfield("650", "a"))
+	if b.Genre == "" {
+		errs = append(errs, "650 $a subject is missing")
+	}
+
+	b.Edition = marc.TrimPunctuation(r.Subfield("250", "a"))
+
+	// 264 replaced 260 in RDA records
+	publication := "264"
+	if r.Subfield(publication, "b") == "" && r.Subfield(publication, "c") == "" {
+		publication = "260"
+	}
+	b.Publisher = marc.TrimPunctuation(r.Subfield(publication, "b"))
+	if year := yearPattern.FindString(r.Subfield(publication, "c")); year != "" {
+		y, _ := strconv.Atoi(year)
+		b.PublishedYear = &y
+	}
+
+	b.Language = r.Subfield("041", "a")
+	if fixed := r.Control("008"); b.Language == "" && len(fixed) >= 38 {
+		b.Language = strings.TrimSpace(fixed[35:38])
+	}
+
+	return b, errs
+}
+
+// recordFromBook map a book onto the same fields read by bookFromRecord
+func recordFromBook(b *book.Book) *marc.Record {
+	r := marc.NewRecord()
+	r.AddControl("001", b.ID.String())
+
+	r.AddData("020", " ", " ", "a", b.ISBN)
+	r.AddData("041", " ", " ", "a", b.Language)
+
+	switch b.ClassificationScheme {
+	case callnumber.Dewey:
+		r.AddData("082", "0", "4", "a", b.CallNumber)
+	case callnumber.LibraryCongress:
+		r.AddData("050", " ", "4", "a", b.CallNumber)
+	}
+
+	r.AddData("100", "1", " ", "a", b.Author)
+	r.AddData("245", "1", "0", "a", b.Title, "c", b.Author)
+	r.AddData("250", " ", " ", "a", b.Edition)
+
+	var year string
+	if b.PublishedYear != nil {
+		year = strconv.Itoa(*b.PublishedYear)
+	}
+	r.AddData("264", " ", "1", "b", b.Publisher, "c", year)
+
+	r.AddData("520", " ", " ", "a", b.Summary)
+	r.AddData("650", " ", "4", "a", b.Genre)
+
+	return r
+}
+
+// shorten cut value to at most n bytes without splitting a character
+func shorten(value string, n int) string {
+	if len(value) <= n {
+		return value
+	}
+
+	cut := 0
+	for i := range value {
+		if i > n-3 {
+			break
+		}
+		cut = i
+	}
+
+	return value[:cut] + "..."
+}
@@ -2,50 +2,118 @@ package booksvc
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/dikyayodihamzah/library-management-api/app/repository/bookrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/locationrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/outboxrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/readinglistrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/workrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/callnumber"
+	"github.com/dikyayodihamzah/library-management-api/pkg/citation"
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
 	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
 	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
-	"github.com/dikyayodihamzah/library-management-api/pkg/model"
 	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/event"
 	"github.com/dikyayodihamzah/library-management-api/pkg/query"
 	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
+var (
+	suggestTTL       = time.Duration(utils.GetInt("SUGGEST_CACHE_SECONDS", 60)) * time.Second
+	suggestCacheSize = utils.GetInt("SUGGEST_CACHE_SIZE", 10000)
+)
+
 type BookService interface {
 	Create(c context.Context, req *book.BookRequest) (*book.Book, error)
-	FindAll(c context.Context, filter *model.QueryParam) ([]book.Book, int, error)
-	FindByID(c context.Context, id uuid.UUID) (*book.Book, error)
+	FindAll(c context.Context, filter *book.BookQuery) ([]book.Book, int, error)
+	FindByID(c context.Context, id, userID uuid.UUID) (*book.BookDetail, error)
+	FindByShelf(c context.Context, shelfID uuid.UUID, filter *book.BookQuery) ([]book.Book, int, error)
 	Update(c context.Context, id uuid.UUID, req *book.BookRequest) (*book.Book, error)
 	Delete(c context.Context, id uuid.UUID) error
+
+	Suggest(c context.Context, keyword string, limit int) ([]book.Suggestion, error)
+
+	ExportCatalog(c context.Context, w io.Writer) error
+	BulkUpdate(c context.Context, r io.Reader) (*book.BulkUpdateResult, error)
+
+	ImportMarc(c context.Context, r io.Reader, req *book.MarcImportRequest) (*book.MarcImportResult, error)
+	ExportMarcXML(c context.Context, id uuid.UUID, w io.Writer) error
+
+	Cite(c context.Context, id uuid.UUID, format citation.Format, w io.Writer) error
+	CiteAll(c context.Context, filter *book.BookQuery, format citation.Format, w io.Writer) error
+
+	Labels(c context.Context, req *book.LabelRequest, w io.Writer) error
 }
 
 type bookService struct {
-	Validate  *validator.Validate
-	TxManager transaction.Manager
-	BookRepo  bookrepo.BookRepository
+	Validate        *validator.Validate
+	TxManager       transaction.Manager
+	BookRepo        bookrepo.BookRepository
+	ReadingListRepo readinglistrepo.ReadingListRepository
+	WorkRepo        workrepo.WorkRepository
+	LocationRepo    locationrepo.LocationRepository
+	OutboxRepo      outboxrepo.OutboxRepository
+	SuggestCache    *lib.TTLCache[string, []book.Suggestion]
 }
 
 func New(
 	validate *validator.Validate,
 	txManager transaction.Manager,
 	bookRepo bookrepo.BookRepository,
+	readingListRepo readinglistrepo.ReadingListRepository,
+	workRepo workrepo.WorkRepository,
+	locationRepo locationrepo.LocationRepository,
+	outboxRepo outboxrepo.OutboxRepository,
 ) BookService {
 	return &bookService{
-		Validate:  validate,
-		TxManager: txManager,
-		BookRepo:  bookRepo,
+		Validate:        validate,
+		TxManager:       txManager,
+		BookRepo:        bookRepo,
+		ReadingListRepo: readingListRepo,
+		WorkRepo:        workRepo,
+		LocationRepo:    locationRepo,
+		OutboxRepo:      outboxRepo,
+		SuggestCache:    lib.NewTTLCache[string, []book.Suggestion](suggestTTL, suggestCacheSize),
 	}
 }
 
 func (s *bookService) Create(c context.Context, req *book.BookRequest) (*book.Book, error) {
 	// validate request
+	if err := s.validateRequest(c, req); err != nil {
+		return nil, err
+	}
+	req.AvailableCopies = req.TotalCopies
+
+	// create new book data
+	b := newBook(req)
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		if err := s.BookRepo.Create(c, tx, &b); err != nil {
+			return err
+		}
+
+		return s.addEvents(c, tx, constant.Event_BookCreated, b)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to create book")
+	}
+
+	return &b, nil
+}
+
+// validateRequest validate rules shared by every book write
+func (s *bookService) validateRequest(c context.Context, req *book.BookRequest) error {
+	req.ISBN = lib.NormalizeISBN(req.ISBN)
 	if err := s.Validate.Struct(req); err != nil {
-		return nil, exception.ErrorBadRequest(err.Error())
+		return exception.ErrorBadRequest(err.Error())
 	}
 
 	arr := map[string]string{
@@ -57,40 +125,113 @@ func (s *bookService) Create(c context.Context, req *book.BookRequest) (*book.Bo
 
 	for key, value := range arr {
 		if len(value) > 255 {
-			return nil, exception.ErrorBadRequest(key + " must be less than 255 characters")
+			return exception.ErrorBadRequest(key + " must be less than 255 characters")
 		}
 	}
 
 	if req.Rating < 1 || req.Rating > 5 {
-		return nil, exception.ErrorBadRequest("rating must be between 1 and 5")
+		return exception.ErrorBadRequest("rating must be between 1 and 5")
 	}
 
 	if req.TotalCopies < 1 {
-		return nil, exception.ErrorBadRequest("total_copies must be greater than 0")
+		return exception.ErrorBadRequest("total_copies must be greater than 0")
 	}
-	req.AvailableCopies = req.TotalCopies
 
 	if req.Price < 0 {
-		return nil, exception.ErrorBadRequest("price must be greater than or equal to 0")
+		return exception.ErrorBadRequest("price must be greater than or equal to 0")
 	}
 
-	// create new book data
+	if req.WorkID != nil {
+		if _, err := s.WorkRepo.FindByID(c, *req.WorkID); err != nil {
+			return exception.ErrorBadRequest("Work not found")
+		}
+	}
+
+	// call number must follow its scheme
+	req.CallNumber = callnumber.Normalize(req.CallNumber)
+	if req.CallNumber != "" || req.ClassificationScheme != "" {
+		if err := callnumber.Validate(req.ClassificationScheme, req.CallNumber); err != nil {
+			return exception.ErrorBadRequest(err.Error())
+		}
+	}
+
+	if req.ShelfID != nil {
+		shelf, err := s.LocationRepo.FindByID(c, *req.ShelfID)
+		if err != nil {
+			return exception.ErrorBadRequest("Shelf not found")
+		}
+
+		if shelf.Type != constant.LocationType_Shelf {
+			return exception.ErrorBadRequest("shelf_id must refer to a shelf")
+		}
+	}
+
+	return nil
+}
+
+// validateUpdate validate request of an existing book
+func (s *bookService) validateUpdate(c context.Context, req *book.BookRequest) error {
+	if err := s.validateRequest(c, req); err != nil {
+		return err
+	}
+
+	if req.AvailableCopies > req.TotalCopies {
+		return exception.ErrorBadRequest("available_copies must be less than or equal to total_copies")
+	}
+
+	return nil
+}
+
+// addEvents write an event of every book into the outbox
+func (s *bookService) addEvents(c context.Context, tx pgx.Tx, eventType string, books ...book.Book) error {
+	events := make([]event.Event, 0, len(books))
+	for i := range books {
+		e, err := event.New(eventType, books[i].ID, &books[i])
+		if err != nil {
+			return err
+		}
+		events = append(events, e)
+	}
+
+	return s.OutboxRepo.Add(c, tx, events...)
+}
+
+func newBook(req *book.BookRequest) book.Book {
 	b := book.Book{
 		BookRequest: *req,
 	}
 	b.ID = uuid.New()
+	b.CallNumberSort = callnumber.SortKey(req.ClassificationScheme, req.CallNumber)
 	b.CreatedAt = lib.Pointer(time.Now())
+	return b
+}
 
-	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
-		return s.BookRepo.Create(c, tx, &b)
-	}); err != nil {
-		return nil, exception.ErrorInternal("Failed to create book")
-	}
-
-	return &b, nil
+// applyRequest copy editable fields of request into book
+func applyRequest(b *book.Book, req *book.BookRequest) {
+	b.ISBN = req.ISBN
+	b.Title = req.Title
+	b.Author = req.Author
+	b.Genre = req.Genre
+	b.Rating = req.Rating
+	b.Description = req.Description
+	b.TotalCopies = req.TotalCopies
+	b.AvailableCopies = req.AvailableCopies
+	b.VideoURL = req.VideoURL
+	b.Summary = req.Summary
+	b.Price = req.Price
+	b.WorkID = req.WorkID
+	b.Publisher = req.Publisher
+	b.PublishedYear = req.PublishedYear
+	b.Language = req.Language
+	b.Edition = req.Edition
+	b.ClassificationScheme = req.ClassificationScheme
+	b.CallNumber = req.CallNumber
+	b.CallNumberSort = callnumber.SortKey(req.ClassificationScheme, req.CallNumber)
+	b.ShelfID = req.ShelfID
+	b.UpdatedAt = lib.Pointer(time.Now())
 }
 
-func (s *bookService) FindAll(c context.Context, filter *model.QueryParam) ([]book.Book, int, error) {
+func (s *bookService) FindAll(c context.Context, filter *book.BookQuery) ([]book.Book, int, error) {
 	// validate filter
 	if filter.Sort == "" {
 		filter.Sort = "-created_at"
@@ -115,71 +256,124 @@ func (s *bookService) FindAll(c context.Context, filter *model.QueryParam) ([]bo
 	return books, total, nil
 }
 
-func (s *bookService) FindByID(c context.Context, id uuid.UUID) (*book.Book, error) {
-	// get book data
-	b, err := s.BookRepo.FindByID(c, id)
-	if err != nil {
-		return nil, exception.ErrorNotFound("Book not found")
+// FindByShelf return books placed on a shelf in call number order
+func (s *bookService) FindByShelf(c context.Context, shelfID uuid.UUID, filter *book.BookQuery) ([]book.Book, int, error) {
+	shelf, err := s.LocationRepo.FindByID(c, shelfID)
+	if err != nil || shelf.Type != constant.LocationType_Shelf {
+		return nil, 0, exception.ErrorNotFound("Shelf not found")
 	}
 
-	return b, nil
+	filter.ShelfID = shelfID
+	if filter.Sort == "" {
+		filter.Sort = "call_number"
+	}
+
+	return s.FindAll(c, filter)
 }
 
-func (s *bookService) Update(c context.Context, id uuid.UUID, req *book.BookRequest) (*book.Book, error) {
+func (s *bookService) FindByID(c context.Context, id, userID uuid.UUID) (*book.BookDetail, error) {
 	// get book data
 	b, err := s.BookRepo.FindByID(c, id)
 	if err != nil {
 		return nil, exception.ErrorNotFound("Book not found")
 	}
 
-	// validate request
-	if err := s.Validate.Struct(req); err != nil {
-		return nil, exception.ErrorBadRequest(err.Error())
+	// get caller's lists containing the book
+	lists, err := s.ReadingListRepo.FindContaining(c, userID, id)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get reading lists")
 	}
 
-	arr := map[string]string{
-		"title":       req.Title,
-		"author":      req.Author,
-		"genre":       req.Genre,
-		"description": req.Description,
+	detail := &book.BookDetail{
+		Book:         *b,
+		ReadingLists: lists,
+		JSONLD:       b.Schema(),
 	}
 
-	for key, value := range arr {
-		if len(value) > 255 {
-			return nil, exception.ErrorBadRequest(key + " must be less than 255 characters")
+	if b.ShelfID != nil {
+		if detail.Location, err = s.LocationRepo.FindPath(c, *b.ShelfID); err != nil {
+			return nil, exception.ErrorInternal("Failed to get book location")
 		}
 	}
 
-	if req.Rating < 1 || req.Rating > 5 {
-		return nil, exception.ErrorBadRequest("rating must be between 1 and 5")
+	if b.WorkID == nil {
+		return detail, nil
 	}
 
-	if req.TotalCopies < 1 {
-		return nil, exception.ErrorBadRequest("total_copies must be greater than 0")
+	// get sibling editions and next volume of the series
+	if detail.Work, err = s.WorkRepo.FindByID(c, *b.WorkID); err != nil {
+		return nil, exception.ErrorInternal("Failed to get work")
 	}
 
-	if req.AvailableCopies > req.TotalCopies {
-		return nil, exception.ErrorBadRequest("available_copies must be less than or equal to total_copies")
+	editions, err := s.WorkRepo.FindEditions(c, *b.WorkID)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get editions")
 	}
 
-	if req.Price < 0 {
-		return nil, exception.ErrorBadRequest("price must be greater than or equal to 0")
+	detail.Editions = make([]book.Edition, 0)
+	for _, e := range editions {
+		if e.ID != b.ID {
+			detail.Editions = append(detail.Editions, e)
+		}
+	}
+
+	if detail.Work.SeriesID != nil && detail.Work.VolumeNumber != nil {
+		// last volume has no next volume
+		if next, err := s.WorkRepo.FindNextVolume(c, *detail.Work.SeriesID, *detail.Work.VolumeNumber); err == nil {
+			detail.NextVolume = next
+		}
+	}
+
+	return detail, nil
+}
+
+// Suggest return autocomplete entries for keyword, results are cached briefly
+// since this is called on every keystroke
+func (s *bookService) Suggest(c context.Context, keyword string, limit int) ([]book.Suggestion, error) {
+	keyword = strings.ToLower(lib.TrimSpace(keyword))
+	if keyword == "" {
+		return make([]book.Suggestion, 0), nil
+	}
+
+	if limit < 1 || limit > 20 {
+		limit = 10
+	}
+
+	key := fmt.Sprintf("%d:%s", limit, keyword)
+	if suggestions, ok := s.SuggestCache.Get(key); ok {
+		return suggestions, nil
+	}
+
+	suggestions, err := s.BookRepo.Suggest(c, keyword, limit)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get suggestions")
+	}
+
+	s.SuggestCache.Set(key, suggestions)
+	return suggestions, nil
+}
+
+func (s *bookService) Update(c context.Context, id uuid.UUID, req *book.BookRequest) (*book.Book, error) {
+	// get book data
+	b, err := s.BookRepo.FindByID(c, id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("Book not found")
+	}
+
+	// validate request
+	if err := s.validateUpdate(c, req); err != nil {
+		return nil, err
 	}
 
 	// update book data
-	b.Title = req.Title
-	b.Author = req.Author
-	b.Genre = req.Genre
-	b.Rating = req.Rating
-	b.Description = req.Description
-	b.TotalCopies = req.TotalCopies
-	b.AvailableCopies = req.AvailableCopies
-	b.VideoURL = req.VideoURL
-	b.Summary = req.Summary
-	b.Price = req.Price
+	applyRequest(b, req)
 
 	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
-		return s.BookRepo.Update(c, tx, b)
+		if err := s.BookRepo.Update(c, tx, b); err != nil {
+			return err
+		}
+
+		return s.addEvents(c, tx, constant.Event_BookUpdated, *b)
 	}); err != nil {
 		return nil, exception.ErrorInternal("Failed to update book")
 	}
@@ -0,0 +1,86 @@
+package borrowsvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/google/uuid"
+)
+
+const maxForecastDays = 90
+
+// Availability estimate when the book is next available and project copies on the shelf for the next days,
+// every open loan is assumed to be returned on its due date while overdue loans are left out
+func (s *borrowService) Availability(c context.Context, bookID uuid.UUID, days int, loc *time.Location) (*book.Availability, error) {
+	if days < 1 {
+		days = 14
+	}
+
+	if days > maxForecastDays {
+		return nil, exception.ErrorBadRequest("days must be less than or equal to 90")
+	}
+
+	b, err := s.BookRepo.FindByID(c, bookID)
+	if err != nil {
+		return nil, exception.ErrorNotFound("Book not found")
+	}
+
+	dueDates, err := s.BorrowRepo.FindOpenDueDates(c, bookID)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get open loans")
+	}
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	res := &book.Availability{
+		BookID:          b.ID,
+		TotalCopies:     b.TotalCopies,
+		AvailableCopies: b.AvailableCopies,
+		OpenLoans:       len(dueDates),
+		Calendar:        make([]book.AvailabilityDay, 0),
+	}
+
+	// count returns per local day
+	returning := make(map[string]int)
+	for _, dueDate := range dueDates {
+		if dueDate.Before(now) {
+			res.OverdueLoans++
+			continue
+		}
+
+		returning[dueDate.In(loc).Format(time.DateOnly)]++
+	}
+
+	if b.AvailableCopies > 0 {
+		res.NextAvailable = &now
+	}
+
+	available := b.AvailableCopies
+	for i := 0; i < days; i++ {
+		day := today.AddDate(0, 0, i)
+		date := day.Format(time.DateOnly)
+
+		available = min(available+returning[date], b.TotalCopies)
+		res.Calendar = append(res.Calendar, book.AvailabilityDay{
+			Date:      date,
+			Available: available,
+			Returning: returning[date],
+		})
+	}
+
+	// earliest due date is the next expected return, even beyond the calendar
+	if res.NextAvailable == nil {
+		for _, dueDate := range dueDates {
+			if !dueDate.Before(now) {
+				next := dueDate.In(loc)
+				res.NextAvailable = &next
+				break
+			}
+		}
+	}
+
+	return res, nil
+}
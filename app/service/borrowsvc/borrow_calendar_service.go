@@ -0,0 +1,71 @@
+package borrowsvc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/ical"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// length of the due date event, clients hide zero length events
+const calendarEventLength = 30 * time.Minute
+
+// LoanCalendar write open loans of a user as an iCalendar feed,
+// the UID of an event is the borrow ID so clients update it in place
+// when the due date changes and drop it once the book is returned
+func (s *borrowService) LoanCalendar(c context.Context, userID uuid.UUID, w io.Writer) error {
+	reminder := time.Duration(utils.GetInt("ICAL_REMINDER_HOURS", 24)) * time.Hour
+
+	cal := &ical.Calendar{
+		ProdID: "-//Library Management API//Loans//EN",
+		Name:   "Library loans",
+		Events: make([]ical.Event, 0),
+	}
+
+	filter := &book.BorrowQuery{
+		UserID: userID,
+		Status: "BORROWED",
+	}
+	filter.Sort = "due_date"
+
+	if err := s.BorrowRepo.Stream(c, filter, func(borrow *book.BorrowDTO) error {
+		event := ical.Event{
+			UID:         fmt.Sprintf("%s@library", borrow.ID),
+			Summary:     fmt.Sprintf("Return \"%s\"", borrow.BookTitle),
+			Description: fmt.Sprintf("Borrowed on %s", borrow.BorrowDate.Format("2006-01-02")),
+			Start:       borrow.DueDate,
+			End:         borrow.DueDate.Add(calendarEventLength),
+			Alarm: &ical.Alarm{
+				Before:      reminder,
+				Description: fmt.Sprintf("\"%s\" is due soon", borrow.BookTitle),
+			},
+		}
+		// URL must be absolute, so it is left out when no public address is configured
+		if url := lib.PublicURL("books", borrow.BookID.String()); strings.HasPrefix(url, "http") {
+			event.URL = url
+		}
+		if borrow.CreatedAt != nil {
+			event.LastModified = *borrow.CreatedAt
+		}
+
+		cal.Events = append(cal.Events, event)
+		return nil
+	}); err != nil {
+		return exception.ErrorInternal("Failed to get loans")
+	}
+
+	if err := cal.Write(w); err != nil {
+		s.Logger.Errorw("Failed to write loan calendar", "error", err)
+		return exception.ErrorInternal("Failed to write calendar")
+	}
+
+	return nil
+}
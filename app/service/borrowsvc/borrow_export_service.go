@@ -1,158 +1,107 @@
 package borrowsvc
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"os"
-	"time"
+	"io"
 
-	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/borrowrepo"
 	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exporter"
 	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
 )
 
 var (
-	excelTemplate = "borrow-template.xlsx"
-	sheetName     = "Borrow List"
+	exportTitle = "Borrow List"
+
+	// report progress every n rows
+	progressInterval = 500
+
+	// same column set is used for every export format
+	exportColumns = []exporter.Column{
+		{Key: "no", Name: "No", Width: 5},
+		{Key: "book_id", Name: "Book ID", Width: 30},
+		{Key: "book_title", Name: "Book Title", Width: 30},
+		{Key: "borrower", Name: "Borrower", Width: 30},
+		{Key: "due_date", Name: "Due Date", Width: 30},
+		{Key: "status", Name: "Status", Width: 10},
+		{Key: "total_price", Name: "Total Price (IDR)", Width: 15},
+		{Key: "created_at", Name: "Created At", Width: 30},
+	}
 )
 
-func (s *borrowService) writeTemplate() error {
-	// create template
-	s.Logger.Info("Creating template")
-	f, err := os.Create(excelTemplate)
-	if err != nil {
-		s.Logger.Errorw("Failed to create template", "error", err)
-		return err
-	}
-	defer f.Close()
-
-	// write template
-	file := excelize.NewFile()
-
-	// set sheet name
-	file.SetSheetName("Sheet1", sheetName)
-
-	// set header
-	headers := []string{
-		"No",
-		"Book ID",
-		"Book Title",
-		"Borrower",
-		"Due Date",
-		"Status",
-		"Total Price (IDR)",
-		"Created At",
-	}
+// ProgressFunc report how many rows have been written out of total
+type ProgressFunc func(done, total int)
 
-	for i, header := range headers {
-		cell := string(rune(65+i)) + "1"
-		file.SetCellValue(sheetName, cell, header)
+// Export stream every borrow record matching filter into w using given format,
+// page and limit of the filter are ignored so the full result set is exported
+func (s *borrowService) Export(c context.Context, filter *book.BorrowQuery, format exporter.Format, w io.Writer, progress ProgressFunc) error {
+	// validate filter
+	if filter.Sort == "" {
+		filter.Sort = "-borrow_date"
 	}
 
-	// set width
-	file.SetColWidth(sheetName, "A", "A", 5)
-	file.SetColWidth(sheetName, "B", "B", 30)
-	file.SetColWidth(sheetName, "C", "C", 30)
-	file.SetColWidth(sheetName, "D", "D", 30)
-	file.SetColWidth(sheetName, "E", "E", 30)
-	file.SetColWidth(sheetName, "F", "F", 10)
-	file.SetColWidth(sheetName, "G", "G", 15)
-	file.SetColWidth(sheetName, "H", "H", 30)
-
-	// set style
-	headerStyle, err := file.NewStyle(`
-	{
-		"alignment":
-			{
-				"horizontal":"center",
-				"vertical":"center"
-			},
-		"font":{"bold":true},
-		"fill":
-			{
-				"type":"pattern",
-				"color":["#f4b084"],
-				"pattern":1
-			}
-	}`)
-	if err != nil {
-		s.Logger.Errorw("Failed to create header style", "error", err)
+	if _, _, err := query.ValidateSort(filter.Sort, borrowrepo.SortBorrowMap); err != nil {
+		return exception.ErrorBadRequest(err.Error())
 	}
 
-	// set style to body
-	bodyStyle, err := file.NewStyle(`{"alignment":{"wrap_text":true}}`)
+	total, err := s.BorrowRepo.Count(c, filter)
 	if err != nil {
-		s.Logger.Errorw("Failed to create body style", "error", err)
+		return exception.ErrorInternal("Failed to get total borrows")
 	}
 
-	// set style to header
-	for i := 1; i <= len(headers); i++ {
-		cell := string(rune(65+i-1)) + "1"
-		file.SetCellStyle(sheetName, cell, cell, headerStyle)
+	if progress != nil {
+		progress(0, total)
 	}
 
-	// set style to body
-	for i := 2; i <= 1000; i++ {
-		for j := 1; j <= len(headers); j++ {
-			cell := string(rune(65+j-1)) + fmt.Sprintf("%d", i)
-			file.SetCellStyle(sheetName, cell, cell, bodyStyle)
-		}
-	}
-
-	// save file
-	if err := file.SaveAs(excelTemplate); err != nil {
-		s.Logger.Errorw("Failed to save template", "error", err)
-		return err
+	file, err := format.New(w, exportTitle, exportColumns)
+	if err != nil {
+		s.Logger.Errorw("Failed to create exporter", "format", format.Name, "error", err)
+		return exception.ErrorInternal("Failed to create export file")
 	}
 
-	return nil
-}
-
-func (s *borrowService) GenerateExcel(c context.Context, filter *book.BorrowQuery, timezone int) (*bytes.Buffer, error) {
-	if _, err := os.Stat(excelTemplate); os.IsNotExist(err) {
-		if err := s.writeTemplate(); err != nil {
-			return nil, exception.ErrorInternal("Failed to write template")
+	// dates are rendered in the zone of the filter
+	loc := filter.Location()
+
+	// write data rows straight from database rows
+	done, totalPrice := 0, 0
+	if err := s.BorrowRepo.Stream(c, filter, func(borrow *book.BorrowDTO) error {
+		done++
+		totalPrice += borrow.TotalPrice
+		if err := file.WriteRow(
+			done,
+			borrow.BookID.String(),
+			borrow.BookTitle,
+			borrow.UserName,
+			borrow.DueDate.In(loc).Format("2006-01-02 15:04:05"),
+			borrow.Status,
+			borrow.TotalPrice,
+			borrow.CreatedAt.In(loc).Format("2006-01-02 15:04:05"),
+		); err != nil {
+			return err
 		}
-	}
-
-	borrows, _, err := s.FindAll(c, filter)
-	if err != nil {
-		return nil, exception.ErrorInternal("Failed to get borrows")
-	}
 
-	// get template
-	file, err := os.Open(excelTemplate)
-	if err != nil {
-		return nil, exception.ErrorInternal("Failed to open template")
-	}
+		if progress != nil && done%progressInterval == 0 {
+			progress(done, total)
+		}
 
-	// read template
-	xlsx, err := excelize.OpenReader(file)
-	if err != nil {
-		return nil, exception.ErrorInternal("Failed to read template")
+		return nil
+	}); err != nil {
+		s.Logger.Errorw("Failed to write export rows", "format", format.Name, "error", err)
+		return exception.ErrorInternal("Failed to write export file")
 	}
 
-	additionalDuration := time.Duration(timezone) * time.Hour
-
-	// Write data rows
-	for i, borrow := range borrows {
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("A%d", i+2), i+1)
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("B%d", i+2), borrow.Book.ID)
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("C%d", i+2), borrow.Book.Name)
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("D%d", i+2), borrow.User.Name)
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("E%d", i+2), borrow.DueDate.Add(additionalDuration).Format("2006-01-02 15:04:05"))
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("F%d", i+2), borrow.Status)
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("G%d", i+2), borrow.TotalPrice)
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("H%d", i+2), borrow.CreatedAt.Add(additionalDuration).Format("2006-01-02 15:04:05"))
+	// totals row, only written by formats meant for printing
+	totals := []interface{}{"Total", nil, fmt.Sprintf("%d borrows", done), nil, nil, nil, totalPrice, nil}
+	if err := file.Close(totals...); err != nil {
+		s.Logger.Errorw("Failed to write export file", "format", format.Name, "error", err)
+		return exception.ErrorInternal("Failed to write export file")
 	}
 
-	// Save file to buffer
-	var buf bytes.Buffer
-	if err := xlsx.Write(&buf); err != nil {
-		s.Logger.Errorw("Failed to write Excel file to buffer", "error", err)
-		return nil, err
+	if progress != nil {
+		progress(done, max(total, done))
 	}
 
-	return &buf, nil
+	return nil
 }
@@ -0,0 +1,94 @@
+package borrowsvc
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/notification"
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// SendDueReminders notify members of loans due within DUE_REMINDER_HOURS,
+// every loan is reminded once and loans of the same member share one message
+func (s *borrowService) SendDueReminders(c context.Context) (int, error) {
+	now := time.Now()
+	until := now.Add(time.Duration(utils.GetInt("DUE_REMINDER_HOURS", 48)) * time.Hour)
+
+	borrows, err := s.BorrowRepo.FindDueSoon(c, now, until)
+	if err != nil {
+		return 0, exception.ErrorInternal("Failed to get loans due soon")
+	}
+
+	return s.notifyLoans(c, borrows, constant.NotifType_DueSoon, now, s.BorrowRepo.MarkDueReminderSent)
+}
+
+// SendOverdueNotices notify members of loans past their due date, once per loan
+func (s *borrowService) SendOverdueNotices(c context.Context) (int, error) {
+	now := time.Now()
+
+	borrows, err := s.BorrowRepo.FindOverdue(c, now)
+	if err != nil {
+		return 0, exception.ErrorInternal("Failed to get overdue loans")
+	}
+
+	return s.notifyLoans(c, borrows, constant.NotifType_Overdue, now, s.BorrowRepo.MarkOverdueNoticeSent)
+}
+
+// notifyLoans send one notification per member and mark the loans as notified,
+// return the number of members notified. A member whose notification cannot be
+// queued is left unmarked so the next run tries again
+func (s *borrowService) notifyLoans(
+	c context.Context,
+	borrows []book.BorrowDTO,
+	notifType string,
+	now time.Time,
+	mark func(c context.Context, ids []uuid.UUID, sentAt time.Time) error,
+) (int, error) {
+	// borrows are ordered by user so loans of a member are adjacent
+	notified := 0
+	for start := 0; start < len(borrows); {
+		end := start
+		for end < len(borrows) && borrows[end].UserID == borrows[start].UserID {
+			end++
+		}
+		group := borrows[start:end]
+		start = end
+
+		userID := group[0].UserID
+		ids := make([]uuid.UUID, 0, len(group))
+		loans := make([]notification.DueLoan, 0, len(group))
+		for _, b := range group {
+			loan := notification.DueLoan{
+				BookID:  b.BookID,
+				Title:   b.BookTitle,
+				DueDate: b.DueDate,
+			}
+
+			// a started day count as a whole day late
+			if now.After(b.DueDate) {
+				loan.DaysOverdue = int(math.Ceil(now.Sub(b.DueDate).Hours() / 24))
+			}
+
+			ids = append(ids, b.ID)
+			loans = append(loans, loan)
+		}
+
+		if err := s.Notifier.Notify(c, userID, notifType, &notification.DueData{Loans: loans}); err != nil {
+			s.Logger.Errorw("Failed to notify loans", "type", notifType, "user_id", userID, "error", err)
+			continue
+		}
+
+		if err := mark(c, ids, now); err != nil {
+			return notified, exception.ErrorInternal("Failed to mark loans as notified")
+		}
+
+		notified++
+	}
+
+	return notified, nil
+}
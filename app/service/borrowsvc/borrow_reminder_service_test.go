@@ -0,0 +1,156 @@
+package borrowsvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/service/notificationsvc"
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/notification"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type notified struct {
+	userID    uuid.UUID
+	notifType string
+	data      *notification.DueData
+}
+
+// fakeNotifier record notifications, members in fail cannot be notified
+type fakeNotifier struct {
+	notificationsvc.NotificationService
+	fail map[uuid.UUID]bool
+	sent []notified
+}
+
+func (n *fakeNotifier) Notify(c context.Context, userID uuid.UUID, notifType string, data interface{}) error {
+	if n.fail[userID] {
+		return errors.New("mail server unavailable")
+	}
+
+	n.sent = append(n.sent, notified{userID: userID, notifType: notifType, data: data.(*notification.DueData)})
+	return nil
+}
+
+func newLoan(userID uuid.UUID, title string, due time.Time) book.BorrowDTO {
+	b := book.BorrowDTO{BookTitle: title}
+	b.ID = uuid.New()
+	b.UserID = userID
+	b.BookID = uuid.New()
+	b.DueDate = due
+	return b
+}
+
+func TestNotifyLoans(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	ann, bob, cat := uuid.New(), uuid.New(), uuid.New()
+
+	// loans are ordered by member
+	borrows := []book.BorrowDTO{
+		newLoan(ann, "Dune", now.Add(-time.Hour)),
+		newLoan(ann, "Emma", now.Add(-49*time.Hour)),
+		newLoan(bob, "Ulysses", now.Add(-24*time.Hour)),
+		newLoan(cat, "Beloved", now.Add(-72*time.Hour)),
+		newLoan(cat, "Middlemarch", now.Add(-time.Minute)),
+	}
+
+	notifier := &fakeNotifier{fail: map[uuid.UUID]bool{bob: true}}
+	s := &borrowService{Logger: zap.NewNop().Sugar(), Notifier: notifier}
+
+	marked := make(map[uuid.UUID]time.Time)
+	mark := func(c context.Context, ids []uuid.UUID, sentAt time.Time) error {
+		for _, id := range ids {
+			marked[id] = sentAt
+		}
+		return nil
+	}
+
+	count, err := s.notifyLoans(context.Background(), borrows, constant.NotifType_Overdue, now, mark)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 2 {
+		t.Fatalf("notified %d members, want 2", count)
+	}
+
+	// one notification per member holding every loan of that member
+	want := map[uuid.UUID][]notification.DueLoan{
+		ann: {
+			{BookID: borrows[0].BookID, Title: "Dune", DueDate: borrows[0].DueDate, DaysOverdue: 1},
+			{BookID: borrows[1].BookID, Title: "Emma", DueDate: borrows[1].DueDate, DaysOverdue: 3},
+		},
+		cat: {
+			{BookID: borrows[3].BookID, Title: "Beloved", DueDate: borrows[3].DueDate, DaysOverdue: 3},
+			{BookID: borrows[4].BookID, Title: "Middlemarch", DueDate: borrows[4].DueDate, DaysOverdue: 1},
+		},
+	}
+
+	if len(notifier.sent) != len(want) {
+		t.Fatalf("sent %d notifications, want %d", len(notifier.sent), len(want))
+	}
+
+	for _, n := range notifier.sent {
+		if n.notifType != constant.NotifType_Overdue {
+			t.Errorf("notification type = %s, want %s", n.notifType, constant.NotifType_Overdue)
+		}
+
+		loans, ok := want[n.userID]
+		if !ok || len(n.data.Loans) != len(loans) {
+			t.Fatalf("unexpected notification to %s: %+v", n.userID, n.data.Loans)
+		}
+
+		for i, loan := range loans {
+			if n.data.Loans[i] != loan {
+				t.Errorf("loan %d of %s = %+v, want %+v", i, n.userID, n.data.Loans[i], loan)
+			}
+		}
+		delete(want, n.userID)
+	}
+
+	// loans of the member whose notification failed are left for the next run
+	for _, b := range borrows {
+		sentAt, ok := marked[b.ID]
+		if b.UserID == bob {
+			if ok {
+				t.Errorf("loan %s was marked although its notification failed", b.BookTitle)
+			}
+			continue
+		}
+
+		if !ok || !sentAt.Equal(now) {
+			t.Errorf("loan %s marked = %v at %s, want marked at %s", b.BookTitle, ok, sentAt, now)
+		}
+	}
+}
+
+func TestNotifyLoansMarkFailure(t *testing.T) {
+	now := time.Now()
+	ann, bob := uuid.New(), uuid.New()
+
+	borrows := []book.BorrowDTO{
+		newLoan(ann, "Dune", now.Add(time.Hour)),
+		newLoan(bob, "Emma", now.Add(time.Hour)),
+	}
+
+	notifier := &fakeNotifier{}
+	s := &borrowService{Logger: zap.NewNop().Sugar(), Notifier: notifier}
+
+	mark := func(c context.Context, ids []uuid.UUID, sentAt time.Time) error {
+		return errors.New("connection reset")
+	}
+
+	count, err := s.notifyLoans(context.Background(), borrows, constant.NotifType_DueSoon, now, mark)
+	if err == nil || count != 0 {
+		t.Fatalf("notifyLoans = %d, %v, want an error", count, err)
+	}
+
+	// the run stop at the first failed mark
+	if len(notifier.sent) != 1 || notifier.sent[0].data.Loans[0].DaysOverdue != 0 {
+		t.Fatalf("sent = %+v", notifier.sent)
+	}
+}
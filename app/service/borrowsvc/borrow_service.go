@@ -1,18 +1,24 @@
 package borrowsvc
 
 import (
-	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/dikyayodihamzah/library-management-api/app/repository/bookrepo"
 	"github.com/dikyayodihamzah/library-management-api/app/repository/borrowrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/outboxrepo"
 	"github.com/dikyayodihamzah/library-management-api/app/repository/userrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/service/notificationsvc"
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
 	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exporter"
 	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
 	"github.com/dikyayodihamzah/library-management-api/pkg/model"
 	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/event"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/notification"
 	"github.com/dikyayodihamzah/library-management-api/pkg/query"
 	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
 	"github.com/go-playground/validator/v10"
@@ -25,8 +31,15 @@ type BorrowService interface {
 	Borrow(c context.Context, req *book.BorrowRequest) (*book.BorrowUserResponse, error)
 	Return(c context.Context, req *book.BorrowRequest) error
 	FindAll(c context.Context, filter *book.BorrowQuery) ([]book.BorrowResponse, int, error)
+	History(c context.Context, userID uuid.UUID, loc *time.Location) ([]book.BorrowHistory, error)
+	Availability(c context.Context, bookID uuid.UUID, days int, loc *time.Location) (*book.Availability, error)
+	LoanCalendar(c context.Context, userID uuid.UUID, w io.Writer) error
 
-	GenerateExcel(c context.Context, filter *book.BorrowQuery, timezone int) (*bytes.Buffer, error)
+	// SendDueReminders and SendOverdueNotices return the number of members notified
+	SendDueReminders(c context.Context) (int, error)
+	SendOverdueNotices(c context.Context) (int, error)
+
+	Export(c context.Context, filter *book.BorrowQuery, format exporter.Format, w io.Writer, progress ProgressFunc) error
 }
 
 type borrowService struct {
@@ -36,6 +49,8 @@ type borrowService struct {
 	UserRepo   userrepo.UserRepository
 	BookRepo   bookrepo.BookRepository
 	BorrowRepo borrowrepo.BorrowRepository
+	OutboxRepo outboxrepo.OutboxRepository
+	Notifier   notificationsvc.NotificationService
 }
 
 func New(
@@ -45,6 +60,8 @@ func New(
 	userRepo userrepo.UserRepository,
 	bookRepo bookrepo.BookRepository,
 	borrowRepo borrowrepo.BorrowRepository,
+	outboxRepo outboxrepo.OutboxRepository,
+	notifier notificationsvc.NotificationService,
 ) BorrowService {
 	return &borrowService{
 		Logger:     logger,
@@ -53,6 +70,8 @@ func New(
 		UserRepo:   userRepo,
 		BookRepo:   bookRepo,
 		BorrowRepo: borrowRepo,
+		OutboxRepo: outboxRepo,
+		Notifier:   notifier,
 	}
 }
 
@@ -145,7 +164,11 @@ func (s *borrowService) Borrow(c context.Context, req *book.BorrowRequest) (*boo
 			}
 		}
 
-		return s.BorrowRepo.Add(c, tx, borrowRecords...)
+		if err := s.BorrowRepo.Add(c, tx, borrowRecords...); err != nil {
+			return err
+		}
+
+		return s.addEvents(c, tx, constant.Event_BorrowCreated, borrowRecords...)
 	}); err != nil {
 		return nil, exception.ErrorInternal("Failed to borrow book")
 	}
@@ -171,6 +194,16 @@ func (s *borrowService) Borrow(c context.Context, req *book.BorrowRequest) (*boo
 	res.TotalPrice = totalPrice
 	res.Books = books
 
+	// the loan stands even when the confirmation cannot be queued
+	if err := s.Notifier.Notify(c, req.UserID, constant.NotifType_LoanConfirmation, &notification.LoanData{
+		Books:      books,
+		BorrowDate: res.BorrowDate,
+		DueDate:    res.DueDate,
+		TotalPrice: totalPrice,
+	}); err != nil {
+		s.Logger.Errorw("Failed to notify loan confirmation", "user_id", req.UserID, "error", err)
+	}
+
 	return res, nil
 }
 
@@ -242,14 +275,40 @@ func (s *borrowService) Return(c context.Context, req *book.BorrowRequest) error
 			}
 		}
 
-		return nil
+		return s.addEvents(c, tx, constant.Event_BorrowReturned, updatedBorrowRecords...)
 	}); err != nil {
 		return exception.ErrorInternal("Failed to return book")
 	}
 
+	books := make([]model.SimpleResponse, 0)
+	for _, b := range returnedBooks {
+		books = append(books, model.SimpleResponse{ID: b.ID, Name: b.Title})
+	}
+
+	if err := s.Notifier.Notify(c, req.UserID, constant.NotifType_ReturnReceipt, &notification.ReturnData{
+		Books:      books,
+		ReturnedAt: time.Now(),
+	}); err != nil {
+		s.Logger.Errorw("Failed to notify return receipt", "user_id", req.UserID, "error", err)
+	}
+
 	return nil
 }
 
+// addEvents write an event of every borrow record into the outbox
+func (s *borrowService) addEvents(c context.Context, tx pgx.Tx, eventType string, records ...book.BorrowRecord) error {
+	events := make([]event.Event, 0, len(records))
+	for i := range records {
+		e, err := event.New(eventType, records[i].ID, records[i].ToEvent())
+		if err != nil {
+			return err
+		}
+		events = append(events, e)
+	}
+
+	return s.OutboxRepo.Add(c, tx, events...)
+}
+
 func (s *borrowService) FindAll(c context.Context, filter *book.BorrowQuery) ([]book.BorrowResponse, int, error) {
 	// validate filter
 	if filter.Sort == "" {
@@ -266,9 +325,10 @@ func (s *borrowService) FindAll(c context.Context, filter *book.BorrowQuery) ([]
 		return nil, 0, exception.ErrorInternal("Failed to get borrow records")
 	}
 
+	loc := filter.Location()
 	res := make([]book.BorrowResponse, 0)
 	for _, dto := range dtos {
-		res = append(res, *dto.ToResponse())
+		res = append(res, *dto.ToResponse().In(loc))
 	}
 
 	// get total borrow records data
@@ -279,3 +339,26 @@ func (s *borrowService) FindAll(c context.Context, filter *book.BorrowQuery) ([]
 
 	return res, total, nil
 }
+
+func (s *borrowService) History(c context.Context, userID uuid.UUID, loc *time.Location) ([]book.BorrowHistory, error) {
+	// get yearly aggregates, years follow the zone of the member
+	histories, err := s.BorrowRepo.History(c, userID, loc.String())
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get borrow history")
+	}
+
+	// get favourite genres per year
+	genres, err := s.BorrowRepo.FavouriteGenres(c, userID, loc.String(), 3)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get favourite genres")
+	}
+
+	for i := range histories {
+		histories[i].FavouriteGenres = genres[histories[i].Year]
+		if histories[i].FavouriteGenres == nil {
+			histories[i].FavouriteGenres = make([]book.GenreCount, 0)
+		}
+	}
+
+	return histories, nil
+}
@@ -0,0 +1,126 @@
+package eventsvc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/outboxrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+	"go.uber.org/zap"
+)
+
+var (
+	pollInterval = time.Duration(utils.GetInt("OUTBOX_POLL_MILLISECONDS", 1000)) * time.Millisecond
+	retention    = time.Duration(utils.GetInt("OUTBOX_RETENTION_HOURS", 168)) * time.Hour
+
+	relayBatch = 100
+)
+
+type EventService interface {
+	// Run relay outbox events to the publisher until c is done
+	Run(c context.Context)
+	// Cleanup remove published events past the retention period, return the number removed
+	Cleanup(c context.Context) (int, error)
+}
+
+type eventService struct {
+	Logger     *zap.SugaredLogger
+	OutboxRepo outboxrepo.OutboxRepository
+	Publisher  lib.Publisher
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	outboxRepo outboxrepo.OutboxRepository,
+	publisher lib.Publisher,
+) EventService {
+	return &eventService{
+		Logger:     logger,
+		OutboxRepo: outboxRepo,
+		Publisher:  publisher,
+	}
+}
+
+func (s *eventService) Run(c context.Context) {
+	defer lib.Recover()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.relay(c); err != nil {
+			s.Logger.Errorw("failed to relay events", "error", err)
+		}
+
+		select {
+		case <-c.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay publish pending events in outbox order while holding the relay lock, so
+// only one replica publish at a time. A batch is marked published only after the
+// broker acknowledged it, a failure in between publish the batch again next time
+func (s *eventService) relay(c context.Context) error {
+	unlock, ok, err := s.OutboxRepo.TryLock(c)
+	if err != nil || !ok {
+		return err
+	}
+	defer unlock()
+
+	for {
+		events, err := s.OutboxRepo.FindUnpublished(c, relayBatch)
+		if err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		msgs := make([]lib.Message, 0, len(events))
+		seqs := make([]int64, 0, len(events))
+		for _, e := range events {
+			value, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+
+			msgs = append(msgs, lib.Message{
+				Key:   e.AggregateID.String(),
+				Value: value,
+				Headers: map[string]string{
+					"event-id":   e.ID.String(),
+					"event-type": e.Type,
+				},
+			})
+			seqs = append(seqs, e.Seq)
+		}
+
+		if err := s.Publisher.Publish(c, msgs...); err != nil {
+			return err
+		}
+
+		if err := s.OutboxRepo.MarkPublished(c, seqs, time.Now()); err != nil {
+			return err
+		}
+
+		if len(events) < relayBatch {
+			return nil
+		}
+	}
+}
+
+func (s *eventService) Cleanup(c context.Context) (int, error) {
+	removed, err := s.OutboxRepo.DeletePublished(c, time.Now().Add(-retention))
+	if err != nil {
+		return 0, exception.ErrorInternal("Failed to remove published events")
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,220 @@
+package eventsvc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/outboxrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/event"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeOutboxRepo keep events in memory, it check on every MarkPublished that the
+// events have already reached the publisher
+type fakeOutboxRepo struct {
+	outboxrepo.OutboxRepository
+	t         *testing.T
+	publisher *lib.MemoryPublisher
+
+	mu      sync.Mutex
+	events  []event.Event
+	markErr error
+	marks   [][]int64
+}
+
+func (r *fakeOutboxRepo) TryLock(c context.Context) (func(), bool, error) {
+	return func() {}, true, nil
+}
+
+func (r *fakeOutboxRepo) FindUnpublished(c context.Context, limit int) ([]event.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]event.Event, 0)
+	for _, e := range r.events {
+		if e.PublishedAt == nil {
+			events = append(events, e)
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Seq < events[j].Seq })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+
+	return events, nil
+}
+
+func (r *fakeOutboxRepo) MarkPublished(c context.Context, seqs []int64, publishedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	published := make(map[string]bool)
+	for _, msg := range r.publisher.Messages() {
+		published[msg.Headers["event-id"]] = true
+	}
+
+	for _, seq := range seqs {
+		for _, e := range r.events {
+			if e.Seq == seq && !published[e.ID.String()] {
+				r.t.Errorf("event %d marked published before it was published", seq)
+			}
+		}
+	}
+
+	if r.markErr != nil {
+		return r.markErr
+	}
+
+	r.marks = append(r.marks, seqs)
+	for i := range r.events {
+		for _, seq := range seqs {
+			if r.events[i].Seq == seq {
+				r.events[i].PublishedAt = &publishedAt
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeOutboxRepo) unpublished() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for _, e := range r.events {
+		if e.PublishedAt == nil {
+			n++
+		}
+	}
+
+	return n
+}
+
+// newTestService return a service over events whose seq are added out of order
+func newTestService(t *testing.T, count int) (*eventService, *fakeOutboxRepo, *lib.MemoryPublisher) {
+	publisher := &lib.MemoryPublisher{}
+	repo := &fakeOutboxRepo{t: t, publisher: publisher}
+
+	for i := count; i > 0; i-- {
+		e, err := event.New(constant.Event_BookUpdated, uuid.New(), map[string]int{"n": i})
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Seq = int64(i)
+		repo.events = append(repo.events, e)
+	}
+
+	return &eventService{
+		Logger:     zap.NewNop().Sugar(),
+		OutboxRepo: repo,
+		Publisher:  publisher,
+	}, repo, publisher
+}
+
+func assertSeqOrder(t *testing.T, repo *fakeOutboxRepo, msgs []lib.Message) {
+	t.Helper()
+
+	seqs := make(map[string]int64)
+	for _, e := range repo.events {
+		seqs[e.ID.String()] = e.Seq
+	}
+
+	for i, msg := range msgs {
+		if got := seqs[msg.Headers["event-id"]]; got != int64(i+1) {
+			t.Fatalf("message %d has seq %d, want %d", i, got, i+1)
+		}
+	}
+}
+
+func TestRelayPublishInSeqOrder(t *testing.T) {
+	defer func(batch int) { relayBatch = batch }(relayBatch)
+	relayBatch = 2
+
+	s, repo, publisher := newTestService(t, 5)
+	if err := s.relay(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := publisher.Messages()
+	if len(msgs) != 5 {
+		t.Fatalf("published %d messages, want 5", len(msgs))
+	}
+	assertSeqOrder(t, repo, msgs)
+
+	// every batch is marked after its own publish
+	if len(repo.marks) != 3 || repo.unpublished() != 0 {
+		t.Fatalf("marks = %v, unpublished = %d", repo.marks, repo.unpublished())
+	}
+
+	for _, e := range repo.events {
+		if msg := msgs[e.Seq-1]; msg.Key != e.AggregateID.String() || msg.Headers["event-type"] != e.Type {
+			t.Fatalf("message %d = key %s, headers %v", e.Seq, msg.Key, msg.Headers)
+		}
+
+		var value event.Event
+		if err := json.Unmarshal(msgs[e.Seq-1].Value, &value); err != nil || value.ID != e.ID || string(value.Payload) != string(e.Payload) {
+			t.Fatalf("message %d value = %s", e.Seq, msgs[e.Seq-1].Value)
+		}
+	}
+
+	// nothing left, a new run publish nothing
+	if err := s.relay(context.Background()); err != nil || len(publisher.Messages()) != 5 {
+		t.Fatalf("second run = %v, %d messages", err, len(publisher.Messages()))
+	}
+}
+
+func TestRelayResendAfterPublishFailure(t *testing.T) {
+	s, repo, publisher := newTestService(t, 3)
+
+	publisher.Err = errors.New("broker unavailable")
+	if err := s.relay(context.Background()); !errors.Is(err, publisher.Err) {
+		t.Fatalf("relay error = %v, want the publish error", err)
+	}
+
+	if len(repo.marks) != 0 || repo.unpublished() != 3 {
+		t.Fatalf("failed publish must leave events unpublished, marks = %v, unpublished = %d", repo.marks, repo.unpublished())
+	}
+
+	publisher.Err = nil
+	if err := s.relay(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := publisher.Messages()
+	if len(msgs) != 3 || repo.unpublished() != 0 {
+		t.Fatalf("published %d messages, unpublished = %d", len(msgs), repo.unpublished())
+	}
+	assertSeqOrder(t, repo, msgs)
+}
+
+func TestRelayResendAfterMarkFailure(t *testing.T) {
+	s, repo, publisher := newTestService(t, 2)
+
+	// the broker got the events but they could not be marked, they are sent again
+	repo.markErr = errors.New("connection reset")
+	if err := s.relay(context.Background()); !errors.Is(err, repo.markErr) {
+		t.Fatalf("relay error = %v, want the mark error", err)
+	}
+
+	repo.markErr = nil
+	if err := s.relay(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := publisher.Messages()
+	if len(msgs) != 4 || repo.unpublished() != 0 {
+		t.Fatalf("published %d messages, unpublished = %d", len(msgs), repo.unpublished())
+	}
+	assertSeqOrder(t, repo, msgs[:2])
+	assertSeqOrder(t, repo, msgs[2:])
+}
@@ -0,0 +1,266 @@
+package exportsvc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/exportrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/service/borrowsvc"
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exporter"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/export"
+	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var (
+	exportDir = utils.GetString("EXPORT_DIR", filepath.Join(os.TempDir(), "library-exports"))
+	exportTTL = time.Duration(utils.GetInt("EXPORT_TTL_HOURS", 24)) * time.Hour
+)
+
+type ExportService interface {
+	Create(c context.Context, userID uuid.UUID, filter *book.BorrowQuery, format string) (*export.JobResponse, error)
+	FindByID(c context.Context, id, userID uuid.UUID, isAdmin bool) (*export.JobResponse, error)
+	Download(c context.Context, id, userID uuid.UUID, isAdmin bool) (string, error)
+
+	Resume(c context.Context) error
+	// CleanupExpired return the number of export files removed
+	CleanupExpired(c context.Context) (int, error)
+}
+
+type exportService struct {
+	Logger        *zap.SugaredLogger
+	TxManager     transaction.Manager
+	ExportRepo    exportrepo.ExportRepository
+	BorrowService borrowsvc.BorrowService
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	txManager transaction.Manager,
+	exportRepo exportrepo.ExportRepository,
+	borrowService borrowsvc.BorrowService,
+) ExportService {
+	return &exportService{
+		Logger:        logger,
+		TxManager:     txManager,
+		ExportRepo:    exportRepo,
+		BorrowService: borrowService,
+	}
+}
+
+func (s *exportService) Create(c context.Context, userID uuid.UUID, filter *book.BorrowQuery, format string) (*export.JobResponse, error) {
+	// validate format
+	f, err := exporter.Lookup(format)
+	if err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	// create new export job data
+	job := export.Job{
+		UserID:   userID,
+		Status:   constant.ExportStatus_Pending,
+		Format:   f.Name,
+		Filter:   *filter,
+		Timezone: filter.Location().String(),
+	}
+	job.ID = uuid.New()
+	job.CreatedAt = lib.Pointer(time.Now())
+	job.FileName = "borrows-" + job.CreatedAt.Format("20060102150405") + "-" + job.ID.String()[:8] + "." + f.Extension
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.ExportRepo.Create(c, tx, &job)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to create export job")
+	}
+
+	go s.run(job)
+
+	return job.ToResponse(), nil
+}
+
+func (s *exportService) findOwned(c context.Context, id, userID uuid.UUID, isAdmin bool) (*export.Job, error) {
+	job, err := s.ExportRepo.FindByID(c, id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("Export job not found")
+	}
+
+	if !isAdmin && job.UserID != userID {
+		return nil, exception.ErrorNotFound("Export job not found")
+	}
+
+	return job, nil
+}
+
+func (s *exportService) FindByID(c context.Context, id, userID uuid.UUID, isAdmin bool) (*export.JobResponse, error) {
+	job, err := s.findOwned(c, id, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	return job.ToResponse(), nil
+}
+
+func (s *exportService) Download(c context.Context, id, userID uuid.UUID, isAdmin bool) (string, error) {
+	job, err := s.findOwned(c, id, userID, isAdmin)
+	if err != nil {
+		return "", err
+	}
+
+	if job.Status == constant.ExportStatus_Expired || job.IsExpired() {
+		return "", exception.ErrorNotFound("Export file has expired")
+	}
+
+	if job.Status != constant.ExportStatus_Finished {
+		return "", exception.ErrorBadRequest("Export job is not finished yet")
+	}
+
+	// file cache only knows jobs finished by this process, fall back to disk after restart
+	path := filepath.Join(exportDir, job.FileName)
+	if finished, exists := lib.FileCache.IsExists(job.FileName); exists && !finished {
+		return "", exception.ErrorBadRequest("Export job is not finished yet")
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return "", exception.ErrorNotFound("Export file not found")
+	}
+
+	return path, nil
+}
+
+// Resume restart export jobs that were left unfinished, e.g. by a restart,
+// jobs still running on another replica are skipped by the job lock
+func (s *exportService) Resume(c context.Context) error {
+	jobs, err := s.ExportRepo.FindByStatus(c, constant.ExportStatusUnfinished...)
+	if err != nil {
+		return exception.ErrorInternal("Failed to get unfinished export jobs")
+	}
+
+	for _, job := range jobs {
+		if _, exists := lib.FileCache.IsExists(job.FileName); exists {
+			continue
+		}
+
+		s.Logger.Infow("resuming export job", "id", job.ID)
+		go s.run(job)
+	}
+
+	return nil
+}
+
+// CleanupExpired remove finished export files which are past their expiry
+func (s *exportService) CleanupExpired(c context.Context) (int, error) {
+	jobs, err := s.ExportRepo.FindExpired(c, time.Now())
+	if err != nil {
+		return 0, exception.ErrorInternal("Failed to get expired export jobs")
+	}
+
+	removed := 0
+	for _, job := range jobs {
+		if err := os.Remove(filepath.Join(exportDir, job.FileName)); err != nil && !os.IsNotExist(err) {
+			s.Logger.Errorw("failed to remove export file", "id", job.ID, "error", err)
+			continue
+		}
+		lib.FileCache.DelFinished(job.FileName)
+
+		job.Status = constant.ExportStatus_Expired
+		job.UpdatedAt = lib.TimeNowPtr()
+		if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+			return s.ExportRepo.Update(c, tx, &job)
+		}); err != nil {
+			s.Logger.Errorw("failed to expire export job", "id", job.ID, "error", err)
+			continue
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+func (s *exportService) run(job export.Job) {
+	defer lib.Recover()
+
+	c := context.Background()
+
+	// only one replica write a job file, e.g. during a rolling deploy a job running on
+	// a live replica is also resumed by the starting one
+	unlock, ok, err := s.ExportRepo.TryLock(c, job.ID)
+	if err != nil || !ok {
+		return
+	}
+	defer unlock()
+
+	// the previous holder may have finished the job since it was read
+	current, err := s.ExportRepo.FindByID(c, job.ID)
+	if err != nil || !slices.Contains(constant.ExportStatusUnfinished, current.Status) {
+		return
+	}
+
+	lib.FileCache.SetProcessing(job.FileName)
+
+	job.Status = constant.ExportStatus_Processing
+	job.Progress = 0
+	job.UpdatedAt = lib.TimeNowPtr()
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.ExportRepo.Update(c, tx, &job)
+	}); err != nil {
+		s.Logger.Errorw("failed to start export job", "id", job.ID, "error", err)
+		lib.FileCache.DelFinished(job.FileName)
+		return
+	}
+
+	if err := s.write(c, &job); err != nil {
+		s.Logger.Errorw("export job failed", "id", job.ID, "error", err)
+		lib.FileCache.DelFinished(job.FileName)
+		os.Remove(filepath.Join(exportDir, job.FileName))
+
+		job.Status = constant.ExportStatus_Failed
+		job.Error = lib.Strptr(err.Error())
+	} else {
+		lib.FileCache.SetFinished(job.FileName)
+
+		job.Status = constant.ExportStatus_Finished
+		job.ExpiresAt = lib.Pointer(time.Now().Add(exportTTL))
+	}
+
+	job.UpdatedAt = lib.TimeNowPtr()
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.ExportRepo.Update(c, tx, &job)
+	}); err != nil {
+		s.Logger.Errorw("failed to finish export job", "id", job.ID, "error", err)
+	}
+}
+
+func (s *exportService) write(c context.Context, job *export.Job) error {
+	format, err := exporter.Lookup(job.Format)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(exportDir, 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(exportDir, job.FileName))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return s.BorrowService.Export(c, &job.Filter, format, file, func(done, total int) {
+		job.Progress = done
+		job.Total = total
+		if err := s.ExportRepo.UpdateProgress(c, job.ID, done, total); err != nil {
+			s.Logger.Errorw("failed to update export progress", "id", job.ID, "error", err)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package feedsvc
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/bookrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/citation"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/feed"
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+	"go.uber.org/zap"
+)
+
+var (
+	feedTitle = utils.GetString("APP_NAME", "Library") + " - New Arrivals"
+
+	// number of newest books in the feed
+	feedSize = utils.GetInt("FEED_SIZE", 50)
+)
+
+type FeedService interface {
+	// NewArrivals build an Atom feed of the newest books, selfURL is the address the feed was requested at
+	NewArrivals(c context.Context, filter *book.BookQuery, selfURL string) (*feed.Feed, time.Time, error)
+}
+
+type feedService struct {
+	Logger   *zap.SugaredLogger
+	BookRepo bookrepo.BookRepository
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	bookRepo bookrepo.BookRepository,
+) FeedService {
+	return &feedService{
+		Logger:   logger,
+		BookRepo: bookRepo,
+	}
+}
+
+// NewArrivals also return the creation time of the newest book, zero when the feed is empty
+func (s *feedService) NewArrivals(c context.Context, filter *book.BookQuery, selfURL string) (*feed.Feed, time.Time, error) {
+	// only genre and author filters are honoured, the feed is always newest first
+	filter.Search = ""
+	filter.Sort = "-created_at"
+	filter.Page = 1
+	filter.Limit = feedSize
+
+	books, err := s.BookRepo.FindAll(c, filter)
+	if err != nil {
+		return nil, time.Time{}, exception.ErrorInternal("Failed to get books")
+	}
+
+	var updated time.Time
+	entries := make([]feed.Entry, 0)
+	for _, b := range books {
+		if b.CreatedAt != nil && b.CreatedAt.After(updated) {
+			updated = *b.CreatedAt
+		}
+
+		entries = append(entries, newEntry(&b))
+	}
+
+	title := feedTitle
+	if filter.Genre != "" {
+		title += " in " + filter.Genre
+	}
+	if filter.Author != "" {
+		title += " by " + filter.Author
+	}
+
+	// an empty feed still needs a timestamp
+	feedUpdated := updated
+	if feedUpdated.IsZero() {
+		feedUpdated = time.Unix(0, 0)
+	}
+
+	return &feed.Feed{
+		Xmlns:   feed.Namespace,
+		ID:      selfURL,
+		Title:   title,
+		Updated: feedUpdated.UTC().Format(feed.DateLayout),
+		Author:  &feed.Person{Name: utils.GetString("APP_NAME", "Library")},
+		Links: []feed.Link{
+			{Rel: "self", Type: feed.ContentType, Href: selfURL},
+		},
+		Entries: entries,
+	}, updated, nil
+}
+
+func newEntry(b *book.Book) feed.Entry {
+	var created string
+	if b.CreatedAt != nil {
+		created = b.CreatedAt.UTC().Format(feed.DateLayout)
+	}
+
+	entry := feed.Entry{
+		ID:        fmt.Sprintf("urn:uuid:%s", b.ID),
+		Title:     b.Title,
+		Updated:   created,
+		Published: created,
+		Links: []feed.Link{
+			{Rel: "alternate", Href: lib.PublicURL("books", b.ID.String())},
+		},
+		Summary: &feed.Text{Type: "text", Body: b.Summary},
+	}
+
+	for _, author := range citation.SplitAuthors(b.Author) {
+		entry.Authors = append(entry.Authors, feed.Person{Name: author})
+	}
+
+	if b.Genre != "" {
+		entry.Category = append(entry.Category, feed.Category{Term: b.Genre})
+	}
+
+	// readers show the cover from the html content, the enclosure keeps it machine readable
+	content := "<p>" + html.EscapeString(b.Summary) + "</p>"
+	if cover := lib.AssignMinioPrefix(b.CoverURL); cover != "" {
+		entry.Links = append(entry.Links, feed.Link{Rel: "enclosure", Type: "image/*", Href: cover})
+		content = fmt.Sprintf(`<img src="%s" alt="%s"/>`, html.EscapeString(cover), html.EscapeString(b.Title)) + content
+	}
+	entry.Content = &feed.Text{Type: "html", Body: content}
+
+	return entry
+}
@@ -0,0 +1,181 @@
+package locationsvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/locationrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/location"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type LocationService interface {
+	Create(c context.Context, req *location.LocationRequest) (*location.Location, error)
+	FindAll(c context.Context, filter *location.LocationQuery) ([]location.Location, int, error)
+	FindByID(c context.Context, id uuid.UUID) (*location.LocationDetail, error)
+	Update(c context.Context, id uuid.UUID, req *location.LocationRequest) (*location.Location, error)
+	Delete(c context.Context, id uuid.UUID) error
+}
+
+type locationService struct {
+	Validate     *validator.Validate
+	TxManager    transaction.Manager
+	LocationRepo locationrepo.LocationRepository
+}
+
+func New(
+	validate *validator.Validate,
+	txManager transaction.Manager,
+	locationRepo locationrepo.LocationRepository,
+) LocationService {
+	return &locationService{
+		Validate:     validate,
+		TxManager:    txManager,
+		LocationRepo: locationRepo,
+	}
+}
+
+func (s *locationService) Create(c context.Context, req *location.LocationRequest) (*location.Location, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	// floors stand alone, sections are placed in a floor and shelves in a section
+	parentType := constant.LocationParentType[req.Type]
+	if parentType == "" && req.ParentID != nil {
+		return nil, exception.ErrorBadRequest("Floor cannot have a parent")
+	}
+
+	if parentType != "" {
+		if req.ParentID == nil {
+			return nil, exception.ErrorBadRequest("parent_id is required")
+		}
+
+		parent, err := s.LocationRepo.FindByID(c, *req.ParentID)
+		if err != nil {
+			return nil, exception.ErrorBadRequest("Parent location not found")
+		}
+
+		if parent.Type != parentType {
+			return nil, exception.ErrorBadRequest("Parent of " + req.Type + " must be a " + parentType)
+		}
+	}
+
+	// create new location data
+	l := location.Location{LocationRequest: *req}
+	l.ID = uuid.New()
+	l.CreatedAt = lib.Pointer(time.Now())
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.LocationRepo.Create(c, tx, &l)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to create location")
+	}
+
+	return &l, nil
+}
+
+func (s *locationService) FindAll(c context.Context, filter *location.LocationQuery) ([]location.Location, int, error) {
+	// validate filter
+	if filter.Sort == "" {
+		filter.Sort = "code"
+	}
+
+	if _, _, err := query.ValidateSort(filter.Sort, locationrepo.SortLocationMap); err != nil {
+		return nil, 0, exception.ErrorBadRequest(err.Error())
+	}
+
+	locations, err := s.LocationRepo.FindAll(c, filter)
+	if err != nil {
+		return nil, 0, exception.ErrorInternal("Failed to get locations")
+	}
+
+	total, err := s.LocationRepo.Count(c, filter)
+	if err != nil {
+		return nil, 0, exception.ErrorInternal("Failed to get total locations")
+	}
+
+	return locations, total, nil
+}
+
+func (s *locationService) FindByID(c context.Context, id uuid.UUID) (*location.LocationDetail, error) {
+	l, err := s.LocationRepo.FindByID(c, id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("Location not found")
+	}
+
+	path, err := s.LocationRepo.FindPath(c, id)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get location path")
+	}
+
+	children, err := s.LocationRepo.FindAll(c, &location.LocationQuery{QueryParam: model.QueryParam{Sort: "code"}, ParentID: id})
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get child locations")
+	}
+
+	return &location.LocationDetail{
+		Location: *l,
+		Path:     path,
+		Children: children,
+	}, nil
+}
+
+// Update change code and name, type and parent are fixed once created
+func (s *locationService) Update(c context.Context, id uuid.UUID, req *location.LocationRequest) (*location.Location, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	l, err := s.LocationRepo.FindByID(c, id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("Location not found")
+	}
+
+	// update location data
+	l.Code = req.Code
+	l.Name = req.Name
+	l.UpdatedAt = lib.Pointer(time.Now())
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.LocationRepo.Update(c, tx, l)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to update location")
+	}
+
+	return l, nil
+}
+
+// Delete remove location without children, books on a removed shelf lose their location
+func (s *locationService) Delete(c context.Context, id uuid.UUID) error {
+	if _, err := s.LocationRepo.FindByID(c, id); err != nil {
+		return exception.ErrorNotFound("Location not found")
+	}
+
+	total, err := s.LocationRepo.Count(c, &location.LocationQuery{ParentID: id})
+	if err != nil {
+		return exception.ErrorInternal("Failed to get child locations")
+	}
+
+	if total > 0 {
+		return exception.ErrorConflict("Location still has child locations")
+	}
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.LocationRepo.Delete(c, tx, id)
+	}); err != nil {
+		return exception.ErrorInternal("Failed to delete location")
+	}
+
+	return nil
+}
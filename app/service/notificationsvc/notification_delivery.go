@@ -0,0 +1,96 @@
+package notificationsvc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/mail"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/notification"
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+)
+
+var (
+	maxAttempts  = utils.GetInt("NOTIFICATION_MAX_ATTEMPTS", 5)
+	retryBackoff = time.Duration(utils.GetInt("NOTIFICATION_RETRY_SECONDS", 60)) * time.Second
+	pollInterval = time.Duration(utils.GetInt("NOTIFICATION_POLL_SECONDS", 30)) * time.Second
+
+	maxBackoff = 24 * time.Hour
+
+	// a claimed notification is hidden from other workers this long
+	claimLease = 5 * time.Minute
+	claimBatch = 50
+)
+
+func (s *notificationService) Run(c context.Context) {
+	defer lib.Recover()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		notifications, err := s.NotificationRepo.ClaimDue(c, claimBatch, claimLease)
+		if err != nil {
+			s.Logger.Errorw("failed to claim notifications", "error", err)
+		}
+
+		for _, n := range notifications {
+			s.deliver(c, n)
+		}
+
+		select {
+		case <-c.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver attempt to send a notification once and record the outcome,
+// failures are retried with exponential backoff until attempts are exhausted
+func (s *notificationService) deliver(c context.Context, n notification.Notification) {
+	defer lib.Recover()
+
+	n.Attempts++
+	err := s.send(c, &n)
+
+	now := time.Now()
+	switch {
+	case err == nil:
+		n.Status = constant.NotifStatus_Sent
+		n.SentAt = &now
+		n.LastError = nil
+		n.NextAttemptAt = nil
+	case n.Attempts >= maxAttempts:
+		n.Status = constant.NotifStatus_Failed
+		n.LastError = lib.Pointer(err.Error())
+		n.NextAttemptAt = nil
+	default:
+		n.LastError = lib.Pointer(err.Error())
+		n.NextAttemptAt = lib.Pointer(now.Add(min(retryBackoff<<(n.Attempts-1), maxBackoff)))
+	}
+
+	if err != nil {
+		s.Logger.Warnw("failed to send notification", "id", n.ID, "attempts", n.Attempts, "error", err)
+	}
+
+	if err := s.NotificationRepo.UpdateDelivery(c, &n); err != nil {
+		s.Logger.Errorw("failed to save notification delivery", "id", n.ID, "error", err)
+	}
+}
+
+func (s *notificationService) send(c context.Context, n *notification.Notification) error {
+	switch n.Channel {
+	case constant.NotifChannel_Email:
+		return s.Sender.Send(c, &mail.Message{
+			To:      n.Recipient,
+			Subject: n.Subject,
+			Text:    n.BodyText,
+			HTML:    n.BodyHTML,
+		})
+	}
+
+	return fmt.Errorf("unknown notification channel %s", n.Channel)
+}
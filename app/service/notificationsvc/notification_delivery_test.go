@@ -0,0 +1,303 @@
+package notificationsvc
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/notificationrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/userrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/mail"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/notification"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/user"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// smtpServer is a minimal in-process SMTP server, it rejects messages while reject is set
+type smtpServer struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	reject   bool
+	received []string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &smtpServer{listener: l}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"), strings.HasPrefix(cmd, "RSET"), strings.HasPrefix(cmd, "NOOP"):
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+
+			var data strings.Builder
+			for {
+				l, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+
+			s.mu.Lock()
+			reject := s.reject
+			if !reject {
+				s.received = append(s.received, data.String())
+			}
+			s.mu.Unlock()
+
+			if reject {
+				reply("554 Transaction failed")
+			} else {
+				reply("250 Queued")
+			}
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (s *smtpServer) setReject(reject bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reject = reject
+}
+
+func (s *smtpServer) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.received...)
+}
+
+func (s *smtpServer) sender() *mail.SMTPSender {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return mail.NewSMTPSender(mail.SMTPConfig{
+		Host:    addr.IP.String(),
+		Port:    addr.Port,
+		From:    "library@example.com",
+		Timeout: 5 * time.Second,
+	})
+}
+
+type fakeTxManager struct{}
+
+func (fakeTxManager) WithTx(c context.Context, callback func(tx pgx.Tx) error) error {
+	return callback(nil)
+}
+
+type fakeUserRepo struct {
+	userrepo.UserRepository
+	user *user.User
+}
+
+func (r *fakeUserRepo) FindByColumn(c context.Context, column string, value interface{}) (*user.User, error) {
+	return r.user, nil
+}
+
+// fakeNotificationRepo keep created notifications and publish every saved delivery
+type fakeNotificationRepo struct {
+	notificationrepo.NotificationRepository
+
+	mu         sync.Mutex
+	created    []notification.Notification
+	deliveries chan notification.Notification
+}
+
+func (r *fakeNotificationRepo) Create(c context.Context, tx pgx.Tx, n *notification.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created = append(r.created, *n)
+	return nil
+}
+
+func (r *fakeNotificationRepo) IsEnabled(c context.Context, userID uuid.UUID, notifType, channel string) (bool, error) {
+	return true, nil
+}
+
+func (r *fakeNotificationRepo) UpdateDelivery(c context.Context, n *notification.Notification) error {
+	r.deliveries <- *n
+	return nil
+}
+
+func newTestService(t *testing.T, sender mail.Sender) (*notificationService, *fakeNotificationRepo) {
+	u := &user.User{}
+	u.ID = uuid.New()
+	u.Name = "Ann"
+	u.Email = "ann@example.com"
+
+	repo := &fakeNotificationRepo{deliveries: make(chan notification.Notification, 10)}
+	return &notificationService{
+		Logger:           zap.NewNop().Sugar(),
+		TxManager:        fakeTxManager{},
+		UserRepo:         &fakeUserRepo{user: u},
+		NotificationRepo: repo,
+		Sender:           sender,
+	}, repo
+}
+
+func waitDelivery(t *testing.T, repo *fakeNotificationRepo) notification.Notification {
+	t.Helper()
+
+	select {
+	case n := <-repo.deliveries:
+		return n
+	case <-time.After(10 * time.Second):
+		t.Fatal("notification was not delivered")
+		return notification.Notification{}
+	}
+}
+
+func TestNotifyDeliverOverSMTP(t *testing.T) {
+	server := newSMTPServer(t)
+	s, repo := newTestService(t, server.sender())
+
+	bookID := uuid.New()
+	if err := s.Notify(context.Background(), uuid.New(), constant.NotifType_LoanConfirmation, &notification.LoanData{
+		Books:      []model.SimpleResponse{{ID: bookID, Name: "Dune"}},
+		BorrowDate: time.Now(),
+		DueDate:    time.Now().AddDate(0, 0, 14),
+		TotalPrice: 15000,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	n := waitDelivery(t, repo)
+	if n.Status != constant.NotifStatus_Sent || n.Attempts != 1 || n.SentAt == nil || n.NextAttemptAt != nil {
+		t.Fatalf("delivery = status %s, attempts %d, sent at %v, next attempt %v", n.Status, n.Attempts, n.SentAt, n.NextAttemptAt)
+	}
+
+	msgs := server.messages()
+	if len(msgs) != 1 {
+		t.Fatalf("received %d messages, want 1", len(msgs))
+	}
+
+	for _, want := range []string{
+		"To: <ann@example.com>",
+		"Subject: Loan confirmation, due",
+		"Content-Type: multipart/alternative",
+		"Content-Type: text/plain",
+		"Content-Type: text/html",
+		"Dune",
+		"15.000",
+	} {
+		if !strings.Contains(msgs[0], want) {
+			t.Errorf("message must contain %q:\n%s", want, msgs[0])
+		}
+	}
+}
+
+func TestDeliverRetryWithBackoff(t *testing.T) {
+	server := newSMTPServer(t)
+	server.setReject(true)
+	s, repo := newTestService(t, server.sender())
+
+	n := notification.Notification{
+		UserID:    uuid.New(),
+		Type:      constant.NotifType_ReturnReceipt,
+		Channel:   constant.NotifChannel_Email,
+		Recipient: "ann@example.com",
+		Subject:   "Return receipt",
+		BodyText:  "Thank you",
+		BodyHTML:  "<p>Thank you</p>",
+		Status:    constant.NotifStatus_Pending,
+	}
+	n.ID = uuid.New()
+
+	// every rejected attempt is retried later, the delay doubling each time
+	for attempt := 1; attempt < maxAttempts; attempt++ {
+		before := time.Now()
+		s.deliver(context.Background(), n)
+		n = waitDelivery(t, repo)
+
+		if n.Status != constant.NotifStatus_Pending || n.Attempts != attempt {
+			t.Fatalf("attempt %d = status %s, attempts %d", attempt, n.Status, n.Attempts)
+		}
+
+		if n.LastError == nil || !strings.Contains(*n.LastError, "554") {
+			t.Fatalf("attempt %d last error = %v, want the SMTP rejection", attempt, n.LastError)
+		}
+
+		backoff := min(retryBackoff<<(attempt-1), maxBackoff)
+		if n.NextAttemptAt == nil || n.NextAttemptAt.Before(before.Add(backoff)) || n.NextAttemptAt.After(time.Now().Add(backoff)) {
+			t.Fatalf("attempt %d next attempt = %v, want %s from now", attempt, n.NextAttemptAt, backoff)
+		}
+	}
+
+	// the server accept again, the next attempt succeed
+	server.setReject(false)
+	retried := n
+	s.deliver(context.Background(), retried)
+	if sent := waitDelivery(t, repo); sent.Status != constant.NotifStatus_Sent || sent.LastError != nil {
+		t.Fatalf("retry = status %s, last error %v", sent.Status, sent.LastError)
+	}
+
+	if got := len(server.messages()); got != 1 {
+		t.Fatalf("received %d messages, want 1", got)
+	}
+
+	// a rejected last attempt give up
+	server.setReject(true)
+	s.deliver(context.Background(), n)
+	if failed := waitDelivery(t, repo); failed.Status != constant.NotifStatus_Failed || failed.Attempts != maxAttempts || failed.NextAttemptAt != nil {
+		t.Fatalf("last attempt = status %s, attempts %d, next attempt %v", failed.Status, failed.Attempts, failed.NextAttemptAt)
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	for amount, want := range map[int]string{0: "0", 999: "999", 15000: "15.000", -1234567: "-1.234.567"} {
+		if got := formatMoney(amount); got != want {
+			t.Errorf("formatMoney(%s) = %q, want %q", strconv.Itoa(amount), got, want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package notificationsvc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/notificationrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/userrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/mail"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/notification"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type NotificationService interface {
+	// Notify render a notification for a user and queue it on every channel,
+	// delivery happens in background so the caller is never blocked by the mail server
+	Notify(c context.Context, userID uuid.UUID, notifType string, data interface{}) error
+
+	FindAll(c context.Context, filter *notification.NotificationQuery) ([]notification.Notification, int, error)
+	Retry(c context.Context, id uuid.UUID) (*notification.Notification, error)
+
+	Preferences(c context.Context, userID uuid.UUID) ([]notification.Preference, error)
+	UpdatePreferences(c context.Context, userID uuid.UUID, req *notification.PreferenceRequest) ([]notification.Preference, error)
+
+	// Run deliver pending notifications until c is done
+	Run(c context.Context)
+}
+
+type notificationService struct {
+	Logger           *zap.SugaredLogger
+	Validate         *validator.Validate
+	TxManager        transaction.Manager
+	UserRepo         userrepo.UserRepository
+	NotificationRepo notificationrepo.NotificationRepository
+	Sender           mail.Sender
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	validate *validator.Validate,
+	txManager transaction.Manager,
+	userRepo userrepo.UserRepository,
+	notificationRepo notificationrepo.NotificationRepository,
+	sender mail.Sender,
+) NotificationService {
+	return &notificationService{
+		Logger:           logger,
+		Validate:         validate,
+		TxManager:        txManager,
+		UserRepo:         userRepo,
+		NotificationRepo: notificationRepo,
+		Sender:           sender,
+	}
+}
+
+func (s *notificationService) Notify(c context.Context, userID uuid.UUID, notifType string, data interface{}) error {
+	u, err := s.UserRepo.FindByColumn(c, "id", userID)
+	if err != nil {
+		return exception.ErrorNotFound("User not found")
+	}
+
+	loc := lib.DefaultLocation()
+	if u.Timezone != "" {
+		if userLoc, err := lib.LoadLocation(u.Timezone); err == nil {
+			loc = userLoc
+		}
+	}
+
+	subject, text, html, err := render(notifType, &templateData{
+		Library: utils.GetString("APP_NAME", "Library"),
+		Name:    u.Name,
+		Data:    data,
+	}, loc)
+	if err != nil {
+		s.Logger.Errorw("failed to render notification", "type", notifType, "error", err)
+		return exception.ErrorInternal("Failed to render notification")
+	}
+
+	now := time.Now()
+	notifications := make([]notification.Notification, 0)
+	for _, channel := range constant.NotifChannels {
+		enabled, err := s.NotificationRepo.IsEnabled(c, userID, notifType, channel)
+		if err != nil {
+			return exception.ErrorInternal("Failed to get notification preferences")
+		}
+
+		n := notification.Notification{
+			UserID:    userID,
+			Type:      notifType,
+			Channel:   channel,
+			Recipient: u.Email,
+			Subject:   subject,
+			BodyText:  text,
+			BodyHTML:  html,
+			Status:    constant.NotifStatus_Pending,
+		}
+		n.ID = uuid.New()
+		n.CreatedAt = &now
+
+		// the worker pick the row up once the lease end, in case the first attempt never finish
+		n.NextAttemptAt = lib.Pointer(now.Add(claimLease))
+
+		// opted out notifications are still logged
+		if !enabled {
+			n.Status = constant.NotifStatus_Skipped
+			n.NextAttemptAt = nil
+		}
+
+		notifications = append(notifications, n)
+	}
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		for i := range notifications {
+			if err := s.NotificationRepo.Create(c, tx, &notifications[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return exception.ErrorInternal("Failed to create notification")
+	}
+
+	for _, n := range notifications {
+		if n.Status == constant.NotifStatus_Pending {
+			go s.deliver(context.Background(), n)
+		}
+	}
+
+	return nil
+}
+
+func (s *notificationService) FindAll(c context.Context, filter *notification.NotificationQuery) ([]notification.Notification, int, error) {
+	// validate filter
+	if filter.Sort == "" {
+		filter.Sort = "-created_at"
+	}
+
+	if _, _, err := query.ValidateSort(filter.Sort, notificationrepo.SortNotificationMap); err != nil {
+		return nil, 0, exception.ErrorBadRequest(err.Error())
+	}
+
+	notifications, err := s.NotificationRepo.FindAll(c, filter)
+	if err != nil {
+		return nil, 0, exception.ErrorInternal("Failed to get notifications")
+	}
+
+	total, err := s.NotificationRepo.Count(c, filter)
+	if err != nil {
+		return nil, 0, exception.ErrorInternal("Failed to get total notifications")
+	}
+
+	return notifications, total, nil
+}
+
+// Retry queue a failed notification again with a fresh set of attempts
+func (s *notificationService) Retry(c context.Context, id uuid.UUID) (*notification.Notification, error) {
+	n, err := s.NotificationRepo.FindByID(c, id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("Notification not found")
+	}
+
+	if n.Status != constant.NotifStatus_Failed {
+		return nil, exception.ErrorBadRequest(fmt.Sprintf("Only %s notification can be retried", constant.NotifStatus_Failed))
+	}
+
+	n.Status = constant.NotifStatus_Pending
+	n.Attempts = 0
+	n.NextAttemptAt = lib.Pointer(time.Now())
+	if err := s.NotificationRepo.UpdateDelivery(c, n); err != nil {
+		return nil, exception.ErrorInternal("Failed to retry notification")
+	}
+
+	return n, nil
+}
+
+// Preferences return every member notification type and channel, enabled unless opted out
+func (s *notificationService) Preferences(c context.Context, userID uuid.UUID) ([]notification.Preference, error) {
+	saved, err := s.NotificationRepo.FindPreferences(c, userID)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get notification preferences")
+	}
+
+	enabled := make(map[[2]string]bool)
+	for _, p := range saved {
+		enabled[[2]string{p.Type, p.Channel}] = p.Enabled
+	}
+
+	prefs := make([]notification.Preference, 0)
+	for _, notifType := range constant.NotifTypeMember {
+		for _, channel := range constant.NotifChannels {
+			p := notification.Preference{Type: notifType, Channel: channel, Enabled: true}
+			if value, ok := enabled[[2]string{notifType, channel}]; ok {
+				p.Enabled = value
+			}
+
+			prefs = append(prefs, p)
+		}
+	}
+
+	return prefs, nil
+}
+
+func (s *notificationService) UpdatePreferences(c context.Context, userID uuid.UUID, req *notification.PreferenceRequest) ([]notification.Preference, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	for _, p := range req.Preferences {
+		if !slices.Contains(constant.NotifTypeMember, p.Type) {
+			return nil, exception.ErrorBadRequest(fmt.Sprintf("Unknown notification type %s", p.Type))
+		}
+
+		if !slices.Contains(constant.NotifChannels, p.Channel) {
+			return nil, exception.ErrorBadRequest(fmt.Sprintf("Unknown notification channel %s", p.Channel))
+		}
+	}
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.NotificationRepo.UpsertPreferences(c, tx, userID, req.Preferences)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to save notification preferences")
+	}
+
+	return s.Preferences(c, userID)
+}
@@ -0,0 +1,114 @@
+package notificationsvc
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// templates of every notification type, parsed once with placeholder functions
+// that are swapped for functions bound to the zone of the recipient on render
+var (
+	htmlTemplates = make(map[string]*htmltemplate.Template)
+	textTemplates = make(map[string]*texttemplate.Template)
+)
+
+func init() {
+	funcs := templateFuncs(time.UTC)
+	for _, notifType := range constant.NotifTypeMember {
+		htmlTemplates[notifType] = htmltemplate.Must(htmltemplate.New(notifType).Funcs(htmltemplate.FuncMap(funcs)).
+			ParseFS(templateFS, "templates/layout.html", "templates/"+notifType+".html"))
+		textTemplates[notifType] = texttemplate.Must(texttemplate.New(notifType).Funcs(funcs).
+			ParseFS(templateFS, "templates/"+notifType+".txt"))
+	}
+}
+
+// templateData is passed to every template, Data hold the type specific payload
+type templateData struct {
+	Library string
+	Name    string
+	Data    interface{}
+}
+
+func templateFuncs(loc *time.Location) texttemplate.FuncMap {
+	return texttemplate.FuncMap{
+		"date": func(t time.Time) string {
+			return t.In(loc).Format("2 January 2006")
+		},
+		"datetime": func(t time.Time) string {
+			return t.In(loc).Format("2 January 2006 15:04 MST")
+		},
+		"money": formatMoney,
+		"bookURL": func(id interface{}) string {
+			return lib.PublicURL("books", fmt.Sprint(id))
+		},
+	}
+}
+
+// formatMoney group thousands with dots, e.g. 15000 into 15.000
+func formatMoney(amount int) string {
+	s := strconv.Itoa(amount)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+
+	if negative {
+		return "-" + b.String()
+	}
+	return b.String()
+}
+
+// render return subject, text and html body of a notification
+func render(notifType string, data *templateData, loc *time.Location) (subject, text, html string, err error) {
+	funcs := templateFuncs(loc)
+
+	textTmpl, err := textTemplates[notifType].Clone()
+	if err != nil {
+		return "", "", "", err
+	}
+	textTmpl.Funcs(funcs)
+
+	htmlTmpl, err := htmlTemplates[notifType].Clone()
+	if err != nil {
+		return "", "", "", err
+	}
+	htmlTmpl.Funcs(htmltemplate.FuncMap(funcs))
+
+	var buf bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", "", err
+	}
+	subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := textTmpl.ExecuteTemplate(&buf, notifType+".txt", data); err != nil {
+		return "", "", "", err
+	}
+	text = buf.String()
+
+	buf.Reset()
+	if err := htmlTmpl.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return "", "", "", err
+	}
+	html = buf.String()
+
+	return subject, text, html, nil
+}
@@ -0,0 +1,446 @@
+package oaisvc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/bookrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/oai"
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	dayGranularity     = "2006-01-02"
+	secondsGranularity = "2006-01-02T15:04:05Z"
+)
+
+var (
+	repositoryName = utils.GetString("OAI_REPOSITORY_NAME", utils.GetString("APP_NAME", "Library"))
+	repositoryID   = utils.GetString("OAI_REPOSITORY_ID", "library")
+	adminEmail     = utils.GetString("OAI_ADMIN_EMAIL", "admin@localhost")
+	pageSize       = utils.GetInt("OAI_PAGE_SIZE", 100)
+
+	// arguments accepted by each verb besides verb itself, true when required
+	verbArguments = map[string]map[string]bool{
+		"Identify":            {},
+		"ListMetadataFormats": {"identifier": false},
+		"ListIdentifiers":     {"metadataPrefix": true, "from": false, "until": false, "set": false},
+		"ListRecords":         {"metadataPrefix": true, "from": false, "until": false, "set": false},
+		"GetRecord":           {"identifier": true, "metadataPrefix": true},
+	}
+
+	dcFormat = oai.MetadataFormat{
+		MetadataPrefix:    oai.DCPrefix,
+		Schema:            oai.DCSchema,
+		MetadataNamespace: oai.DCNamespace,
+	}
+)
+
+type OAIService interface {
+	// Handle answer a protocol request, protocol errors are part of the response
+	Handle(c context.Context, baseURL string, args map[string][]string) *oai.Response
+}
+
+type oaiService struct {
+	Logger   *zap.SugaredLogger
+	BookRepo bookrepo.BookRepository
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	bookRepo bookrepo.BookRepository,
+) OAIService {
+	return &oaiService{
+		Logger:   logger,
+		BookRepo: bookRepo,
+	}
+}
+
+// listRequest is the state of a list request, carried between pages by the resumption token
+type listRequest struct {
+	from, until string
+	cursor      int // records already returned
+
+	// created_at and id of the last record returned, the next page start after it
+	afterCreatedAt *time.Time
+	afterID        uuid.UUID
+}
+
+func (s *oaiService) Handle(c context.Context, baseURL string, args map[string][]string) *oai.Response {
+	res := &oai.Response{
+		Xmlns:          oai.Namespace,
+		XmlnsXSI:       oai.XSINamespace,
+		SchemaLocation: oai.SchemaLocation,
+		ResponseDate:   time.Now().UTC().Format(secondsGranularity),
+		Request:        oai.Request{BaseURL: baseURL},
+	}
+
+	params, err := validateArguments(args)
+	if err != nil {
+		res.Errors = append(res.Errors, *err)
+		return res
+	}
+
+	// request is only echoed once arguments are known to be valid
+	res.Request = oai.Request{
+		Verb:            params["verb"],
+		Identifier:      params["identifier"],
+		MetadataPrefix:  params["metadataPrefix"],
+		From:            params["from"],
+		Until:           params["until"],
+		Set:             params["set"],
+		ResumptionToken: params["resumptionToken"],
+		BaseURL:         baseURL,
+	}
+
+	switch params["verb"] {
+	case "Identify":
+		res.Identify, err = s.identify(c, baseURL)
+	case "ListMetadataFormats":
+		res.ListMetadataFormats, err = s.listMetadataFormats(c, params)
+	case "ListIdentifiers", "ListRecords":
+		var records []oai.Record
+		var token *oai.ResumptionToken
+		if records, token, err = s.list(c, baseURL, params); err == nil {
+			if params["verb"] == "ListRecords" {
+				res.ListRecords = &oai.ListRecords{Records: records, ResumptionToken: token}
+			} else {
+				res.ListIdentifiers = &oai.ListIdentifiers{ResumptionToken: token}
+				for _, r := range records {
+					res.ListIdentifiers.Headers = append(res.ListIdentifiers.Headers, r.Header)
+				}
+			}
+		}
+	case "GetRecord":
+		var record *oai.Record
+		if record, err = s.getRecord(c, baseURL, params); err == nil {
+			res.GetRecord = &oai.GetRecord{Record: *record}
+		}
+	}
+
+	if err != nil {
+		res.Errors = append(res.Errors, *err)
+	}
+
+	return res
+}
+
+// validateArguments check verb, repeated, missing and unknown arguments,
+// resumptionToken is exclusive and replaces every other argument of list verbs
+func validateArguments(args map[string][]string) (map[string]string, *oai.Error) {
+	params := make(map[string]string)
+	for key, values := range args {
+		if len(values) > 1 {
+			return nil, oai.NewError(oai.ErrBadArgument, fmt.Sprintf("argument %s is repeated", key))
+		}
+		params[key] = values[0]
+	}
+
+	verb := params["verb"]
+	allowed, ok := verbArguments[verb]
+	if !ok {
+		return nil, oai.NewError(oai.ErrBadVerb, "verb is missing or not a legal OAI-PMH verb")
+	}
+
+	if _, ok := params["resumptionToken"]; ok && (verb == "ListIdentifiers" || verb == "ListRecords") {
+		if len(params) != 2 {
+			return nil, oai.NewError(oai.ErrBadArgument, "resumptionToken is an exclusive argument")
+		}
+		return params, nil
+	}
+
+	for key := range params {
+		if _, ok := allowed[key]; !ok && key != "verb" {
+			return nil, oai.NewError(oai.ErrBadArgument, fmt.Sprintf("argument %s is not allowed for %s", key, verb))
+		}
+	}
+
+	for key, required := range allowed {
+		if required && params[key] == "" {
+			return nil, oai.NewError(oai.ErrBadArgument, fmt.Sprintf("argument %s is required for %s", key, verb))
+		}
+	}
+
+	return params, nil
+}
+
+func (s *oaiService) identify(c context.Context, baseURL string) (*oai.Identify, *oai.Error) {
+	earliest := time.Now()
+
+	// updates only move datestamps forward, the oldest book holds the earliest datestamp
+	books, err := s.BookRepo.FindAll(c, &book.BookQuery{QueryParam: model.QueryParam{Sort: "created_at", Page: 1, Limit: 1}})
+	if err != nil {
+		s.Logger.Errorw("Failed to get earliest datestamp", "error", err)
+	} else if len(books) > 0 && books[0].CreatedAt != nil {
+		earliest = *books[0].CreatedAt
+	}
+
+	return &oai.Identify{
+		RepositoryName:    repositoryName,
+		BaseURL:           baseURL,
+		ProtocolVersion:   "2.0",
+		AdminEmail:        adminEmail,
+		EarliestDatestamp: earliest.UTC().Format(secondsGranularity),
+		DeletedRecord:     "no",
+		Granularity:       "YYYY-MM-DDThh:mm:ssZ",
+	}, nil
+}
+
+func (s *oaiService) listMetadataFormats(c context.Context, params map[string]string) (*oai.ListMetadataFormats, *oai.Error) {
+	if identifier := params["identifier"]; identifier != "" {
+		if _, err := s.findBook(c, identifier); err != nil {
+			return nil, err
+		}
+	}
+
+	return &oai.ListMetadataFormats{MetadataFormats: []oai.MetadataFormat{dcFormat}}, nil
+}
+
+func (s *oaiService) getRecord(c context.Context, baseURL string, params map[string]string) (*oai.Record, *oai.Error) {
+	if params["metadataPrefix"] != oai.DCPrefix {
+		return nil, oai.NewError(oai.ErrCannotDisseminateFormat, "only oai_dc is supported")
+	}
+
+	b, err := s.findBook(c, params["identifier"])
+	if err != nil {
+		return nil, err
+	}
+
+	return newRecord(baseURL, b), nil
+}
+
+// list return a page of records in (created_at, id) order, the token carry the last key returned
+// so books added or removed while harvesting do not shift the following pages
+func (s *oaiService) list(c context.Context, baseURL string, params map[string]string) ([]oai.Record, *oai.ResumptionToken, *oai.Error) {
+	req := listRequest{from: params["from"], until: params["until"]}
+
+	if token := params["resumptionToken"]; token != "" {
+		var ok bool
+		if req, ok = decodeToken(token); !ok {
+			return nil, nil, oai.NewError(oai.ErrBadResumptionToken, "resumptionToken is invalid")
+		}
+	} else {
+		if params["metadataPrefix"] != oai.DCPrefix {
+			return nil, nil, oai.NewError(oai.ErrCannotDisseminateFormat, "only oai_dc is supported")
+		}
+
+		if params["set"] != "" {
+			return nil, nil, oai.NewError(oai.ErrNoSetHierarchy, "repository does not support sets")
+		}
+	}
+
+	// one more book is read to know whether another page follows
+	filter := &book.BookQuery{QueryParam: model.QueryParam{Sort: "created_at", Page: 1, Limit: pageSize + 1}}
+
+	var err error
+	if filter.ModifiedFrom, filter.ModifiedUntil, err = parseRange(req.from, req.until); err != nil {
+		return nil, nil, oai.NewError(oai.ErrBadArgument, err.Error())
+	}
+
+	total, err := s.BookRepo.Count(c, filter)
+	if err != nil {
+		s.Logger.Errorw("Failed to count harvested books", "error", err)
+		return nil, nil, oai.NewError(oai.ErrNoRecordsMatch, "failed to get records")
+	}
+
+	if total == 0 {
+		return nil, nil, oai.NewError(oai.ErrNoRecordsMatch, "no records match the request")
+	}
+
+	// the keyset is left out of the count so the complete list size is the same on every page
+	filter.AfterCreatedAt, filter.AfterID = req.afterCreatedAt, req.afterID
+
+	books, err := s.BookRepo.FindAll(c, filter)
+	if err != nil {
+		s.Logger.Errorw("Failed to get harvested books", "error", err)
+		return nil, nil, oai.NewError(oai.ErrNoRecordsMatch, "failed to get records")
+	}
+
+	if len(books) == 0 {
+		return nil, nil, oai.NewError(oai.ErrBadResumptionToken, "resumptionToken is past the end of the list")
+	}
+
+	more := len(books) > pageSize
+	if more {
+		books = books[:pageSize]
+	}
+
+	records := make([]oai.Record, 0)
+	for i := range books {
+		records = append(records, *newRecord(baseURL, &books[i]))
+	}
+
+	// first page of a complete list carries no token, last page of an incomplete list carries an empty one
+	var token *oai.ResumptionToken
+	if last := books[len(books)-1]; more && last.CreatedAt != nil {
+		next := listRequest{
+			from:           req.from,
+			until:          req.until,
+			cursor:         req.cursor + len(books),
+			afterCreatedAt: last.CreatedAt,
+			afterID:        last.ID,
+		}
+		token = &oai.ResumptionToken{CompleteListSize: total, Cursor: req.cursor, Value: encodeToken(next)}
+	} else if req.afterCreatedAt != nil {
+		token = &oai.ResumptionToken{CompleteListSize: total, Cursor: req.cursor}
+	}
+
+	return records, token, nil
+}
+
+// findBook resolve an oai identifier, e.g. oai:library:2b9c...
+func (s *oaiService) findBook(c context.Context, identifier string) (*book.Book, *oai.Error) {
+	id, err := uuid.Parse(strings.TrimPrefix(identifier, "oai:"+repositoryID+":"))
+	if err != nil || !strings.HasPrefix(identifier, "oai:"+repositoryID+":") {
+		return nil, oai.NewError(oai.ErrIDDoesNotExist, "identifier is unknown in this repository")
+	}
+
+	b, err := s.BookRepo.FindByID(c, id)
+	if err != nil {
+		return nil, oai.NewError(oai.ErrIDDoesNotExist, "identifier is unknown in this repository")
+	}
+
+	return b, nil
+}
+
+func newRecord(baseURL string, b *book.Book) *oai.Record {
+	datestamp := b.CreatedAt
+	if b.UpdatedAt != nil {
+		datestamp = b.UpdatedAt
+	}
+
+	dc := oai.DublinCore{
+		XmlnsOAIDC:     oai.DCNamespace,
+		XmlnsDC:        oai.DCElementsNS,
+		XmlnsXSI:       oai.XSINamespace,
+		SchemaLocation: oai.DCSchemaLocation,
+		Title:          b.Title,
+		Creator:        b.Author,
+		Subject:        b.Genre,
+		Description:    b.Summary,
+		Publisher:      b.Publisher,
+		Type:           "Text",
+		Identifiers:    []string{strings.TrimSuffix(baseURL, "/oai") + "/books/" + b.ID.String()},
+		Language:       b.Language,
+	}
+
+	if b.PublishedYear != nil {
+		dc.Date = strconv.Itoa(*b.PublishedYear)
+	}
+
+	if b.ISBN != "" {
+		dc.Identifiers = append(dc.Identifiers, "urn:isbn:"+b.ISBN)
+	}
+
+	record := &oai.Record{
+		Header: oai.Header{
+			Identifier: "oai:" + repositoryID + ":" + b.ID.String(),
+		},
+		Metadata: oai.Metadata{DC: dc},
+	}
+
+	if datestamp != nil {
+		record.Header.Datestamp = datestamp.UTC().Format(secondsGranularity)
+	}
+
+	return record
+}
+
+// parseRange parse from and until of the same granularity, both bounds are inclusive
+func parseRange(from, until string) (*time.Time, *time.Time, error) {
+	fromTime, fromLayout, err := parseDatestamp(from)
+	if err != nil {
+		return nil, nil, errors.New("from is not a valid datestamp")
+	}
+
+	untilTime, untilLayout, err := parseDatestamp(until)
+	if err != nil {
+		return nil, nil, errors.New("until is not a valid datestamp")
+	}
+
+	if fromTime != nil && untilTime != nil {
+		if fromLayout != untilLayout {
+			return nil, nil, errors.New("from and until must have the same granularity")
+		}
+
+		if fromTime.After(*untilTime) {
+			return nil, nil, errors.New("from must not be later than until")
+		}
+	}
+
+	// until cover the whole day or second it names
+	if untilTime != nil {
+		step := time.Second
+		if untilLayout == dayGranularity {
+			step = 24 * time.Hour
+		}
+
+		end := untilTime.Add(step - time.Nanosecond)
+		untilTime = &end
+	}
+
+	return fromTime, untilTime, nil
+}
+
+func parseDatestamp(value string) (*time.Time, string, error) {
+	if value == "" {
+		return nil, "", nil
+	}
+
+	for _, layout := range []string{dayGranularity, secondsGranularity} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, layout, nil
+		}
+	}
+
+	return nil, "", errors.New("invalid datestamp")
+}
+
+func encodeToken(req listRequest) string {
+	raw := strings.Join([]string{
+		req.from,
+		req.until,
+		strconv.Itoa(req.cursor),
+		req.afterCreatedAt.UTC().Format(time.RFC3339Nano),
+		req.afterID.String(),
+	}, "|")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeToken(token string) (listRequest, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return listRequest{}, false
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 5 {
+		return listRequest{}, false
+	}
+
+	cursor, err := strconv.Atoi(parts[2])
+	if err != nil || cursor < 1 {
+		return listRequest{}, false
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[3])
+	if err != nil {
+		return listRequest{}, false
+	}
+
+	id, err := uuid.Parse(parts[4])
+	if err != nil {
+		return listRequest{}, false
+	}
+
+	return listRequest{from: parts[0], until: parts[1], cursor: cursor, afterCreatedAt: &createdAt, afterID: id}, true
+}
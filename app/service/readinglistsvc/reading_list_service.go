@@ -0,0 +1,359 @@
+package readinglistsvc
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/bookrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/readinglistrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/citation"
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/readinglist"
+	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type ReadingListService interface {
+	Create(c context.Context, userID uuid.UUID, req *readinglist.ListRequest) (*readinglist.ReadingList, error)
+	FindMine(c context.Context, userID uuid.UUID) ([]readinglist.ReadingList, error)
+	FindByID(c context.Context, id, userID uuid.UUID) (*readinglist.ReadingListDetail, error)
+	FindShared(c context.Context, token string) (*readinglist.ReadingListDetail, error)
+	Update(c context.Context, id, userID uuid.UUID, req *readinglist.ListRequest) (*readinglist.ReadingList, error)
+	Delete(c context.Context, id, userID uuid.UUID) error
+
+	AddItem(c context.Context, id, userID uuid.UUID, req *readinglist.ItemRequest) (*readinglist.ReadingListDetail, error)
+	RemoveItem(c context.Context, id, userID, bookID uuid.UUID) (*readinglist.ReadingListDetail, error)
+	Reorder(c context.Context, id, userID uuid.UUID, req *readinglist.ReorderRequest) (*readinglist.ReadingListDetail, error)
+
+	Cite(c context.Context, id, userID uuid.UUID, format citation.Format, w io.Writer) error
+}
+
+type readingListService struct {
+	Logger          *zap.SugaredLogger
+	Validate        *validator.Validate
+	TxManager       transaction.Manager
+	BookRepo        bookrepo.BookRepository
+	ReadingListRepo readinglistrepo.ReadingListRepository
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	validate *validator.Validate,
+	txManager transaction.Manager,
+	bookRepo bookrepo.BookRepository,
+	readingListRepo readinglistrepo.ReadingListRepository,
+) ReadingListService {
+	return &readingListService{
+		Logger:          logger,
+		Validate:        validate,
+		TxManager:       txManager,
+		BookRepo:        bookRepo,
+		ReadingListRepo: readingListRepo,
+	}
+}
+
+func (s *readingListService) Create(c context.Context, userID uuid.UUID, req *readinglist.ListRequest) (*readinglist.ReadingList, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	// create new list data
+	list := readinglist.ReadingList{
+		UserID: userID,
+		Name:   req.Name,
+	}
+	list.ID = uuid.New()
+	list.CreatedAt = lib.Pointer(time.Now())
+
+	if err := setVisibility(&list, req.Visibility); err != nil {
+		return nil, err
+	}
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.ReadingListRepo.Create(c, tx, &list)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to create reading list")
+	}
+
+	return &list, nil
+}
+
+// FindMine return lists of user, the default list is created on first access
+func (s *readingListService) FindMine(c context.Context, userID uuid.UUID) ([]readinglist.ReadingList, error) {
+	lists, err := s.ReadingListRepo.FindByUser(c, userID)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get reading lists")
+	}
+
+	for _, list := range lists {
+		if list.IsDefault {
+			return lists, nil
+		}
+	}
+
+	// create default list data
+	list := readinglist.ReadingList{
+		UserID:     userID,
+		Name:       constant.DefaultListName,
+		IsDefault:  true,
+		Visibility: constant.ListVisibility_Private,
+	}
+	list.ID = uuid.New()
+	list.CreatedAt = lib.Pointer(time.Now())
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.ReadingListRepo.Create(c, tx, &list)
+	}); err != nil {
+		// default list may have been created by a concurrent request
+		s.Logger.Warnw("Failed to create default reading list", "user_id", userID, "error", err)
+	}
+
+	lists, err = s.ReadingListRepo.FindByUser(c, userID)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get reading lists")
+	}
+
+	return lists, nil
+}
+
+// FindByID return list with its items, lists of other users are only visible when public
+func (s *readingListService) FindByID(c context.Context, id, userID uuid.UUID) (*readinglist.ReadingListDetail, error) {
+	list, err := s.ReadingListRepo.FindByID(c, id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("Reading list not found")
+	}
+
+	if list.UserID != userID {
+		if list.Visibility != constant.ListVisibility_Public {
+			return nil, exception.ErrorNotFound("Reading list not found")
+		}
+
+		// share token is only shown to the owner
+		list.ShareToken = nil
+	}
+
+	return s.detail(c, list)
+}
+
+// Cite write citations of every book of a list in list order
+func (s *readingListService) Cite(c context.Context, id, userID uuid.UUID, format citation.Format, w io.Writer) error {
+	list, err := s.FindByID(c, id, userID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uuid.UUID, 0)
+	for _, item := range list.Items {
+		ids = append(ids, item.BookID)
+	}
+
+	books, err := s.BookRepo.FindByIDs(c, ids)
+	if err != nil {
+		return exception.ErrorInternal("Failed to get books")
+	}
+
+	if err := format.Write(w, book.Citations(books)); err != nil {
+		return exception.ErrorInternal("Failed to write citation")
+	}
+
+	return nil
+}
+
+func (s *readingListService) FindShared(c context.Context, token string) (*readinglist.ReadingListDetail, error) {
+	list, err := s.ReadingListRepo.FindByShareToken(c, token)
+	if err != nil || list.Visibility != constant.ListVisibility_Public {
+		return nil, exception.ErrorNotFound("Reading list not found")
+	}
+	list.ShareToken = nil
+
+	return s.detail(c, list)
+}
+
+func (s *readingListService) Update(c context.Context, id, userID uuid.UUID, req *readinglist.ListRequest) (*readinglist.ReadingList, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	list, err := s.findOwned(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.IsDefault && req.Name != list.Name {
+		return nil, exception.ErrorBadRequest("Default list cannot be renamed")
+	}
+
+	// update list data, visibility is kept when not given
+	list.Name = req.Name
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = list.Visibility
+	}
+	if err := setVisibility(list, visibility); err != nil {
+		return nil, err
+	}
+	list.UpdatedAt = lib.Pointer(time.Now())
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.ReadingListRepo.Update(c, tx, list)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to update reading list")
+	}
+
+	return list, nil
+}
+
+func (s *readingListService) Delete(c context.Context, id, userID uuid.UUID) error {
+	list, err := s.findOwned(c, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if list.IsDefault {
+		return exception.ErrorBadRequest("Default list cannot be deleted")
+	}
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.ReadingListRepo.Delete(c, tx, id)
+	}); err != nil {
+		return exception.ErrorInternal("Failed to delete reading list")
+	}
+
+	return nil
+}
+
+func (s *readingListService) AddItem(c context.Context, id, userID uuid.UUID, req *readinglist.ItemRequest) (*readinglist.ReadingListDetail, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	list, err := s.findOwned(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.BookRepo.FindByID(c, req.BookID); err != nil {
+		return nil, exception.ErrorNotFound("Book not found")
+	}
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.ReadingListRepo.AddItem(c, tx, id, req.BookID)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to add book to reading list")
+	}
+
+	return s.detail(c, list)
+}
+
+func (s *readingListService) RemoveItem(c context.Context, id, userID, bookID uuid.UUID) (*readinglist.ReadingListDetail, error) {
+	list, err := s.findOwned(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.ReadingListRepo.RemoveItem(c, tx, id, bookID)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to remove book from reading list")
+	}
+
+	return s.detail(c, list)
+}
+
+// Reorder set order of items, request must contain every book of the list exactly once
+func (s *readingListService) Reorder(c context.Context, id, userID uuid.UUID, req *readinglist.ReorderRequest) (*readinglist.ReadingListDetail, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	list, err := s.findOwned(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.ReadingListRepo.FindItems(c, id)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get reading list items")
+	}
+
+	current := make(map[uuid.UUID]bool)
+	for _, item := range items {
+		current[item.BookID] = true
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	for _, bookID := range req.BookIDs {
+		if !current[bookID] || seen[bookID] {
+			return nil, exception.ErrorBadRequest("book_ids must contain every book of the list exactly once")
+		}
+		seen[bookID] = true
+	}
+
+	if len(seen) != len(current) {
+		return nil, exception.ErrorBadRequest("book_ids must contain every book of the list exactly once")
+	}
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.ReadingListRepo.Reorder(c, tx, id, req.BookIDs)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to reorder reading list")
+	}
+
+	return s.detail(c, list)
+}
+
+func (s *readingListService) findOwned(c context.Context, id, userID uuid.UUID) (*readinglist.ReadingList, error) {
+	list, err := s.ReadingListRepo.FindByID(c, id)
+	if err != nil || list.UserID != userID {
+		return nil, exception.ErrorNotFound("Reading list not found")
+	}
+
+	return list, nil
+}
+
+func (s *readingListService) detail(c context.Context, list *readinglist.ReadingList) (*readinglist.ReadingListDetail, error) {
+	items, err := s.ReadingListRepo.FindItems(c, list.ID)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get reading list items")
+	}
+	list.TotalItems = len(items)
+
+	return &readinglist.ReadingListDetail{
+		ReadingList: *list,
+		Items:       items,
+	}, nil
+}
+
+// setVisibility apply visibility to list, public lists get a share token which is kept until made private
+func setVisibility(list *readinglist.ReadingList, visibility string) error {
+	if visibility == "" {
+		visibility = constant.ListVisibility_Private
+	}
+	list.Visibility = visibility
+
+	if visibility == constant.ListVisibility_Private {
+		list.ShareToken = nil
+		return nil
+	}
+
+	if list.ShareToken == nil {
+		token, err := lib.RandomToken(24)
+		if err != nil {
+			return exception.ErrorInternal("Failed to create share link")
+		}
+		list.ShareToken = &token
+	}
+
+	return nil
+}
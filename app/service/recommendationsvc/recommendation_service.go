@@ -0,0 +1,98 @@
+package recommendationsvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/bookrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/recommendationrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/recommendation"
+	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var (
+	// number of precomputed recommendations kept per book and per member
+	precomputed = utils.GetInt("RECOMMENDATION_SIZE", 50)
+)
+
+type RecommendationService interface {
+	AlsoBorrowed(c context.Context, bookID uuid.UUID, limit int) ([]recommendation.Recommendation, error)
+	ForUser(c context.Context, userID uuid.UUID, limit int) ([]recommendation.Recommendation, error)
+
+	// Refresh recompute every precomputed recommendation
+	Refresh(c context.Context) error
+}
+
+type recommendationService struct {
+	Logger             *zap.SugaredLogger
+	TxManager          transaction.Manager
+	BookRepo           bookrepo.BookRepository
+	RecommendationRepo recommendationrepo.RecommendationRepository
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	txManager transaction.Manager,
+	bookRepo bookrepo.BookRepository,
+	recommendationRepo recommendationrepo.RecommendationRepository,
+) RecommendationService {
+	return &recommendationService{
+		Logger:             logger,
+		TxManager:          txManager,
+		BookRepo:           bookRepo,
+		RecommendationRepo: recommendationRepo,
+	}
+}
+
+func validateLimit(limit int) int {
+	if limit < 1 {
+		return 10
+	}
+
+	return min(limit, precomputed)
+}
+
+func (s *recommendationService) AlsoBorrowed(c context.Context, bookID uuid.UUID, limit int) ([]recommendation.Recommendation, error) {
+	if _, err := s.BookRepo.FindByID(c, bookID); err != nil {
+		return nil, exception.ErrorNotFound("Book not found")
+	}
+
+	res, err := s.RecommendationRepo.FindAlsoBorrowed(c, bookID, validateLimit(limit))
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get recommendations")
+	}
+
+	return res, nil
+}
+
+func (s *recommendationService) ForUser(c context.Context, userID uuid.UUID, limit int) ([]recommendation.Recommendation, error) {
+	res, err := s.RecommendationRepo.FindForUser(c, userID, validateLimit(limit))
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get recommendations")
+	}
+
+	return res, nil
+}
+
+func (s *recommendationService) Refresh(c context.Context) error {
+	start := time.Now()
+
+	// tables are replaced in one transaction so readers never see a half refreshed table
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		if err := s.RecommendationRepo.RefreshAlsoBorrowed(c, tx, precomputed, start); err != nil {
+			return err
+		}
+
+		return s.RecommendationRepo.RefreshPersonal(c, tx, precomputed, start)
+	}); err != nil {
+		return exception.ErrorInternal("Failed to refresh recommendations")
+	}
+
+	s.Logger.Infow("recommendations refreshed", "duration", time.Since(start))
+	return nil
+}
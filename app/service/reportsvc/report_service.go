@@ -0,0 +1,163 @@
+package reportsvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/reportrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/report"
+	"go.uber.org/zap"
+)
+
+type ReportService interface {
+	MostBorrowed(c context.Context, filter *report.ReportQuery) ([]report.MostBorrowed, error)
+	Revenue(c context.Context, filter *report.ReportQuery) ([]report.Revenue, error)
+	Members(c context.Context, filter *report.ReportQuery) (*report.MemberSummary, error)
+	ReturnTime(c context.Context, filter *report.ReportQuery) ([]report.ReturnTime, error)
+	Utilization(c context.Context, filter *report.ReportQuery) ([]report.Utilization, error)
+}
+
+type reportService struct {
+	Logger     *zap.SugaredLogger
+	ReportRepo reportrepo.ReportRepository
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	reportRepo reportrepo.ReportRepository,
+) ReportService {
+	return &reportService{
+		Logger:     logger,
+		ReportRepo: reportRepo,
+	}
+}
+
+// validateQuery validate grouping and fill in the default date range
+func validateQuery(filter *report.ReportQuery, requireGroup bool) error {
+	if filter.GroupBy == "" && requireGroup {
+		filter.GroupBy = "month"
+	}
+
+	if filter.GroupBy != "" {
+		if _, ok := reportrepo.GroupByMap[filter.GroupBy]; !ok {
+			return exception.ErrorBadRequest("group_by must be one of 'day', 'week', 'month'")
+		}
+	}
+
+	if filter.EndDate.IsZero() {
+		filter.EndDate = time.Now()
+	}
+
+	if filter.StartDate.IsZero() {
+		switch filter.GroupBy {
+		case "day":
+			filter.StartDate = filter.EndDate.AddDate(0, -1, 0)
+		case "week":
+			filter.StartDate = filter.EndDate.AddDate(0, -3, 0)
+		default:
+			filter.StartDate = filter.EndDate.AddDate(-1, 0, 0)
+		}
+	}
+
+	if filter.StartDate.After(filter.EndDate) {
+		return exception.ErrorBadRequest("start_date must be before end_date")
+	}
+
+	return nil
+}
+
+func (s *reportService) MostBorrowed(c context.Context, filter *report.ReportQuery) ([]report.MostBorrowed, error) {
+	// validate filter
+	if err := validateQuery(filter, false); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 10
+	}
+
+	res, err := s.ReportRepo.MostBorrowed(c, filter)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get most borrowed books")
+	}
+
+	return res, nil
+}
+
+func (s *reportService) Revenue(c context.Context, filter *report.ReportQuery) ([]report.Revenue, error) {
+	// validate filter
+	if err := validateQuery(filter, true); err != nil {
+		return nil, err
+	}
+
+	res, err := s.ReportRepo.Revenue(c, filter)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get revenue")
+	}
+
+	return res, nil
+}
+
+func (s *reportService) Members(c context.Context, filter *report.ReportQuery) (*report.MemberSummary, error) {
+	// validate filter
+	if err := validateQuery(filter, true); err != nil {
+		return nil, err
+	}
+
+	if filter.DormantDays <= 0 {
+		filter.DormantDays = 90
+	}
+
+	// get active and dormant members as of end date
+	res, err := s.ReportRepo.MemberSummary(c, filter.EndDate, filter.DormantDays)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get member summary")
+	}
+
+	// get member activity per period
+	res.Periods, err = s.ReportRepo.MemberActivity(c, filter)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get member activity")
+	}
+
+	return res, nil
+}
+
+func (s *reportService) ReturnTime(c context.Context, filter *report.ReportQuery) ([]report.ReturnTime, error) {
+	// validate filter
+	if err := validateQuery(filter, true); err != nil {
+		return nil, err
+	}
+
+	res, err := s.ReportRepo.ReturnTime(c, filter)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get return time")
+	}
+
+	return res, nil
+}
+
+func (s *reportService) Utilization(c context.Context, filter *report.ReportQuery) ([]report.Utilization, error) {
+	// validate filter
+	if err := validateQuery(filter, true); err != nil {
+		return nil, err
+	}
+
+	// every book adds a row per period, books are paged to keep the report bounded
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	filter.Limit = min(filter.Limit, 100)
+
+	res, err := s.ReportRepo.Utilization(c, filter)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get utilization")
+	}
+
+	return res, nil
+}
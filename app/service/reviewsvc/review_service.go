@@ -0,0 +1,221 @@
+package reviewsvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/bookrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/borrowrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/reviewrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/review"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type ReviewService interface {
+	Create(c context.Context, bookID, userID uuid.UUID, req *review.ReviewRequest) (*review.Review, error)
+	FindAll(c context.Context, filter *review.ReviewQuery) ([]review.ReviewDTO, int, error)
+	Update(c context.Context, id, userID uuid.UUID, req *review.ReviewRequest) (*review.Review, error)
+	Delete(c context.Context, id, userID uuid.UUID, isAdmin bool) error
+
+	Moderate(c context.Context, id uuid.UUID, req *review.ModerateRequest) (*review.Review, error)
+}
+
+type reviewService struct {
+	Logger     *zap.SugaredLogger
+	Validate   *validator.Validate
+	TxManager  transaction.Manager
+	BookRepo   bookrepo.BookRepository
+	BorrowRepo borrowrepo.BorrowRepository
+	ReviewRepo reviewrepo.ReviewRepository
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	validate *validator.Validate,
+	txManager transaction.Manager,
+	bookRepo bookrepo.BookRepository,
+	borrowRepo borrowrepo.BorrowRepository,
+	reviewRepo reviewrepo.ReviewRepository,
+) ReviewService {
+	return &reviewService{
+		Logger:     logger,
+		Validate:   validate,
+		TxManager:  txManager,
+		BookRepo:   bookRepo,
+		BorrowRepo: borrowRepo,
+		ReviewRepo: reviewRepo,
+	}
+}
+
+func (s *reviewService) Create(c context.Context, bookID, userID uuid.UUID, req *review.ReviewRequest) (*review.Review, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	if _, err := s.BookRepo.FindByID(c, bookID); err != nil {
+		return nil, exception.ErrorNotFound("Book not found")
+	}
+
+	// only members who have read the book may review it
+	returned, err := s.BorrowRepo.HasReturned(c, userID, bookID)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to check borrow history")
+	}
+	if !returned {
+		return nil, exception.ErrorForbidden("You can only review books you have borrowed and returned")
+	}
+
+	exists, err := s.ReviewRepo.Exists(c, bookID, userID)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to check review")
+	}
+	if exists {
+		return nil, exception.ErrorConflict("You have already reviewed this book")
+	}
+
+	// create new review data
+	rv := review.Review{
+		BookID:  bookID,
+		UserID:  userID,
+		Rating:  req.Rating,
+		Content: req.Content,
+		Status:  constant.ReviewStatus_Published,
+	}
+	rv.ID = uuid.New()
+	rv.CreatedAt = lib.Pointer(time.Now())
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		if err := s.ReviewRepo.Create(c, tx, &rv); err != nil {
+			return err
+		}
+
+		return s.ReviewRepo.RefreshBookRating(c, tx, bookID)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to create review")
+	}
+
+	return &rv, nil
+}
+
+func (s *reviewService) FindAll(c context.Context, filter *review.ReviewQuery) ([]review.ReviewDTO, int, error) {
+	// validate filter
+	if filter.Sort == "" {
+		filter.Sort = "-created_at"
+	}
+
+	if _, _, err := query.ValidateSort(filter.Sort, reviewrepo.SortReviewMap); err != nil {
+		return nil, 0, exception.ErrorBadRequest(err.Error())
+	}
+
+	reviews, err := s.ReviewRepo.FindAll(c, filter)
+	if err != nil {
+		return nil, 0, exception.ErrorInternal("Failed to get reviews")
+	}
+
+	total, err := s.ReviewRepo.Count(c, filter)
+	if err != nil {
+		return nil, 0, exception.ErrorInternal("Failed to get total reviews")
+	}
+
+	return reviews, total, nil
+}
+
+func (s *reviewService) Update(c context.Context, id, userID uuid.UUID, req *review.ReviewRequest) (*review.Review, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	// get review data
+	rv, err := s.ReviewRepo.FindByID(c, id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("Review not found")
+	}
+
+	if rv.UserID != userID {
+		return nil, exception.ErrorForbidden("You can only edit your own review")
+	}
+
+	// update review data, moderation status is kept
+	rv.Rating = req.Rating
+	rv.Content = req.Content
+	rv.UpdatedAt = lib.Pointer(time.Now())
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		if err := s.ReviewRepo.Update(c, tx, &rv.Review); err != nil {
+			return err
+		}
+
+		return s.ReviewRepo.RefreshBookRating(c, tx, rv.BookID)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to update review")
+	}
+
+	return &rv.Review, nil
+}
+
+func (s *reviewService) Delete(c context.Context, id, userID uuid.UUID, isAdmin bool) error {
+	// get review data
+	rv, err := s.ReviewRepo.FindByID(c, id)
+	if err != nil {
+		return exception.ErrorNotFound("Review not found")
+	}
+
+	if !isAdmin && rv.UserID != userID {
+		return exception.ErrorForbidden("You can only delete your own review")
+	}
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		if err := s.ReviewRepo.Delete(c, tx, id); err != nil {
+			return err
+		}
+
+		return s.ReviewRepo.RefreshBookRating(c, tx, rv.BookID)
+	}); err != nil {
+		return exception.ErrorInternal("Failed to delete review")
+	}
+
+	return nil
+}
+
+// Moderate publish or hide a review, hidden reviews are left out of the book rating
+func (s *reviewService) Moderate(c context.Context, id uuid.UUID, req *review.ModerateRequest) (*review.Review, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	// get review data
+	rv, err := s.ReviewRepo.FindByID(c, id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("Review not found")
+	}
+
+	rv.Status = req.Status
+	rv.ModerationNote = nil
+	if req.Note != "" {
+		rv.ModerationNote = lib.Strptr(req.Note)
+	}
+	rv.UpdatedAt = lib.Pointer(time.Now())
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		if err := s.ReviewRepo.Update(c, tx, &rv.Review); err != nil {
+			return err
+		}
+
+		return s.ReviewRepo.RefreshBookRating(c, tx, rv.BookID)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to moderate review")
+	}
+
+	return &rv.Review, nil
+}
@@ -0,0 +1,76 @@
+package schedulersvc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dikyayodihamzah/library-management-api/app/service/borrowsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/eventsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/exportsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/recommendationsvc"
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+	"github.com/robfig/cron/v3"
+)
+
+// scheduledJob is a job run on a cron schedule, Run return a short summary
+// of what was done which is kept in the run history
+type scheduledJob struct {
+	Name string
+	Spec string
+	Run  func(c context.Context) (string, error)
+
+	// entry is set once the job is registered in the cron
+	entry cron.EntryID
+}
+
+// jobs declare every scheduled job, the schedule of a job can be overridden
+// with a standard cron expression or a descriptor such as @hourly
+func jobs(
+	borrowService borrowsvc.BorrowService,
+	exportService exportsvc.ExportService,
+	eventService eventsvc.EventService,
+	recommendationService recommendationsvc.RecommendationService,
+) []*scheduledJob {
+	return []*scheduledJob{
+		{
+			Name: constant.Job_DueSoonReminders,
+			Spec: utils.GetString("JOB_DUE_SOON_REMINDERS_CRON", "0 8 * * *"),
+			Run: func(c context.Context) (string, error) {
+				notified, err := borrowService.SendDueReminders(c)
+				return fmt.Sprintf("%d members reminded", notified), err
+			},
+		},
+		{
+			Name: constant.Job_OverdueNotices,
+			Spec: utils.GetString("JOB_OVERDUE_NOTICES_CRON", "0 9 * * *"),
+			Run: func(c context.Context) (string, error) {
+				notified, err := borrowService.SendOverdueNotices(c)
+				return fmt.Sprintf("%d members noticed", notified), err
+			},
+		},
+		{
+			Name: constant.Job_ExportCleanup,
+			Spec: utils.GetString("JOB_EXPORT_CLEANUP_CRON", "@hourly"),
+			Run: func(c context.Context) (string, error) {
+				removed, err := exportService.CleanupExpired(c)
+				return fmt.Sprintf("%d export files removed", removed), err
+			},
+		},
+		{
+			Name: constant.Job_OutboxCleanup,
+			Spec: utils.GetString("JOB_OUTBOX_CLEANUP_CRON", "30 3 * * *"),
+			Run: func(c context.Context) (string, error) {
+				removed, err := eventService.Cleanup(c)
+				return fmt.Sprintf("%d published events removed", removed), err
+			},
+		},
+		{
+			Name: constant.Job_RecommendationRefresh,
+			Spec: utils.GetString("JOB_RECOMMENDATION_REFRESH_CRON", "@hourly"),
+			Run: func(c context.Context) (string, error) {
+				return "recommendations refreshed", recommendationService.Refresh(c)
+			},
+		},
+	}
+}
@@ -0,0 +1,311 @@
+package schedulersvc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/jobrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/service/borrowsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/eventsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/exportsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/recommendationsvc"
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/job"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type SchedulerService interface {
+	// Start run every job on its schedule until c is done,
+	// each run take an advisory lock so only one replica runs a job at a time
+	Start(c context.Context) error
+
+	List(c context.Context) ([]job.Job, error)
+	Runs(c context.Context, filter *job.RunQuery) ([]job.Run, int, error)
+
+	// Trigger start a run right away in background, paused jobs can still be triggered
+	Trigger(c context.Context, name string) (*job.Run, error)
+	// Pause and Resume apply to every replica, scheduled runs of a paused job are skipped
+	Pause(c context.Context, name string) (*job.Job, error)
+	Resume(c context.Context, name string) (*job.Job, error)
+}
+
+type schedulerService struct {
+	Logger    *zap.SugaredLogger
+	TxManager transaction.Manager
+	JobRepo   jobrepo.JobRepository
+
+	cron *cron.Cron
+	jobs []*scheduledJob
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	txManager transaction.Manager,
+	jobRepo jobrepo.JobRepository,
+	borrowService borrowsvc.BorrowService,
+	exportService exportsvc.ExportService,
+	eventService eventsvc.EventService,
+	recommendationService recommendationsvc.RecommendationService,
+) SchedulerService {
+	return &schedulerService{
+		Logger:    logger,
+		TxManager: txManager,
+		JobRepo:   jobRepo,
+		cron:      cron.New(cron.WithLocation(lib.DefaultLocation())),
+		jobs:      jobs(borrowService, exportService, eventService, recommendationService),
+	}
+}
+
+func (s *schedulerService) Start(c context.Context) error {
+	// parse every schedule first so a typo does not leave half the jobs running
+	schedules := make([]cron.Schedule, len(s.jobs))
+	for i, j := range s.jobs {
+		schedule, err := cron.ParseStandard(j.Spec)
+		if err != nil {
+			return fmt.Errorf("invalid schedule of job %s: %w", j.Name, err)
+		}
+		schedules[i] = schedule
+	}
+
+	for i, j := range s.jobs {
+		j.entry = s.cron.Schedule(schedules[i], cron.FuncJob(func() { s.scheduled(j) }))
+	}
+
+	s.cron.Start()
+	go func() {
+		<-c.Done()
+		<-s.cron.Stop().Done()
+	}()
+
+	return nil
+}
+
+func (s *schedulerService) List(c context.Context) ([]job.Job, error) {
+	paused, err := s.JobRepo.FindPaused(c)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get scheduled jobs")
+	}
+
+	lastRuns, err := s.JobRepo.FindLastRuns(c)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get job runs")
+	}
+
+	jobs := make([]job.Job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, s.toJob(j, paused[j.Name], lastRuns))
+	}
+
+	return jobs, nil
+}
+
+func (s *schedulerService) Runs(c context.Context, filter *job.RunQuery) ([]job.Run, int, error) {
+	// validate filter
+	if filter.Sort == "" {
+		filter.Sort = "-started_at"
+	}
+
+	if _, _, err := query.ValidateSort(filter.Sort, jobrepo.SortRunMap); err != nil {
+		return nil, 0, exception.ErrorBadRequest(err.Error())
+	}
+
+	runs, err := s.JobRepo.FindAllRuns(c, filter)
+	if err != nil {
+		return nil, 0, exception.ErrorInternal("Failed to get job runs")
+	}
+
+	total, err := s.JobRepo.CountRuns(c, filter)
+	if err != nil {
+		return nil, 0, exception.ErrorInternal("Failed to get total job runs")
+	}
+
+	return runs, total, nil
+}
+
+func (s *schedulerService) Trigger(c context.Context, name string) (*job.Run, error) {
+	j, err := s.find(name)
+	if err != nil {
+		return nil, err
+	}
+
+	run, unlock, err := s.start(c, j, constant.JobTrigger_Manual)
+	if err != nil {
+		return nil, err
+	}
+
+	if run == nil {
+		return nil, exception.ErrorConflict("Job is already running")
+	}
+
+	go s.finish(j, run, unlock)
+
+	return run, nil
+}
+
+func (s *schedulerService) Pause(c context.Context, name string) (*job.Job, error) {
+	return s.setPaused(c, name, true)
+}
+
+func (s *schedulerService) Resume(c context.Context, name string) (*job.Job, error) {
+	return s.setPaused(c, name, false)
+}
+
+func (s *schedulerService) setPaused(c context.Context, name string, paused bool) (*job.Job, error) {
+	j, err := s.find(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.JobRepo.SetPaused(c, tx, j.Name, paused)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to update job")
+	}
+
+	lastRuns, err := s.JobRepo.FindLastRuns(c)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get job runs")
+	}
+
+	res := s.toJob(j, paused, lastRuns)
+	return &res, nil
+}
+
+func (s *schedulerService) find(name string) (*scheduledJob, error) {
+	for _, j := range s.jobs {
+		if j.Name == name {
+			return j, nil
+		}
+	}
+
+	return nil, exception.ErrorNotFound("Job not found")
+}
+
+func (s *schedulerService) toJob(j *scheduledJob, paused bool, lastRuns map[string]job.Run) job.Job {
+	res := job.Job{
+		Name:     j.Name,
+		Schedule: j.Spec,
+		Paused:   paused,
+	}
+
+	// jobs only have a next run once registered by Start
+	if entry := s.cron.Entry(j.entry); entry.Valid() && !entry.Next.IsZero() && !paused {
+		res.NextRunAt = lib.Pointer(entry.Next)
+	}
+
+	if run, ok := lastRuns[j.Name]; ok {
+		res.LastRun = &run
+	}
+
+	return res
+}
+
+// scheduled run a job on its schedule, the run is skipped when the job is paused
+// or when another replica holds the lock
+func (s *schedulerService) scheduled(j *scheduledJob) {
+	defer lib.Recover()
+
+	c := context.Background()
+
+	paused, err := s.JobRepo.IsPaused(c, j.Name)
+	if err != nil {
+		s.Logger.Errorw("failed to check paused job", "job", j.Name, "error", err)
+		return
+	}
+
+	if paused {
+		return
+	}
+
+	run, unlock, err := s.start(c, j, constant.JobTrigger_Schedule)
+	if err != nil {
+		s.Logger.Errorw("failed to start job", "job", j.Name, "error", err)
+		return
+	}
+
+	if run == nil {
+		s.Logger.Debugw("job is running elsewhere, skipped", "job", j.Name)
+		return
+	}
+
+	s.finish(j, run, unlock)
+}
+
+// start take the lock of a job and record a new run, run is nil when the lock is held elsewhere.
+// unlock must be called once the run is finished
+func (s *schedulerService) start(c context.Context, j *scheduledJob, trigger string) (*job.Run, func(), error) {
+	unlock, ok, err := s.JobRepo.TryLock(c, j.Name)
+	if err != nil {
+		return nil, nil, exception.ErrorInternal("Failed to lock job")
+	}
+
+	if !ok {
+		return nil, nil, nil
+	}
+
+	// nobody else can be running the job while we hold the lock
+	if err := s.JobRepo.FailInterrupted(c, j.Name); err != nil {
+		unlock()
+		return nil, nil, exception.ErrorInternal("Failed to update job runs")
+	}
+
+	run := &job.Run{
+		ID:        uuid.New(),
+		JobName:   j.Name,
+		Trigger:   trigger,
+		Status:    constant.JobStatus_Running,
+		StartedAt: time.Now(),
+	}
+
+	if err := s.JobRepo.CreateRun(c, run); err != nil {
+		unlock()
+		return nil, nil, exception.ErrorInternal("Failed to create job run")
+	}
+
+	return run, unlock, nil
+}
+
+// finish execute a started run and record its outcome
+func (s *schedulerService) finish(j *scheduledJob, run *job.Run, unlock func()) {
+	defer unlock()
+
+	c := context.Background()
+	s.Logger.Infow("job started", "job", j.Name, "trigger", run.Trigger)
+
+	result, err := s.execute(c, j)
+
+	run.Status = constant.JobStatus_Succeeded
+	run.Result = lib.Strptr(result)
+	if err != nil {
+		run.Status = constant.JobStatus_Failed
+		run.Error = lib.Strptr(err.Error())
+		s.Logger.Errorw("job failed", "job", j.Name, "error", err)
+	} else {
+		s.Logger.Infow("job finished", "job", j.Name, "result", result)
+	}
+	run.FinishedAt = lib.TimeNowPtr()
+
+	if err := s.JobRepo.FinishRun(c, run); err != nil {
+		s.Logger.Errorw("failed to finish job run", "job", j.Name, "error", err)
+	}
+}
+
+// execute call the job, a panic fail the run instead of taking the process down
+func (s *schedulerService) execute(c context.Context, j *scheduledJob) (result string, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			lib.PrintStackTrace(p)
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return j.Run(c)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"time"
 
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
 	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
 	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/event"
 	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/user"
 	"github.com/golang-jwt/jwt"
 	"github.com/jackc/pgx/v5"
@@ -57,11 +59,17 @@ func (s *userService) SignUp(c context.Context, api *user.SignUpRequest) (*user.
 		return nil, exception.ErrorBadRequest("Email already exists")
 	}
 
+	cardNumber, err := s.newCardNumber(c)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	userRes = &user.User{
 		SignUpRequest:    *api,
 		Role:             "USER",
 		LastActivityDate: now,
+		CardNumber:       cardNumber,
 	}
 
 	userRes.GenerateID()
@@ -70,8 +78,17 @@ func (s *userService) SignUp(c context.Context, api *user.SignUpRequest) (*user.
 		return nil, exception.ErrorInternal("Failed When hash password")
 	}
 
+	registered, err := event.New(constant.Event_UserRegistered, userRes.ID, userRes.ToEvent())
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed When create user")
+	}
+
 	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
-		return s.UserRepository.Create(c, tx, userRes)
+		if err := s.UserRepository.Create(c, tx, userRes); err != nil {
+			return err
+		}
+
+		return s.OutboxRepo.Add(c, tx, registered)
 	}); err != nil {
 		return nil, exception.ErrorInternal("Failed When create user")
 	}
@@ -0,0 +1,60 @@
+package usersvc
+
+import (
+	"context"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/user"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateCalendarToken issue a new calendar feed token, any previous token stops working
+func (s *userService) CreateCalendarToken(c context.Context, id uuid.UUID) (*user.CalendarToken, error) {
+	if _, err := s.UserRepository.FindByColumn(c, "id", id); err != nil {
+		return nil, exception.ErrorNotFound("User not found")
+	}
+
+	token, err := lib.RandomToken(32)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to generate calendar token")
+	}
+
+	hash := lib.HashToken(token)
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.UserRepository.UpdateCalendarToken(c, tx, id, &hash)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to save calendar token")
+	}
+
+	return &user.CalendarToken{
+		Token: token,
+		URL:   lib.PublicURL("users/me/loans.ics") + "?token=" + token,
+	}, nil
+}
+
+// RevokeCalendarToken disable the calendar feed, the JWT of the user is not affected
+func (s *userService) RevokeCalendarToken(c context.Context, id uuid.UUID) error {
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.UserRepository.UpdateCalendarToken(c, tx, id, nil)
+	}); err != nil {
+		return exception.ErrorInternal("Failed to revoke calendar token")
+	}
+
+	return nil
+}
+
+// FindByCalendarToken return owner of a calendar feed token
+func (s *userService) FindByCalendarToken(c context.Context, token string) (*user.User, error) {
+	if token == "" {
+		return nil, exception.ErrorUnauthorized("Calendar token is required")
+	}
+
+	u, err := s.UserRepository.FindByColumn(c, "calendar_token_hash", lib.HashToken(token))
+	if err != nil {
+		return nil, exception.ErrorUnauthorized("Invalid calendar token")
+	}
+
+	return u, nil
+}
@@ -0,0 +1,88 @@
+package usersvc
+
+import (
+	"context"
+	"io"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/membercard"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/user"
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// attempts to find an unused card number before giving up
+const cardNumberAttempts = 5
+
+// Card write membership card of a user,
+// members registered before cards existed get their number on first request
+func (s *userService) Card(c context.Context, id uuid.UUID, format membercard.Format, w io.Writer) error {
+	u, err := s.UserRepository.FindByColumn(c, "id", id)
+	if err != nil {
+		return exception.ErrorNotFound("User not found")
+	}
+
+	if u.CardNumber == "" {
+		number, err := s.newCardNumber(c)
+		if err != nil {
+			return err
+		}
+
+		if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+			return s.UserRepository.UpdateCardNumber(c, tx, id, number)
+		}); err != nil {
+			return exception.ErrorInternal("Failed to assign card number")
+		}
+		u.CardNumber = number
+	}
+
+	card := &membercard.Card{
+		Library: utils.GetString("APP_NAME", "Library"),
+		Name:    u.Name,
+		Number:  u.CardNumber,
+	}
+	if u.CreatedAt != nil {
+		card.Since = *u.CreatedAt
+	}
+
+	if err := format.Write(w, card); err != nil {
+		s.Logger.Errorw("Failed to write membership card", "format", format.Name, "error", err)
+		return exception.ErrorInternal("Failed to write membership card")
+	}
+
+	return nil
+}
+
+// FindByCardNumber return member holding a card, spaces and dashes of a printed number are ignored
+func (s *userService) FindByCardNumber(c context.Context, number string) (*user.User, error) {
+	number = lib.NormalizeCardNumber(number)
+	if !lib.ValidCardNumber(number) {
+		return nil, exception.ErrorBadRequest("Invalid card number")
+	}
+
+	u, err := s.UserRepository.FindByColumn(c, "card_number", number)
+	if err != nil {
+		return nil, exception.ErrorNotFound("User not found")
+	}
+
+	u.Password = ""
+	return u, nil
+}
+
+// newCardNumber generate a card number not held by any member yet
+func (s *userService) newCardNumber(c context.Context) (string, error) {
+	for range cardNumberAttempts {
+		number, err := lib.NewCardNumber()
+		if err != nil {
+			return "", exception.ErrorInternal("Failed to generate card number")
+		}
+
+		if _, err := s.UserRepository.FindByColumn(c, "card_number", number); err != nil {
+			return number, nil
+		}
+	}
+
+	return "", exception.ErrorInternal("Failed to generate card number")
+}
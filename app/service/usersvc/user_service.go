@@ -2,9 +2,14 @@ package usersvc
 
 import (
 	"context"
+	"io"
+	"time"
 
+	"github.com/dikyayodihamzah/library-management-api/app/repository/outboxrepo"
 	"github.com/dikyayodihamzah/library-management-api/app/repository/userrepo"
 	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/membercard"
 	"github.com/dikyayodihamzah/library-management-api/pkg/model"
 	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/user"
 	"github.com/dikyayodihamzah/library-management-api/pkg/query"
@@ -21,6 +26,17 @@ type UserService interface {
 
 	FindAll(c context.Context, filter *model.QueryParam) ([]user.User, int, error)
 	AssignAdmin(c context.Context, id uuid.UUID) (*user.User, error)
+
+	Profile(c context.Context, id uuid.UUID) (*user.User, error)
+	UpdateProfile(c context.Context, id uuid.UUID, req *user.ProfileRequest) (*user.User, error)
+	Location(c context.Context, id uuid.UUID) (*time.Location, error)
+
+	CreateCalendarToken(c context.Context, id uuid.UUID) (*user.CalendarToken, error)
+	RevokeCalendarToken(c context.Context, id uuid.UUID) error
+	FindByCalendarToken(c context.Context, token string) (*user.User, error)
+
+	Card(c context.Context, id uuid.UUID, format membercard.Format, w io.Writer) error
+	FindByCardNumber(c context.Context, number string) (*user.User, error)
 }
 
 type userService struct {
@@ -28,6 +44,7 @@ type userService struct {
 	Validate       *validator.Validate
 	TxManager      transaction.Manager
 	UserRepository userrepo.UserRepository
+	OutboxRepo     outboxrepo.OutboxRepository
 }
 
 func New(
@@ -35,12 +52,14 @@ func New(
 	validate *validator.Validate,
 	txManager transaction.Manager,
 	userRepository userrepo.UserRepository,
+	outboxRepo outboxrepo.OutboxRepository,
 ) UserService {
 	return &userService{
 		Logger:         logger,
 		Validate:       validate,
 		TxManager:      txManager,
 		UserRepository: userRepository,
+		OutboxRepo:     outboxRepo,
 	}
 }
 
@@ -92,3 +111,60 @@ func (s *userService) AssignAdmin(c context.Context, id uuid.UUID) (*user.User,
 	userData.Password = ""
 	return userData, nil
 }
+
+func (s *userService) Profile(c context.Context, id uuid.UUID) (*user.User, error) {
+	// get user data
+	userData, err := s.UserRepository.FindByColumn(c, "id", id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("User not found")
+	}
+
+	userData.Password = ""
+	return userData, nil
+}
+
+func (s *userService) UpdateProfile(c context.Context, id uuid.UUID, req *user.ProfileRequest) (*user.User, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	if req.Timezone != "" {
+		if _, err := lib.LoadLocation(req.Timezone); err != nil {
+			return nil, exception.ErrorBadRequest("timezone must be a valid IANA timezone, e.g. Asia/Jakarta")
+		}
+	}
+
+	// get user data
+	userData, err := s.UserRepository.FindByColumn(c, "id", id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("User not found")
+	}
+
+	// update user data
+	userData.Name = req.Name
+	userData.Timezone = req.Timezone
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.UserRepository.Update(c, tx, userData)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to update profile")
+	}
+
+	userData.Password = ""
+	return userData, nil
+}
+
+// Location return preferred zone of the user, fallback to default zone
+func (s *userService) Location(c context.Context, id uuid.UUID) (*time.Location, error) {
+	userData, err := s.UserRepository.FindByColumn(c, "id", id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("User not found")
+	}
+
+	if loc, err := lib.LoadLocation(userData.Timezone); err == nil {
+		return loc, nil
+	}
+
+	return lib.DefaultLocation(), nil
+}
@@ -0,0 +1,282 @@
+package worksvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/workrepo"
+	"github.com/dikyayodihamzah/library-management-api/pkg/exception"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/query"
+	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type WorkService interface {
+	CreateSeries(c context.Context, req *book.SeriesRequest) (*book.Series, error)
+	FindAllSeries(c context.Context, filter *model.QueryParam) ([]book.Series, int, error)
+	FindSeriesByID(c context.Context, id uuid.UUID) (*book.SeriesDetail, error)
+	UpdateSeries(c context.Context, id uuid.UUID, req *book.SeriesRequest) (*book.Series, error)
+	DeleteSeries(c context.Context, id uuid.UUID) error
+
+	Create(c context.Context, req *book.WorkRequest) (*book.Work, error)
+	FindAll(c context.Context, filter *model.QueryParam) ([]book.Work, int, error)
+	FindByID(c context.Context, id uuid.UUID) (*book.WorkDetail, error)
+	Update(c context.Context, id uuid.UUID, req *book.WorkRequest) (*book.Work, error)
+	Delete(c context.Context, id uuid.UUID) error
+}
+
+type workService struct {
+	Validate  *validator.Validate
+	TxManager transaction.Manager
+	WorkRepo  workrepo.WorkRepository
+}
+
+func New(
+	validate *validator.Validate,
+	txManager transaction.Manager,
+	workRepo workrepo.WorkRepository,
+) WorkService {
+	return &workService{
+		Validate:  validate,
+		TxManager: txManager,
+		WorkRepo:  workRepo,
+	}
+}
+
+func (s *workService) CreateSeries(c context.Context, req *book.SeriesRequest) (*book.Series, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	// create new series data
+	series := book.Series{SeriesRequest: *req}
+	series.ID = uuid.New()
+	series.CreatedAt = lib.Pointer(time.Now())
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.WorkRepo.CreateSeries(c, tx, &series)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to create series")
+	}
+
+	return &series, nil
+}
+
+func (s *workService) FindAllSeries(c context.Context, filter *model.QueryParam) ([]book.Series, int, error) {
+	// validate filter
+	if filter.Sort == "" {
+		filter.Sort = "name"
+	}
+
+	if _, _, err := query.ValidateSort(filter.Sort, workrepo.SortSeriesMap); err != nil {
+		return nil, 0, exception.ErrorBadRequest(err.Error())
+	}
+
+	series, err := s.WorkRepo.FindAllSeries(c, filter)
+	if err != nil {
+		return nil, 0, exception.ErrorInternal("Failed to get series")
+	}
+
+	total, err := s.WorkRepo.CountSeries(c, filter)
+	if err != nil {
+		return nil, 0, exception.ErrorInternal("Failed to get total series")
+	}
+
+	return series, total, nil
+}
+
+func (s *workService) FindSeriesByID(c context.Context, id uuid.UUID) (*book.SeriesDetail, error) {
+	series, err := s.WorkRepo.FindSeriesByID(c, id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("Series not found")
+	}
+
+	works, err := s.WorkRepo.FindBySeries(c, id)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get works")
+	}
+
+	return &book.SeriesDetail{
+		Series: *series,
+		Works:  works,
+	}, nil
+}
+
+func (s *workService) UpdateSeries(c context.Context, id uuid.UUID, req *book.SeriesRequest) (*book.Series, error) {
+	// validate request
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, exception.ErrorBadRequest(err.Error())
+	}
+
+	series, err := s.WorkRepo.FindSeriesByID(c, id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("Series not found")
+	}
+
+	// update series data
+	series.SeriesRequest = *req
+	series.UpdatedAt = lib.Pointer(time.Now())
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.WorkRepo.UpdateSeries(c, tx, series)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to update series")
+	}
+
+	return series, nil
+}
+
+// DeleteSeries remove series, its works are kept without series
+func (s *workService) DeleteSeries(c context.Context, id uuid.UUID) error {
+	if _, err := s.WorkRepo.FindSeriesByID(c, id); err != nil {
+		return exception.ErrorNotFound("Series not found")
+	}
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.WorkRepo.DeleteSeries(c, tx, id)
+	}); err != nil {
+		return exception.ErrorInternal("Failed to delete series")
+	}
+
+	return nil
+}
+
+// validateWork validate request and check volume number is free in the series
+func (s *workService) validateWork(c context.Context, id uuid.UUID, req *book.WorkRequest) error {
+	if err := s.Validate.Struct(req); err != nil {
+		return exception.ErrorBadRequest(err.Error())
+	}
+
+	if req.SeriesID == nil {
+		if req.VolumeNumber != nil {
+			return exception.ErrorBadRequest("volume_number requires series_id")
+		}
+		return nil
+	}
+
+	if _, err := s.WorkRepo.FindSeriesByID(c, *req.SeriesID); err != nil {
+		return exception.ErrorBadRequest("Series not found")
+	}
+
+	if req.VolumeNumber == nil {
+		return nil
+	}
+
+	works, err := s.WorkRepo.FindBySeries(c, *req.SeriesID)
+	if err != nil {
+		return exception.ErrorInternal("Failed to get works")
+	}
+
+	for _, w := range works {
+		if w.ID != id && w.VolumeNumber != nil && *w.VolumeNumber == *req.VolumeNumber {
+			return exception.ErrorConflict("Volume number is already used in the series")
+		}
+	}
+
+	return nil
+}
+
+func (s *workService) Create(c context.Context, req *book.WorkRequest) (*book.Work, error) {
+	// validate request
+	if err := s.validateWork(c, uuid.Nil, req); err != nil {
+		return nil, err
+	}
+
+	// create new work data
+	w := book.Work{WorkRequest: *req}
+	w.ID = uuid.New()
+	w.CreatedAt = lib.Pointer(time.Now())
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.WorkRepo.Create(c, tx, &w)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to create work")
+	}
+
+	return &w, nil
+}
+
+func (s *workService) FindAll(c context.Context, filter *model.QueryParam) ([]book.Work, int, error) {
+	// validate filter
+	if filter.Sort == "" {
+		filter.Sort = "title"
+	}
+
+	if _, _, err := query.ValidateSort(filter.Sort, workrepo.SortWorkMap); err != nil {
+		return nil, 0, exception.ErrorBadRequest(err.Error())
+	}
+
+	works, err := s.WorkRepo.FindAll(c, filter)
+	if err != nil {
+		return nil, 0, exception.ErrorInternal("Failed to get works")
+	}
+
+	total, err := s.WorkRepo.Count(c, filter)
+	if err != nil {
+		return nil, 0, exception.ErrorInternal("Failed to get total works")
+	}
+
+	return works, total, nil
+}
+
+func (s *workService) FindByID(c context.Context, id uuid.UUID) (*book.WorkDetail, error) {
+	w, err := s.WorkRepo.FindByID(c, id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("Work not found")
+	}
+
+	editions, err := s.WorkRepo.FindEditions(c, id)
+	if err != nil {
+		return nil, exception.ErrorInternal("Failed to get editions")
+	}
+
+	return &book.WorkDetail{
+		Work:     *w,
+		Editions: editions,
+	}, nil
+}
+
+func (s *workService) Update(c context.Context, id uuid.UUID, req *book.WorkRequest) (*book.Work, error) {
+	w, err := s.WorkRepo.FindByID(c, id)
+	if err != nil {
+		return nil, exception.ErrorNotFound("Work not found")
+	}
+
+	// validate request
+	if err := s.validateWork(c, id, req); err != nil {
+		return nil, err
+	}
+
+	// update work data
+	w.WorkRequest = *req
+	w.UpdatedAt = lib.Pointer(time.Now())
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.WorkRepo.Update(c, tx, w)
+	}); err != nil {
+		return nil, exception.ErrorInternal("Failed to update work")
+	}
+
+	return w, nil
+}
+
+// Delete remove work, its editions are kept as standalone books
+func (s *workService) Delete(c context.Context, id uuid.UUID) error {
+	if _, err := s.WorkRepo.FindByID(c, id); err != nil {
+		return exception.ErrorNotFound("Work not found")
+	}
+
+	if err := s.TxManager.WithTx(c, func(tx pgx.Tx) error {
+		return s.WorkRepo.Delete(c, tx, id)
+	}); err != nil {
+		return exception.ErrorInternal("Failed to delete work")
+	}
+
+	return nil
+}
@@ -0,0 +1,352 @@
+package sipserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/user"
+	"github.com/dikyayodihamzah/library-management-api/pkg/sip2"
+	"github.com/google/uuid"
+)
+
+const (
+	// language of patron messages, 000 is unknown
+	language = "000"
+
+	// circulation status of item information
+	statusAvailable = "03"
+	statusCharged   = "04"
+
+	// patron status flags, a blank flag means the privilege is allowed
+	patronAllowed = "              "
+	patronDenied  = "YYYY          "
+)
+
+// supported messages in the order of the BX field of ACS status
+var supportedMessages = strings.Join([]string{
+	"Y", // patron status
+	"Y", // checkout
+	"Y", // checkin
+	"N", // block patron
+	"Y", // SC/ACS status
+	"Y", // request SC/ACS resend
+	"Y", // login
+	"Y", // patron information
+	"N", // end patron session
+	"N", // fee paid
+	"Y", // item information
+	"N", // item status update
+	"N", // patron enable
+	"N", // hold
+	"N", // renew
+	"N", // renew all
+}, "")
+
+func (s *Server) login(sess *session, req *sip2.Message) *sip2.Message {
+	ok := subtle.ConstantTimeCompare([]byte(req.Get("CN")), []byte(username)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(req.Get("CO")), []byte(password)) == 1
+
+	sess.loggedIn = ok || username == ""
+	return sip2.NewMessage(sip2.LoginResponse, boolDigit(sess.loggedIn))
+}
+
+func (s *Server) status() *sip2.Message {
+	return sip2.NewMessage(sip2.ACSStatus,
+		"Y",   // online
+		"Y",   // checkin ok
+		"Y",   // checkout ok
+		"N",   // renewal policy
+		"N",   // status update ok
+		"N",   // offline ok
+		"030", // timeout
+		"003", // retries
+		sip2.Date(now()),
+		"2.00",
+	).
+		Add("AO", institution).
+		Add("AM", libraryName).
+		Add("BX", supportedMessages)
+}
+
+func (s *Server) patronStatus(c context.Context, req *sip2.Message) *sip2.Message {
+	u, valid, validPassword := s.findPatron(c, req)
+
+	res := sip2.NewMessage(sip2.PatronStatusResponse, patronFlags(valid && validPassword), language, sip2.Date(now())).
+		Add("AO", req.Get("AO")).
+		Add("AA", req.Get("AA"))
+
+	if u != nil {
+		res.Add("AE", u.Name)
+	}
+
+	return res.Add("BL", sip2.Bool(valid)).Add("CQ", sip2.Bool(validPassword))
+}
+
+func (s *Server) patronInformation(c context.Context, req *sip2.Message) *sip2.Message {
+	u, valid, validPassword := s.findPatron(c, req)
+
+	var loans []book.BorrowDTO
+	if u != nil {
+		loans, _ = s.BorrowRepo.FindAll(c, &book.BorrowQuery{UserID: u.ID, Status: "BORROWED"})
+	}
+
+	overdue := make([]book.BorrowDTO, 0)
+	for _, loan := range loans {
+		if loan.DueDate.Before(time.Now()) {
+			overdue = append(overdue, loan)
+		}
+	}
+
+	res := sip2.NewMessage(sip2.PatronInformationResp,
+		patronFlags(valid && validPassword),
+		language,
+		sip2.Date(now()),
+		sip2.Count(0), // hold items
+		sip2.Count(len(overdue)),
+		sip2.Count(len(loans)),
+		sip2.Count(0), // fine items
+		sip2.Count(0), // recall items
+		sip2.Count(0), // unavailable holds
+	).
+		Add("AO", req.Get("AO")).
+		Add("AA", req.Get("AA"))
+
+	if u != nil {
+		res.Add("AE", u.Name).Add("BE", u.Email)
+	}
+	res.Add("BL", sip2.Bool(valid)).Add("CQ", sip2.Bool(validPassword))
+
+	// summary flags which item list is requested, position 2 is overdue and 3 is charged items
+	summary := ""
+	if len(req.Fixed) > 2 {
+		summary = req.Fixed[2]
+	}
+
+	if len(summary) > 1 && summary[1] == 'Y' {
+		for _, loan := range overdue {
+			res.Add("AT", loan.BookID.String())
+		}
+	}
+
+	if len(summary) > 2 && summary[2] == 'Y' {
+		for _, loan := range loans {
+			res.Add("AU", loan.BookID.String())
+		}
+	}
+
+	return res
+}
+
+func (s *Server) checkout(c context.Context, req *sip2.Message) *sip2.Message {
+	res := func(ok bool, b *book.Book, dueDate string, message string) *sip2.Message {
+		m := sip2.NewMessage(sip2.CheckoutResponse, boolDigit(ok), "N", "N", sip2.Bool(ok), sip2.Date(now())).
+			Add("AO", req.Get("AO")).
+			Add("AA", req.Get("AA")).
+			Add("AB", req.Get("AB"))
+
+		if b != nil {
+			m.Add("AJ", b.Title)
+		}
+
+		if dueDate != "" {
+			m.Add("AH", dueDate)
+		}
+
+		return m.Add("AF", message)
+	}
+
+	u, valid, validPassword := s.findPatron(c, req)
+	if !valid {
+		return res(false, nil, "", "Patron not found")
+	}
+
+	if !validPassword {
+		return res(false, nil, "", "Invalid patron password")
+	}
+
+	b := s.findItem(c, req.Get("AB"))
+	if b == nil {
+		return res(false, nil, "", "Item not found")
+	}
+
+	// kiosks may ask for a due date, otherwise the default loan period is used
+	dueDate := time.Now().AddDate(0, 0, loanDays)
+	if len(req.Fixed) > 3 {
+		if t, err := time.ParseInLocation(sip2.DateLayout, req.Fixed[3], lib.DefaultLocation()); err == nil {
+			dueDate = t
+		}
+	}
+
+	if _, err := s.BorrowService.Borrow(c, &book.BorrowRequest{
+		BookIDs: []uuid.UUID{b.ID},
+		UserID:  u.ID,
+		DueDate: dueDate,
+	}); err != nil {
+		return res(false, b, "", err.Error())
+	}
+
+	return res(true, b, sip2.Date(dueDate.In(lib.DefaultLocation())), "Checked out")
+}
+
+// checkin return the open loan of the item. Books have no per-copy records, so when
+// several members hold the same title the kiosk must name the patron in AA
+func (s *Server) checkin(c context.Context, req *sip2.Message) *sip2.Message {
+	res := func(ok bool, b *book.Book, patron, message string) *sip2.Message {
+		m := sip2.NewMessage(sip2.CheckinResponse, boolDigit(ok), sip2.Bool(ok), "N", "N", sip2.Date(now())).
+			Add("AO", req.Get("AO")).
+			Add("AB", req.Get("AB"))
+
+		if b != nil {
+			m.Add("AQ", b.CallNumber).Add("AJ", b.Title)
+		}
+
+		if patron != "" {
+			m.Add("AA", patron)
+		}
+
+		return m.Add("AF", message)
+	}
+
+	b := s.findItem(c, req.Get("AB"))
+	if b == nil {
+		return res(false, nil, "", "Item not found")
+	}
+
+	filter := &book.BorrowQuery{
+		QueryParam: model.QueryParam{Sort: "due_date"},
+		BookID:     b.ID,
+		Status:     "BORROWED",
+	}
+
+	if req.Get("AA") != "" {
+		u, valid, _ := s.findPatron(c, req)
+		if !valid {
+			return res(false, b, "", "Patron not found")
+		}
+		filter.UserID = u.ID
+	}
+
+	loans, err := s.BorrowRepo.FindAll(c, filter)
+	if err != nil || len(loans) == 0 {
+		return res(false, b, "", "Item is not checked out")
+	}
+
+	if len(loans) > 1 {
+		return res(false, b, "", "Item is checked out by several patrons, patron is required")
+	}
+
+	loan := loans[0]
+	if err := s.BorrowService.Return(c, &book.BorrowRequest{
+		BookIDs: []uuid.UUID{b.ID},
+		UserID:  loan.UserID,
+	}); err != nil {
+		return res(false, b, "", err.Error())
+	}
+
+	patron := loan.UserID.String()
+	if u, err := s.UserRepo.FindByColumn(c, "id", loan.UserID); err == nil {
+		patron = u.Email
+	}
+
+	return res(true, b, patron, "Checked in")
+}
+
+func (s *Server) itemInformation(c context.Context, req *sip2.Message) *sip2.Message {
+	b := s.findItem(c, req.Get("AB"))
+	if b == nil {
+		return sip2.NewMessage(sip2.ItemInformationResponse, "01", "00", "01", sip2.Date(now())).
+			Add("AB", req.Get("AB")).
+			Add("AJ", "").
+			Add("AF", "Item not found")
+	}
+
+	status := statusAvailable
+	if b.AvailableCopies == 0 {
+		status = statusCharged
+	}
+
+	res := sip2.NewMessage(sip2.ItemInformationResponse, status, "02", "01", sip2.Date(now())).
+		Add("AB", req.Get("AB")).
+		Add("AJ", b.Title)
+
+	// earliest return of a charged item
+	if status == statusCharged {
+		if dueDates, err := s.BorrowRepo.FindOpenDueDates(c, b.ID); err == nil && len(dueDates) > 0 {
+			res.Add("AH", sip2.Date(dueDates[0].In(lib.DefaultLocation())))
+		}
+	}
+
+	return res.Add("AQ", b.CallNumber)
+}
+
+// findPatron look patron up by card number or email, a missing password is only
+// accepted when SIP2_ALLOW_NO_PIN is set
+func (s *Server) findPatron(c context.Context, req *sip2.Message) (u *user.User, valid, validPassword bool) {
+	identifier := strings.TrimSpace(req.Get("AA"))
+	if identifier == "" {
+		return nil, false, false
+	}
+
+	// kiosks scan the membership card, members may also type their email
+	column := "email"
+	if number := lib.NormalizeCardNumber(identifier); lib.ValidCardNumber(number) {
+		column, identifier = "card_number", number
+	}
+
+	u, err := s.UserRepo.FindByColumn(c, column, identifier)
+	if err != nil || u == nil {
+		return nil, false, false
+	}
+
+	validPassword = allowNoPIN
+	if pwd := req.Get("AD"); pwd != "" {
+		validPassword = lib.PasswordCompare(u.Password, pwd)
+	}
+
+	return u, true, validPassword
+}
+
+// findItem look item up by book ID or ISBN
+func (s *Server) findItem(c context.Context, identifier string) *book.Book {
+	identifier = strings.TrimSpace(identifier)
+
+	if id, err := uuid.Parse(identifier); err == nil {
+		if b, err := s.BookRepo.FindByID(c, id); err == nil {
+			return b
+		}
+		return nil
+	}
+
+	if isbn := lib.NormalizeISBN(identifier); isbn != "" {
+		if b, err := s.BookRepo.FindByISBN(c, isbn); err == nil {
+			return b
+		}
+	}
+
+	return nil
+}
+
+func patronFlags(allowed bool) string {
+	if allowed {
+		return patronAllowed
+	}
+
+	return patronDenied
+}
+
+func boolDigit(v bool) string {
+	if v {
+		return "1"
+	}
+
+	return "0"
+}
+
+func now() time.Time {
+	return time.Now().In(lib.DefaultLocation())
+}
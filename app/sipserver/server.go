@@ -0,0 +1,203 @@
+// Package sipserver serve SIP2 self-check kiosks over TCP next to the REST API
+package sipserver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/bookrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/borrowrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/userrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/service/borrowsvc"
+	"github.com/dikyayodihamzah/library-management-api/pkg/sip2"
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+	"go.uber.org/zap"
+)
+
+var (
+	// kiosk credentials checked by login, login is optional when no username is set
+	username = utils.GetString("SIP2_USERNAME")
+	password = utils.GetString("SIP2_PASSWORD")
+
+	// patrons must enter their PIN unless kiosks are explicitly trusted to skip it
+	allowNoPIN = utils.GetBool("SIP2_ALLOW_NO_PIN")
+
+	institution = utils.GetString("SIP2_INSTITUTION", "library")
+	libraryName = utils.GetString("APP_NAME", "Library")
+	loanDays    = utils.GetInt("SIP2_LOAN_DAYS", 14)
+	idleTimeout = time.Duration(utils.GetInt("SIP2_IDLE_SECONDS", 600)) * time.Second
+)
+
+type Server struct {
+	Logger        *zap.SugaredLogger
+	BorrowService borrowsvc.BorrowService
+	UserRepo      userrepo.UserRepository
+	BookRepo      bookrepo.BookRepository
+	BorrowRepo    borrowrepo.BorrowRepository
+
+	mu       sync.Mutex
+	listener net.Listener
+}
+
+func New(
+	logger *zap.SugaredLogger,
+	borrowService borrowsvc.BorrowService,
+	userRepo userrepo.UserRepository,
+	bookRepo bookrepo.BookRepository,
+	borrowRepo borrowrepo.BorrowRepository,
+) *Server {
+	return &Server{
+		Logger:        logger,
+		BorrowService: borrowService,
+		UserRepo:      userRepo,
+		BookRepo:      bookRepo,
+		BorrowRepo:    borrowRepo,
+	}
+}
+
+// session is the state of one kiosk connection
+type session struct {
+	loggedIn     bool
+	lastSequence int
+	lastResponse string
+}
+
+// ListenAndServe accept kiosk connections on addr until Close is called
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(l)
+}
+
+// Serve accept kiosk connections on l, every connection is handled in its own goroutine
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	s.listener = l
+	s.mu.Unlock()
+
+	s.Logger.Infow("SIP2 server started", "address", l.Addr().String())
+	for {
+		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		go s.handleConn(conn)
+	}
+}
+
+// Close stop accepting connections, open connections end at their next idle timeout
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+
+	return s.listener.Close()
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	sess := &session{loggedIn: username == "", lastSequence: -1}
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(idleTimeout))
+
+		// messages end with a carriage return, some kiosks add a line feed
+		line, err := reader.ReadString('\r')
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.Logger.Warnw("SIP2 connection closed", "remote", conn.RemoteAddr().String(), "error", err)
+			}
+			return
+		}
+
+		line = strings.TrimLeft(line, "\n")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		res, ok := s.handle(context.Background(), sess, line)
+		if !ok {
+			s.Logger.Warnw("SIP2 request before login", "remote", conn.RemoteAddr().String())
+			return
+		}
+
+		if _, err := io.WriteString(conn, res); err != nil {
+			s.Logger.Warnw("Failed to write SIP2 response", "remote", conn.RemoteAddr().String(), "error", err)
+			return
+		}
+	}
+}
+
+// handle answer a single raw message, ok is false when the connection must be closed
+func (s *Server) handle(c context.Context, sess *session, line string) (string, bool) {
+	req, err := sip2.Decode(line)
+	if errors.Is(err, sip2.ErrChecksum) {
+		return sip2.NewMessage(sip2.RequestSCResend).Encode(), true
+	}
+
+	if err != nil {
+		s.Logger.Warnw("Invalid SIP2 message", "error", err)
+		return sip2.NewMessage(sip2.RequestSCResend).Encode(), true
+	}
+
+	if req.ID == sip2.RequestACSResend {
+		// nothing to resend yet, ask the kiosk to send its request again
+		if sess.lastResponse == "" {
+			return sip2.NewMessage(sip2.RequestSCResend).Encode(), true
+		}
+		return sess.lastResponse, true
+	}
+
+	// a kiosk retransmit with the same sequence when our response was lost,
+	// answer it again instead of running e.g. a checkout twice
+	if req.Sequence >= 0 && req.Sequence == sess.lastSequence && sess.lastResponse != "" {
+		return sess.lastResponse, true
+	}
+
+	if req.ID != sip2.LoginRequest && req.ID != sip2.SCStatus && !sess.loggedIn {
+		return "", false
+	}
+
+	var res *sip2.Message
+	switch req.ID {
+	case sip2.LoginRequest:
+		res = s.login(sess, req)
+	case sip2.SCStatus:
+		res = s.status()
+	case sip2.PatronStatusRequest:
+		res = s.patronStatus(c, req)
+	case sip2.PatronInformationRequest:
+		res = s.patronInformation(c, req)
+	case sip2.CheckoutRequest:
+		res = s.checkout(c, req)
+	case sip2.CheckinRequest:
+		res = s.checkin(c, req)
+	case sip2.ItemInformationRequest:
+		res = s.itemInformation(c, req)
+	}
+
+	// responses echo the sequence number of the request
+	res.Sequence = req.Sequence
+	sess.lastSequence = req.Sequence
+	sess.lastResponse = res.Encode()
+	return sess.lastResponse, true
+}
@@ -0,0 +1,334 @@
+package sipserver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/app/repository/bookrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/borrowrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/userrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/service/borrowsvc"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/user"
+	"github.com/dikyayodihamzah/library-management-api/pkg/sip2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// library is the in-memory state shared by the fake repositories and borrow service
+type library struct {
+	mu        sync.Mutex
+	users     []user.User
+	books     []book.Book
+	loans     []book.BorrowDTO
+	checkouts int
+}
+
+type fakeUserRepo struct {
+	userrepo.UserRepository
+	state *library
+}
+
+func (r *fakeUserRepo) FindByColumn(c context.Context, column string, value interface{}) (*user.User, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	for _, u := range r.state.users {
+		if (column == "email" && u.Email == value) ||
+			(column == "card_number" && u.CardNumber == value) ||
+			(column == "id" && u.ID == value) {
+			return &u, nil
+		}
+	}
+
+	return nil, errors.New("user not found")
+}
+
+type fakeBookRepo struct {
+	bookrepo.BookRepository
+	state *library
+}
+
+func (r *fakeBookRepo) FindByID(c context.Context, id uuid.UUID) (*book.Book, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	for _, b := range r.state.books {
+		if b.ID == id {
+			return &b, nil
+		}
+	}
+
+	return nil, errors.New("book not found")
+}
+
+func (r *fakeBookRepo) FindByISBN(c context.Context, isbn string) (*book.Book, error) {
+	return nil, errors.New("book not found")
+}
+
+type fakeBorrowRepo struct {
+	borrowrepo.BorrowRepository
+	state *library
+}
+
+func (r *fakeBorrowRepo) FindAll(c context.Context, filter *book.BorrowQuery) ([]book.BorrowDTO, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	loans := make([]book.BorrowDTO, 0)
+	for _, loan := range r.state.loans {
+		if (filter.BookID == uuid.Nil || loan.BookID == filter.BookID) &&
+			(filter.UserID == uuid.Nil || loan.UserID == filter.UserID) &&
+			(filter.Status == "" || loan.Status == filter.Status) {
+			loans = append(loans, loan)
+		}
+	}
+
+	return loans, nil
+}
+
+type fakeBorrowService struct {
+	borrowsvc.BorrowService
+	state *library
+}
+
+func (s *fakeBorrowService) Borrow(c context.Context, req *book.BorrowRequest) (*book.BorrowUserResponse, error) {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	for _, id := range req.BookIDs {
+		loan := book.BorrowDTO{}
+		loan.ID = uuid.New()
+		loan.BookID = id
+		loan.UserID = req.UserID
+		loan.DueDate = req.DueDate
+		loan.Status = "BORROWED"
+		s.state.loans = append(s.state.loans, loan)
+	}
+	s.state.checkouts++
+
+	return &book.BorrowUserResponse{DueDate: req.DueDate}, nil
+}
+
+func (s *fakeBorrowService) Return(c context.Context, req *book.BorrowRequest) error {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	for i, loan := range s.state.loans {
+		if loan.UserID == req.UserID && loan.BookID == req.BookIDs[0] && loan.Status == "BORROWED" {
+			s.state.loans[i].Status = "RETURNED"
+			return nil
+		}
+	}
+
+	return errors.New("loan not found")
+}
+
+// client is a scripted kiosk, every request carries the next sequence number
+type client struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+	seq    int
+}
+
+func dial(t *testing.T, addr string) *client {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &client{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// send write a request with sequence seq and return the raw response
+func (c *client) sendSeq(req *sip2.Message, seq int) string {
+	c.t.Helper()
+
+	req.Sequence = seq
+	return c.sendRaw(req.Encode())
+}
+
+func (c *client) send(req *sip2.Message) string {
+	c.t.Helper()
+
+	c.seq = (c.seq + 1) % 10
+	return c.sendSeq(req, c.seq)
+}
+
+func (c *client) sendRaw(raw string) string {
+	c.t.Helper()
+
+	c.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := c.conn.Write([]byte(raw)); err != nil {
+		c.t.Fatal(err)
+	}
+
+	res, err := c.reader.ReadString('\r')
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	return res
+}
+
+func expect(t *testing.T, res, prefix string, fields ...string) {
+	t.Helper()
+
+	if !strings.HasPrefix(res, prefix) {
+		t.Errorf("response %q must start with %q", res, prefix)
+	}
+
+	for _, f := range fields {
+		if !strings.Contains(res, f) {
+			t.Errorf("response %q must contain %q", res, f)
+		}
+	}
+}
+
+func TestServerScriptedKiosk(t *testing.T) {
+	username, password = "kiosk", "secret"
+	t.Cleanup(func() { username, password = "", "" })
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("1234"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cardNumber, err := lib.NewCardNumber()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ann := user.User{CardNumber: cardNumber}
+	ann.ID = uuid.New()
+	ann.Name = "Ann"
+	ann.Email = "ann@example.com"
+	ann.Password = string(hash)
+
+	bob := user.User{}
+	bob.ID = uuid.New()
+	bob.Name = "Bob"
+	bob.Email = "bob@example.com"
+	bob.Password = string(hash)
+
+	dune := book.Book{}
+	dune.ID = uuid.New()
+	dune.Title = "Dune"
+	dune.CallNumber = "PS3558.E63 D8"
+	dune.TotalCopies = 2
+	dune.AvailableCopies = 2
+
+	state := &library{users: []user.User{ann, bob}, books: []book.Book{dune}}
+	srv := New(zap.NewNop().Sugar(), &fakeBorrowService{state: state}, &fakeUserRepo{state: state}, &fakeBookRepo{state: state}, &fakeBorrowRepo{state: state})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+
+	date := sip2.Date(time.Now())
+	blankDate := strings.Repeat(" ", 18)
+	itemID := dune.ID.String()
+
+	kiosk := dial(t, l.Addr().String())
+
+	// resend before any response ask the kiosk to send its request again
+	if res := kiosk.send(sip2.NewMessage(sip2.RequestACSResend)); res != "96\r" {
+		t.Fatalf("resend before any response = %q, want 96", res)
+	}
+
+	// login
+	res := kiosk.send(sip2.NewMessage(sip2.LoginRequest, "0", "0").Add("CN", "kiosk").Add("CO", "wrong").Add("CP", "desk"))
+	expect(t, res, "940")
+
+	res = kiosk.send(sip2.NewMessage(sip2.LoginRequest, "0", "0").Add("CN", "kiosk").Add("CO", "secret").Add("CP", "desk"))
+	expect(t, res, "941AY")
+
+	// patron information, the PIN is required
+	res = kiosk.send(sip2.NewMessage(sip2.PatronInformationRequest, "000", date, "          ").
+		Add("AO", "library").Add("AA", ann.CardNumber))
+	expect(t, res, "64YYYY", "|AEAnn|", "|BLY|", "|CQN|")
+
+	res = kiosk.send(sip2.NewMessage(sip2.PatronInformationRequest, "000", date, "          ").
+		Add("AO", "library").Add("AA", ann.CardNumber).Add("AD", "1234"))
+	expect(t, res, "64              000", "|AEAnn|", "|BEann@example.com|", "|BLY|", "|CQY|")
+
+	// checkout without PIN is refused
+	res = kiosk.send(sip2.NewMessage(sip2.CheckoutRequest, "N", "N", date, blankDate).
+		Add("AO", "library").Add("AA", ann.Email).Add("AB", itemID))
+	expect(t, res, "120NNN", "|AFInvalid patron password|")
+
+	// checkout
+	checkout := sip2.NewMessage(sip2.CheckoutRequest, "N", "N", date, blankDate).
+		Add("AO", "library").Add("AA", ann.Email).Add("AB", itemID).Add("AD", "1234")
+	res = kiosk.send(checkout)
+	expect(t, res, "121NNY", "|AAann@example.com|", "|AB"+itemID+"|", "|AJDune|", "|AH", "|AFChecked out|")
+
+	// a retransmit with the same sequence get the same response without a second checkout
+	if again := kiosk.sendSeq(checkout, kiosk.seq); again != res {
+		t.Errorf("retransmitted checkout = %q, want %q", again, res)
+	}
+
+	if state.checkouts != 1 {
+		t.Errorf("checkouts = %d, want 1", state.checkouts)
+	}
+
+	// resend the last response
+	if again := kiosk.send(sip2.NewMessage(sip2.RequestACSResend)); again != res {
+		t.Errorf("resend = %q, want %q", again, res)
+	}
+
+	// charged items of the patron
+	res = kiosk.send(sip2.NewMessage(sip2.PatronInformationRequest, "000", date, "  Y       ").
+		Add("AO", "library").Add("AA", ann.CardNumber).Add("AD", "1234"))
+	expect(t, res, "64              000"+date+"000000000001", "|AU"+itemID+"|")
+
+	// another member borrow the second copy, the item alone no longer identify the loan
+	res = kiosk.send(sip2.NewMessage(sip2.CheckoutRequest, "N", "N", date, blankDate).
+		Add("AO", "library").Add("AA", bob.Email).Add("AB", itemID).Add("AD", "1234"))
+	expect(t, res, "121NNY")
+
+	res = kiosk.send(sip2.NewMessage(sip2.CheckinRequest, "N", date, date).
+		Add("AO", "library").Add("AB", itemID))
+	expect(t, res, "100NNN", "|AFItem is checked out by several patrons, patron is required|")
+
+	// checkin
+	res = kiosk.send(sip2.NewMessage(sip2.CheckinRequest, "N", date, date).
+		Add("AO", "library").Add("AB", itemID).Add("AA", bob.Email))
+	expect(t, res, "101YNN", "|AQPS3558.E63 D8|", "|AJDune|", "|AAbob@example.com|", "|AFChecked in|")
+
+	res = kiosk.send(sip2.NewMessage(sip2.CheckinRequest, "N", date, date).
+		Add("AO", "library").Add("AB", itemID))
+	expect(t, res, "101YNN", "|AAann@example.com|", "|AFChecked in|")
+
+	for _, loan := range state.loans {
+		if loan.Status != "RETURNED" {
+			t.Errorf("loan of %s is %s, want RETURNED", loan.UserID, loan.Status)
+		}
+	}
+
+	// corrupted checksum ask for a resend
+	raw := sip2.NewMessage(sip2.CheckinRequest, "N", date, date).Add("AB", itemID)
+	raw.Sequence = 1
+	encoded := raw.Encode()
+	corrupted := "0"
+	if encoded[len(encoded)-2] == '0' {
+		corrupted = "1"
+	}
+
+	if res := kiosk.sendRaw(encoded[:len(encoded)-2] + corrupted + "\r"); res != "96\r" {
+		t.Errorf("corrupted message = %q, want 96", res)
+	}
+}
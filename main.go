@@ -1,19 +1,46 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/dikyayodihamzah/library-management-api/app/controller"
 	"github.com/dikyayodihamzah/library-management-api/app/repository/bookrepo"
 	"github.com/dikyayodihamzah/library-management-api/app/repository/borrowrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/exportrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/jobrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/locationrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/notificationrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/outboxrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/readinglistrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/recommendationrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/reportrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/reviewrepo"
 	"github.com/dikyayodihamzah/library-management-api/app/repository/userrepo"
+	"github.com/dikyayodihamzah/library-management-api/app/repository/workrepo"
 	"github.com/dikyayodihamzah/library-management-api/app/service/booksvc"
 	"github.com/dikyayodihamzah/library-management-api/app/service/borrowsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/eventsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/exportsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/feedsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/locationsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/notificationsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/oaisvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/readinglistsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/recommendationsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/reportsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/reviewsvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/schedulersvc"
 	"github.com/dikyayodihamzah/library-management-api/app/service/usersvc"
+	"github.com/dikyayodihamzah/library-management-api/app/service/worksvc"
+	"github.com/dikyayodihamzah/library-management-api/app/sipserver"
 	"github.com/dikyayodihamzah/library-management-api/pkg/config/dbconfig"
 	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+	"github.com/dikyayodihamzah/library-management-api/pkg/mail"
 	"github.com/dikyayodihamzah/library-management-api/pkg/transaction"
 	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
 	"github.com/go-playground/validator/v10"
@@ -65,15 +92,106 @@ func main() {
 	userRepository := userrepo.New(logger, postgreDB)
 	bookRepository := bookrepo.New(logger, postgreDB)
 	borrowRepository := borrowrepo.New(logger, postgreDB)
+	reportRepository := reportrepo.New(logger, postgreDB)
+	exportRepository := exportrepo.New(logger, postgreDB)
+	reviewRepository := reviewrepo.New(logger, postgreDB)
+	readingListRepository := readinglistrepo.New(logger, postgreDB)
+	recommendationRepository := recommendationrepo.New(logger, postgreDB)
+	workRepository := workrepo.New(logger, postgreDB)
+	locationRepository := locationrepo.New(logger, postgreDB)
+	notificationRepository := notificationrepo.New(logger, postgreDB)
+	jobRepository := jobrepo.New(logger, postgreDB)
+	outboxRepository := outboxrepo.New(logger, postgreDB)
+
+	// email is only logged when no SMTP server is configured
+	var mailSender mail.Sender = &mail.LogSender{Logger: logger}
+	if host := utils.GetString("SMTP_HOST"); host != "" {
+		mailSender = mail.NewSMTPSender(mail.SMTPConfig{
+			Host:        host,
+			Port:        utils.GetInt("SMTP_PORT", 587),
+			Username:    utils.GetString("SMTP_USERNAME"),
+			Password:    utils.GetString("SMTP_PASSWORD"),
+			From:        utils.GetString("SMTP_FROM", "library@localhost"),
+			ImplicitTLS: utils.GetBool("SMTP_IMPLICIT_TLS"),
+		})
+	}
+
+	// domain events stay in the outbox until a broker is configured
+	var eventPublisher lib.Publisher
+	if brokers := utils.GetString("KAFKA_BROKERS"); brokers != "" {
+		eventPublisher = lib.NewKafkaPublisher(lib.KafkaConfig{
+			Brokers:  strings.Split(brokers, ","),
+			Topic:    utils.GetString("KAFKA_EVENTS_TOPIC", "library.events"),
+			ClientID: utils.GetString("APP_NAME", "library"),
+		})
+	}
 
 	// service
 	validate := validator.New()
-	userService := usersvc.New(logger, validate, txManager, userRepository)
-	bookService := booksvc.New(validate, txManager, bookRepository)
-	borrowService := borrowsvc.New(logger, validate, txManager, userRepository, bookRepository, borrowRepository)
+	userService := usersvc.New(logger, validate, txManager, userRepository, outboxRepository)
+	bookService := booksvc.New(validate, txManager, bookRepository, readingListRepository, workRepository, locationRepository, outboxRepository)
+	notificationService := notificationsvc.New(logger, validate, txManager, userRepository, notificationRepository, mailSender)
+	borrowService := borrowsvc.New(logger, validate, txManager, userRepository, bookRepository, borrowRepository, outboxRepository, notificationService)
+	reportService := reportsvc.New(logger, reportRepository)
+	exportService := exportsvc.New(logger, txManager, exportRepository, borrowService)
+	reviewService := reviewsvc.New(logger, validate, txManager, bookRepository, borrowRepository, reviewRepository)
+	readingListService := readinglistsvc.New(logger, validate, txManager, bookRepository, readingListRepository)
+	recommendationService := recommendationsvc.New(logger, txManager, bookRepository, recommendationRepository)
+	workService := worksvc.New(validate, txManager, workRepository)
+	locationService := locationsvc.New(validate, txManager, locationRepository)
+	oaiService := oaisvc.New(logger, bookRepository)
+	feedService := feedsvc.New(logger, bookRepository)
+
+	eventService := eventsvc.New(logger, outboxRepository, eventPublisher)
+	schedulerService := schedulersvc.New(logger, txManager, jobRepository, borrowService, exportService, eventService, recommendationService)
+
+	// resume export jobs left unfinished by previous run
+	if err := exportService.Resume(context.Background()); err != nil {
+		logger.Errorw("Failed to resume export jobs", "error", err)
+	}
+
+	// deliver queued notifications and retry failed ones
+	go notificationService.Run(context.Background())
+
+	// publish domain events written to the outbox
+	if eventPublisher != nil {
+		go eventService.Run(context.Background())
+	} else {
+		logger.Warnw("KAFKA_BROKERS is not set, domain events are kept in the outbox")
+	}
+
+	// run reminders and housekeeping on their schedule
+	if err := schedulerService.Start(context.Background()); err != nil {
+		logger.Errorw("Failed to start scheduler", "error", err)
+	}
+
+	// serve self-check kiosks when a SIP2 port is configured
+	if port := utils.GetString("SIP2_PORT"); port != "" {
+		sipServer := sipserver.New(logger, borrowService, userRepository, bookRepository, borrowRepository)
+		go func() {
+			if err := sipServer.ListenAndServe(fmt.Sprintf(":%s", port)); err != nil {
+				logger.Errorw("Failed to start SIP2 server", "error", err)
+			}
+		}()
+	}
 
 	// controller
-	ctrl := controller.New(userService, bookService, borrowService)
+	ctrl := controller.New(
+		userService,
+		bookService,
+		borrowService,
+		reportService,
+		exportService,
+		reviewService,
+		readingListService,
+		recommendationService,
+		workService,
+		locationService,
+		oaiService,
+		feedService,
+		notificationService,
+		schedulerService,
+	)
 
 	// listen to routes
 	listenRoutes(ctrl)
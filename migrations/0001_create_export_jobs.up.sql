@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS export_jobs (
+	id UUID PRIMARY KEY,
+	user_id UUID NOT NULL REFERENCES users (id),
+	status VARCHAR(20) NOT NULL,
+	filter JSONB NOT NULL DEFAULT '{}',
+	timezone INT NOT NULL DEFAULT 0,
+	progress INT NOT NULL DEFAULT 0,
+	total INT NOT NULL DEFAULT 0,
+	file_name VARCHAR(255) NOT NULL,
+	error TEXT,
+	expires_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS export_jobs_status_idx ON export_jobs (status);
+CREATE INDEX IF NOT EXISTS export_jobs_user_id_idx ON export_jobs (user_id);
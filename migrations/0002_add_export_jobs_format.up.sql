@@ -0,0 +1 @@
+ALTER TABLE export_jobs ADD COLUMN IF NOT EXISTS format VARCHAR(10) NOT NULL DEFAULT 'xlsx';
@@ -0,0 +1,5 @@
+ALTER TABLE export_jobs ALTER COLUMN timezone DROP DEFAULT;
+ALTER TABLE export_jobs ALTER COLUMN timezone TYPE INT USING 0;
+ALTER TABLE export_jobs ALTER COLUMN timezone SET DEFAULT 0;
+
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64);
+
+-- legacy hour offset becomes an Etc zone, which has inverted sign
+ALTER TABLE export_jobs ALTER COLUMN timezone DROP DEFAULT;
+ALTER TABLE export_jobs ALTER COLUMN timezone TYPE VARCHAR(64) USING (
+	CASE
+		WHEN timezone = 0 THEN 'UTC'
+		WHEN timezone > 0 THEN 'Etc/GMT-' || timezone
+		ELSE 'Etc/GMT+' || ABS(timezone)
+	END
+);
+ALTER TABLE export_jobs ALTER COLUMN timezone SET DEFAULT 'UTC';
@@ -0,0 +1,4 @@
+ALTER TABLE books DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE books DROP COLUMN IF EXISTS rating_average;
+
+DROP TABLE IF EXISTS book_reviews;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS book_reviews (
+	id UUID PRIMARY KEY,
+	book_id UUID NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+	user_id UUID NOT NULL REFERENCES users (id),
+	rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+	content TEXT NOT NULL DEFAULT '',
+	status VARCHAR(20) NOT NULL,
+	moderation_note TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ,
+	UNIQUE (book_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS book_reviews_status_idx ON book_reviews (status);
+
+-- aggregated from published reviews, kept up to date on every review write
+ALTER TABLE books ADD COLUMN IF NOT EXISTS rating_average NUMERIC(3, 2) NOT NULL DEFAULT 0;
+ALTER TABLE books ADD COLUMN IF NOT EXISTS rating_count INT NOT NULL DEFAULT 0;
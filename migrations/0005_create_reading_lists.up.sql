@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS reading_lists (
+	id UUID PRIMARY KEY,
+	user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	name VARCHAR(100) NOT NULL,
+	is_default BOOLEAN NOT NULL DEFAULT FALSE,
+	visibility VARCHAR(20) NOT NULL,
+	share_token VARCHAR(64) UNIQUE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS reading_lists_user_id_idx ON reading_lists (user_id);
+
+-- every member has at most one default list
+CREATE UNIQUE INDEX IF NOT EXISTS reading_lists_default_idx ON reading_lists (user_id) WHERE is_default;
+
+CREATE TABLE IF NOT EXISTS reading_list_items (
+	list_id UUID NOT NULL REFERENCES reading_lists (id) ON DELETE CASCADE,
+	book_id UUID NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+	position INT NOT NULL,
+	added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (list_id, book_id)
+);
+
+CREATE INDEX IF NOT EXISTS reading_list_items_book_id_idx ON reading_list_items (book_id);
@@ -0,0 +1,18 @@
+-- members who borrowed book_id also borrowed recommended_book_id, score is number of such members
+CREATE TABLE IF NOT EXISTS book_recommendations (
+	book_id UUID NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+	recommended_book_id UUID NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+	score NUMERIC(10, 2) NOT NULL,
+	refreshed_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (book_id, recommended_book_id)
+);
+
+-- personalised picks based on genre and author history of the member
+CREATE TABLE IF NOT EXISTS user_recommendations (
+	user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	book_id UUID NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+	score NUMERIC(10, 2) NOT NULL,
+	reason VARCHAR(20) NOT NULL,
+	refreshed_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (user_id, book_id)
+);
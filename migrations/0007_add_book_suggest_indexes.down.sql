@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS borrow_records_book_id_idx;
+DROP INDEX IF EXISTS books_genre_trgm_idx;
+DROP INDEX IF EXISTS books_author_trgm_idx;
+DROP INDEX IF EXISTS books_title_trgm_idx;
@@ -0,0 +1,8 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS books_title_trgm_idx ON books USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS books_author_trgm_idx ON books USING GIN (author gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS books_genre_trgm_idx ON books USING GIN (genre gin_trgm_ops);
+
+-- popularity of suggestions is counted per book
+CREATE INDEX IF NOT EXISTS borrow_records_book_id_idx ON borrow_records (book_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS books_work_id_idx;
+
+ALTER TABLE books DROP COLUMN IF EXISTS edition;
+ALTER TABLE books DROP COLUMN IF EXISTS language;
+ALTER TABLE books DROP COLUMN IF EXISTS published_year;
+ALTER TABLE books DROP COLUMN IF EXISTS publisher;
+ALTER TABLE books DROP COLUMN IF EXISTS work_id;
+
+DROP TABLE IF EXISTS works;
+DROP TABLE IF EXISTS series;
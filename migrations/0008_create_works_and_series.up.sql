@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS series (
+	id UUID PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ
+);
+
+-- a work groups every edition of the same text, a series orders works by volume
+CREATE TABLE IF NOT EXISTS works (
+	id UUID PRIMARY KEY,
+	title VARCHAR(255) NOT NULL,
+	author VARCHAR(255) NOT NULL,
+	series_id UUID REFERENCES series (id) ON DELETE SET NULL,
+	volume_number INT CHECK (volume_number > 0),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ,
+	UNIQUE (series_id, volume_number)
+);
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS work_id UUID REFERENCES works (id) ON DELETE SET NULL;
+ALTER TABLE books ADD COLUMN IF NOT EXISTS publisher VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN IF NOT EXISTS published_year INT;
+ALTER TABLE books ADD COLUMN IF NOT EXISTS language VARCHAR(35) NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN IF NOT EXISTS edition VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS books_work_id_idx ON books (work_id);
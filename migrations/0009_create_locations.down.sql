@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS books_shelf_id_idx;
+
+ALTER TABLE books DROP COLUMN IF EXISTS shelf_id;
+ALTER TABLE books DROP COLUMN IF EXISTS call_number_sort;
+ALTER TABLE books DROP COLUMN IF EXISTS call_number;
+ALTER TABLE books DROP COLUMN IF EXISTS classification_scheme;
+
+DROP TABLE IF EXISTS locations;
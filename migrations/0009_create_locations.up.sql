@@ -0,0 +1,19 @@
+-- floors contain sections which contain shelves
+CREATE TABLE IF NOT EXISTS locations (
+	id UUID PRIMARY KEY,
+	parent_id UUID REFERENCES locations (id) ON DELETE RESTRICT,
+	type VARCHAR(20) NOT NULL,
+	code VARCHAR(50) NOT NULL,
+	name VARCHAR(255) NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS locations_parent_id_idx ON locations (parent_id);
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS classification_scheme VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN IF NOT EXISTS call_number VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN IF NOT EXISTS call_number_sort VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN IF NOT EXISTS shelf_id UUID REFERENCES locations (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS books_shelf_id_idx ON books (shelf_id, call_number_sort);
@@ -0,0 +1,4 @@
+-- ISBN-13 or ISBN-10 without hyphens, empty when unknown
+ALTER TABLE books ADD COLUMN IF NOT EXISTS isbn VARCHAR(13) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS books_isbn_idx ON books (isbn) WHERE isbn <> '';
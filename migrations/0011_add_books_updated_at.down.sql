@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS books_datestamp_idx;
+
+ALTER TABLE books DROP COLUMN IF EXISTS updated_at;
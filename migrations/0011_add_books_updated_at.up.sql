@@ -0,0 +1,4 @@
+ALTER TABLE books ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+
+-- datestamp used by selective harvesting
+CREATE INDEX IF NOT EXISTS books_datestamp_idx ON books ((COALESCE(updated_at, created_at)));
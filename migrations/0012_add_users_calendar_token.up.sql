@@ -0,0 +1,4 @@
+-- sha256 of the calendar feed token, the token itself is only shown once
+ALTER TABLE users ADD COLUMN IF NOT EXISTS calendar_token_hash VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_calendar_token_hash_idx ON users (calendar_token_hash);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS users_card_number_idx;
+
+ALTER TABLE users DROP COLUMN IF EXISTS card_number;
@@ -0,0 +1,4 @@
+-- library card number, printed as QR code on the membership card
+ALTER TABLE users ADD COLUMN IF NOT EXISTS card_number VARCHAR(20);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_card_number_idx ON users (card_number);
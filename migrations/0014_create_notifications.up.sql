@@ -0,0 +1,31 @@
+-- delivery log, pending rows are retried until sent or attempts are exhausted
+CREATE TABLE IF NOT EXISTS notifications (
+	id UUID PRIMARY KEY,
+	user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	type VARCHAR(50) NOT NULL,
+	channel VARCHAR(20) NOT NULL,
+	recipient VARCHAR(255) NOT NULL,
+	subject VARCHAR(255) NOT NULL,
+	body_text TEXT NOT NULL,
+	body_html TEXT NOT NULL,
+	status VARCHAR(20) NOT NULL,
+	attempts INT NOT NULL DEFAULT 0,
+	last_error TEXT,
+	next_attempt_at TIMESTAMPTZ,
+	sent_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_idx ON notifications (user_id);
+CREATE INDEX IF NOT EXISTS notifications_pending_idx ON notifications (next_attempt_at) WHERE status = 'PENDING';
+
+-- opt outs per notification type and channel, missing row mean enabled
+CREATE TABLE IF NOT EXISTS notification_preferences (
+	user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	type VARCHAR(50) NOT NULL,
+	channel VARCHAR(20) NOT NULL,
+	enabled BOOLEAN NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (user_id, type, channel)
+);
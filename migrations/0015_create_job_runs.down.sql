@@ -0,0 +1,6 @@
+ALTER TABLE borrow_records DROP COLUMN IF EXISTS overdue_notice_sent_at;
+ALTER TABLE borrow_records DROP COLUMN IF EXISTS due_reminder_sent_at;
+
+DROP TABLE IF EXISTS job_runs;
+
+DROP TABLE IF EXISTS scheduled_jobs;
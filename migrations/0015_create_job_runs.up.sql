@@ -0,0 +1,23 @@
+-- jobs are declared in code, a row only exists once a job has been paused or resumed
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+	name VARCHAR(50) PRIMARY KEY,
+	paused BOOLEAN NOT NULL DEFAULT FALSE,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS job_runs (
+	id UUID PRIMARY KEY,
+	job_name VARCHAR(50) NOT NULL,
+	trigger VARCHAR(20) NOT NULL,
+	status VARCHAR(20) NOT NULL,
+	result TEXT,
+	error TEXT,
+	started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS job_runs_job_name_idx ON job_runs (job_name, started_at DESC);
+
+-- loans are reminded once before the due date and noticed once after it
+ALTER TABLE borrow_records ADD COLUMN IF NOT EXISTS due_reminder_sent_at TIMESTAMPTZ;
+ALTER TABLE borrow_records ADD COLUMN IF NOT EXISTS overdue_notice_sent_at TIMESTAMPTZ;
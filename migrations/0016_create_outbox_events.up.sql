@@ -0,0 +1,18 @@
+-- domain events written in the transaction of the change, relayed to the broker in seq order.
+-- xact_id is the writing transaction (needs PostgreSQL 13+), instead of serializing writers on a global lock
+-- the relay only read rows whose transaction is older than every running one; events of one aggregate keep
+-- their order since the writer lock the aggregate row before adding them
+CREATE TABLE IF NOT EXISTS outbox_events (
+	seq BIGSERIAL PRIMARY KEY,
+	id UUID NOT NULL UNIQUE,
+	type VARCHAR(50) NOT NULL,
+	aggregate_type VARCHAR(50) NOT NULL,
+	aggregate_id UUID NOT NULL,
+	payload JSONB NOT NULL,
+	occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	published_at TIMESTAMPTZ,
+	xact_id XID8 NOT NULL DEFAULT pg_current_xact_id()
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_idx ON outbox_events (seq) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_events_published_at_idx ON outbox_events (published_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS books_shelf_id_idx;
+
+ALTER TABLE books ALTER COLUMN call_number_sort TYPE VARCHAR(255) COLLATE "default";
+
+CREATE INDEX IF NOT EXISTS books_shelf_id_idx ON books (shelf_id, call_number_sort);
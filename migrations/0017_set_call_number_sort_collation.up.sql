@@ -0,0 +1,6 @@
+-- sort keys are compared byte by byte, the default collation ignores spaces and dots and break shelf order
+DROP INDEX IF EXISTS books_shelf_id_idx;
+
+ALTER TABLE books ALTER COLUMN call_number_sort TYPE VARCHAR(255) COLLATE "C";
+
+CREATE INDEX IF NOT EXISTS books_shelf_id_idx ON books (shelf_id, call_number_sort);
@@ -0,0 +1,83 @@
+// Package callnumber validate and normalize Dewey Decimal and Library of Congress call numbers
+package callnumber
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	Dewey           = "DDC"
+	LibraryCongress = "LCC"
+)
+
+var (
+	// e.g. "823.914 ROW", "005.133 K58 2019"
+	deweyPattern = regexp.MustCompile(`^(\d{3})(?:\.(\d+))?((?:\s+[A-Z0-9][A-Z0-9.]*)*)$`)
+
+	// e.g. "QA76.73.G63 D66 2016", "PR6068.O93 H37 1997"
+	lcPattern = regexp.MustCompile(`^([A-Z]{1,3})\s?(\d{1,4})(?:\.(\d+))?((?:\s?\.?[A-Z]\d+)*)((?:\s+\d{4}[A-Z]?)?)$`)
+
+	cutterPattern = regexp.MustCompile(`\.?([A-Z])(\d+)`)
+)
+
+// Normalize trim and collapse spaces, classes and cutters are upper case
+func Normalize(value string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(value), " "))
+}
+
+// Validate check call number against the classification scheme
+func Validate(scheme, value string) error {
+	value = Normalize(value)
+
+	switch scheme {
+	case Dewey:
+		if !deweyPattern.MatchString(value) {
+			return fmt.Errorf("call_number %q is not a valid Dewey call number, e.g. \"823.914 ROW\"", value)
+		}
+	case LibraryCongress:
+		if !lcPattern.MatchString(value) {
+			return fmt.Errorf("call_number %q is not a valid Library of Congress call number, e.g. \"QA76.73 .G63 2016\"", value)
+		}
+	default:
+		return fmt.Errorf("classification_scheme must be one of '%s', '%s'", Dewey, LibraryCongress)
+	}
+
+	return nil
+}
+
+// SortKey return a key whose byte order is the shelf order of call numbers,
+// value must be valid for the scheme
+func SortKey(scheme, value string) string {
+	value = Normalize(value)
+
+	switch scheme {
+	case Dewey:
+		m := deweyPattern.FindStringSubmatch(value)
+		if m == nil {
+			return value
+		}
+
+		// class is always 3 digits, decimals compare as text
+		return fmt.Sprintf("%s.%-12s%s", m[1], m[2], strings.TrimSpace(m[3]))
+
+	case LibraryCongress:
+		m := lcPattern.FindStringSubmatch(value)
+		if m == nil {
+			return value
+		}
+
+		// class letters are padded so "Q" comes before "QA", the number is zero padded
+		key := fmt.Sprintf("%-3s%04s.%-8s", m[1], m[2], m[3])
+
+		// cutters sort letter first then decimal digits
+		for _, cutter := range cutterPattern.FindAllStringSubmatch(m[4], -1) {
+			key += fmt.Sprintf(" %s%-8s", cutter[1], cutter[2])
+		}
+
+		return key + strings.TrimSpace(m[5])
+	}
+
+	return value
+}
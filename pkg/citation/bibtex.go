@@ -0,0 +1,135 @@
+package citation
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+func writeBibTeX(w io.Writer, works []Work) error {
+	used := make(map[string]int)
+
+	for i, work := range works {
+		// keys must be unique within a file, repeated keys get a letter suffix
+		key := bibtexKey(work)
+		if n := used[key]; n > 0 {
+			used[key]++
+			key += string(rune('a' + n - 1))
+		} else {
+			used[key] = 1
+		}
+
+		authors := make([]string, 0)
+		for _, name := range work.Authors {
+			family, given := splitName(name)
+			if given != "" {
+				family += ", " + given
+			}
+			authors = append(authors, family)
+		}
+
+		fields := [][2]string{
+			{"author", strings.Join(authors, " and ")},
+			{"title", work.Title},
+			{"edition", work.Edition},
+			{"publisher", work.Publisher},
+			{"year", yearString(work.Year)},
+			{"isbn", work.ISBN},
+			{"language", work.Language},
+			{"url", work.URL},
+			{"abstract", work.Abstract},
+			{"keywords", strings.Join(work.Keywords, ", ")},
+		}
+
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "@book{%s", key); err != nil {
+			return err
+		}
+
+		for _, f := range fields {
+			if f[1] == "" {
+				continue
+			}
+
+			value := bibtexEscaper.Replace(f[1])
+			if f[0] == "url" {
+				value = f[1]
+			}
+
+			if _, err := fmt.Fprintf(w, ",\n  %s = {%s}", f[0], value); err != nil {
+				return err
+			}
+		}
+
+		if _, err := io.WriteString(w, "\n}\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bibtexKey build a key from first author family name, year and first title word, e.g. rowling1997harry
+func bibtexKey(work Work) string {
+	var b strings.Builder
+
+	if len(work.Authors) > 0 {
+		family, _ := splitName(work.Authors[0])
+		b.WriteString(keyPart(family))
+	}
+
+	b.WriteString(yearString(work.Year))
+
+	for _, word := range strings.Fields(work.Title) {
+		if part := keyPart(word); len(part) > 3 || b.Len() == 0 {
+			b.WriteString(part)
+			break
+		}
+	}
+
+	if b.Len() == 0 {
+		return "book"
+	}
+
+	return b.String()
+}
+
+// keyPart keep ascii letters and digits of s in lower case
+func keyPart(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+func yearString(year int) string {
+	if year == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(year)
+}
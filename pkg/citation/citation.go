@@ -0,0 +1,104 @@
+// Package citation render book citations for reference managers
+package citation
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Work is the bibliographic data of a single cited book
+type Work struct {
+	ID        string
+	Title     string
+	Authors   []string
+	Publisher string
+	Year      int // zero when unknown
+	Edition   string
+	ISBN      string
+	Language  string
+	URL       string
+	Abstract  string
+	Keywords  []string
+}
+
+// Format describe a supported citation format
+type Format struct {
+	Name        string
+	Extension   string
+	ContentType string
+	write       func(w io.Writer, works []Work) error
+}
+
+var formats = map[string]Format{
+	"bibtex": {
+		Name:        "bibtex",
+		Extension:   "bib",
+		ContentType: "application/x-bibtex; charset=utf-8",
+		write:       writeBibTeX,
+	},
+	"ris": {
+		Name:        "ris",
+		Extension:   "ris",
+		ContentType: "application/x-research-info-systems; charset=utf-8",
+		write:       writeRIS,
+	},
+	"csl-json": {
+		Name:        "csl-json",
+		Extension:   "json",
+		ContentType: "application/vnd.citationstyles.csl+json",
+		write:       writeCSL,
+	},
+}
+
+// Lookup return format by name, name is case insensitive
+func Lookup(name string) (Format, error) {
+	f, ok := formats[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0)
+		for key := range formats {
+			names = append(names, "'"+key+"'")
+		}
+		sort.Strings(names)
+
+		return Format{}, fmt.Errorf("Invalid format. Available formats: %s", strings.Join(names, ", "))
+	}
+
+	return f, nil
+}
+
+// Write render every work into w
+func (f Format) Write(w io.Writer, works []Work) error {
+	return f.write(w, works)
+}
+
+// authorSeparators split an author field holding several people
+var authorSeparators = strings.NewReplacer(";", "\x00", " & ", "\x00", " and ", "\x00")
+
+// SplitAuthors split an author field such as "Pratchett, Terry & Gaiman, Neil" into names
+func SplitAuthors(author string) []string {
+	names := make([]string, 0)
+	for _, name := range strings.Split(authorSeparators.Replace(author), "\x00") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// splitName return family and given name of "Family, Given" or "Given Family",
+// a single word is returned as family name only
+func splitName(name string) (family, given string) {
+	if family, given, ok := strings.Cut(name, ","); ok {
+		return strings.TrimSpace(family), strings.TrimSpace(given)
+	}
+
+	words := strings.Fields(name)
+	if len(words) < 2 {
+		return name, ""
+	}
+
+	return words[len(words)-1], strings.Join(words[:len(words)-1], " ")
+}
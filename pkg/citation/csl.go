@@ -0,0 +1,74 @@
+package citation
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type cslName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+type cslItem struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	Author    []cslName `json:"author,omitempty"`
+	Issued    *cslDate  `json:"issued,omitempty"`
+	Publisher string    `json:"publisher,omitempty"`
+	Edition   string    `json:"edition,omitempty"`
+	ISBN      string    `json:"ISBN,omitempty"`
+	Language  string    `json:"language,omitempty"`
+	URL       string    `json:"URL,omitempty"`
+	Abstract  string    `json:"abstract,omitempty"`
+	Keyword   string    `json:"keyword,omitempty"`
+}
+
+func writeCSL(w io.Writer, works []Work) error {
+	items := make([]cslItem, 0)
+	for _, work := range works {
+		item := cslItem{
+			ID:        work.ID,
+			Type:      "book",
+			Title:     work.Title,
+			Publisher: work.Publisher,
+			Edition:   work.Edition,
+			ISBN:      work.ISBN,
+			Language:  work.Language,
+			URL:       work.URL,
+			Abstract:  work.Abstract,
+		}
+
+		for _, name := range work.Authors {
+			family, given := splitName(name)
+			if given == "" {
+				item.Author = append(item.Author, cslName{Literal: family})
+				continue
+			}
+			item.Author = append(item.Author, cslName{Family: family, Given: given})
+		}
+
+		if work.Year != 0 {
+			item.Issued = &cslDate{DateParts: [][]int{{work.Year}}}
+		}
+
+		for i, keyword := range work.Keywords {
+			if i > 0 {
+				item.Keyword += ", "
+			}
+			item.Keyword += keyword
+		}
+
+		items = append(items, item)
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(items)
+}
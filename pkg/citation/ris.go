@@ -0,0 +1,52 @@
+package citation
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func writeRIS(w io.Writer, works []Work) error {
+	for _, work := range works {
+		lines := [][2]string{{"TY", "BOOK"}}
+
+		for _, name := range work.Authors {
+			family, given := splitName(name)
+			if given != "" {
+				family += ", " + given
+			}
+			lines = append(lines, [2]string{"AU", family})
+		}
+
+		lines = append(lines,
+			[2]string{"TI", work.Title},
+			[2]string{"ET", work.Edition},
+			[2]string{"PB", work.Publisher},
+			[2]string{"PY", yearString(work.Year)},
+			[2]string{"SN", work.ISBN},
+			[2]string{"LA", work.Language},
+			[2]string{"UR", work.URL},
+			[2]string{"AB", work.Abstract},
+		)
+
+		for _, keyword := range work.Keywords {
+			lines = append(lines, [2]string{"KW", keyword})
+		}
+
+		lines = append(lines, [2]string{"ID", work.ID}, [2]string{"ER", ""})
+
+		for _, line := range lines {
+			if line[1] == "" && line[0] != "ER" {
+				continue
+			}
+
+			// tags are followed by two spaces, a hyphen and a space, values are single line
+			value := strings.Join(strings.Fields(line[1]), " ")
+			if _, err := fmt.Fprintf(w, "%s  - %s\r\n", line[0], value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
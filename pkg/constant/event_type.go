@@ -0,0 +1,9 @@
+package constant
+
+const (
+	Event_BookCreated    string = "book.created"
+	Event_BookUpdated    string = "book.updated"
+	Event_BorrowCreated  string = "borrow.created"
+	Event_BorrowReturned string = "borrow.returned"
+	Event_UserRegistered string = "user.registered"
+)
@@ -0,0 +1,15 @@
+package constant
+
+const (
+	ExportStatus_Pending    string = "PENDING"
+	ExportStatus_Processing string = "PROCESSING"
+	ExportStatus_Finished   string = "FINISHED"
+	ExportStatus_Failed     string = "FAILED"
+	ExportStatus_Expired    string = "EXPIRED"
+)
+
+// ExportStatusUnfinished is job status that must be resumed after restart
+var ExportStatusUnfinished []string = []string{
+	ExportStatus_Pending,
+	ExportStatus_Processing,
+}
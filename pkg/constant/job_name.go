@@ -0,0 +1,10 @@
+package constant
+
+const (
+	Job_DueSoonReminders string = "due_soon_reminders"
+	Job_OverdueNotices   string = "overdue_notices"
+	Job_ExportCleanup    string = "export_cleanup"
+	Job_OutboxCleanup    string = "outbox_cleanup"
+
+	Job_RecommendationRefresh string = "recommendation_refresh"
+)
@@ -0,0 +1,12 @@
+package constant
+
+const (
+	JobStatus_Running   string = "RUNNING"
+	JobStatus_Succeeded string = "SUCCEEDED"
+	JobStatus_Failed    string = "FAILED"
+)
+
+const (
+	JobTrigger_Schedule string = "SCHEDULE"
+	JobTrigger_Manual   string = "MANUAL" // started by an admin
+)
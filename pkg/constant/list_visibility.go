@@ -0,0 +1,9 @@
+package constant
+
+const (
+	ListVisibility_Private string = "PRIVATE"
+	ListVisibility_Public  string = "PUBLIC"
+)
+
+// DefaultListName is name of the list every member gets
+const DefaultListName string = "Want to read"
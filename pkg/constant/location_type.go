@@ -0,0 +1,14 @@
+package constant
+
+const (
+	LocationType_Floor   string = "FLOOR"
+	LocationType_Section string = "SECTION"
+	LocationType_Shelf   string = "SHELF"
+)
+
+// LocationParentType is the type a location must be placed in, floors have no parent
+var LocationParentType = map[string]string{
+	LocationType_Floor:   "",
+	LocationType_Section: LocationType_Floor,
+	LocationType_Shelf:   LocationType_Section,
+}
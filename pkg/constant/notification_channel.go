@@ -0,0 +1,10 @@
+package constant
+
+const (
+	NotifChannel_Email string = "email"
+)
+
+// NotifChannels is every delivery channel, preferences are kept per type and channel
+var NotifChannels []string = []string{
+	NotifChannel_Email,
+}
@@ -0,0 +1,8 @@
+package constant
+
+const (
+	NotifStatus_Pending string = "PENDING" // waiting for first delivery or retry
+	NotifStatus_Sent    string = "SENT"
+	NotifStatus_Failed  string = "FAILED"  // retries exhausted
+	NotifStatus_Skipped string = "SKIPPED" // channel disabled by member preference
+)
@@ -3,6 +3,12 @@ package constant
 const (
 	NotificationPrefix string = "notification-"
 
+	// circulation
+	NotifType_LoanConfirmation string = "loan_confirmation"
+	NotifType_ReturnReceipt    string = "return_receipt"
+	NotifType_DueSoon          string = "due_soon"
+	NotifType_Overdue          string = "overdue"
+
 	// preparation
 	NotifType_NotifyFeedback string = "notify_feedback"
 	NotifType_Hm1ClosePrep   string = "day_min_1_close_preparation"
@@ -43,3 +49,11 @@ const (
 	NotifType_ResetPassword14 string = "notify_reset_password_14"
 	NotifType_ResetPassword30 string = "notify_reset_password_30"
 )
+
+// NotifTypeMember is notification type members can opt out of
+var NotifTypeMember []string = []string{
+	NotifType_LoanConfirmation,
+	NotifType_ReturnReceipt,
+	NotifType_DueSoon,
+	NotifType_Overdue,
+}
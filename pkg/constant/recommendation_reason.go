@@ -0,0 +1,7 @@
+package constant
+
+const (
+	RecommendationReason_AlsoBorrowed string = "ALSO_BORROWED"
+	RecommendationReason_SameAuthor   string = "SAME_AUTHOR"
+	RecommendationReason_SameGenre    string = "SAME_GENRE"
+)
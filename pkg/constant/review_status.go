@@ -0,0 +1,13 @@
+package constant
+
+const (
+	ReviewStatus_Published string = "PUBLISHED"
+	ReviewStatus_Hidden    string = "HIDDEN"
+)
+
+func ReviewStatus() []string {
+	return []string{
+		ReviewStatus_Published,
+		ReviewStatus_Hidden,
+	}
+}
@@ -0,0 +1,41 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+type csvExporter struct {
+	writer *csv.Writer
+}
+
+func newCSV(w io.Writer, title string, columns []Column) (Exporter, error) {
+	writer := csv.NewWriter(w)
+
+	headers := make([]string, 0)
+	for _, column := range columns {
+		headers = append(headers, column.Name)
+	}
+
+	if err := writer.Write(headers); err != nil {
+		return nil, err
+	}
+
+	return &csvExporter{writer: writer}, nil
+}
+
+func (e *csvExporter) WriteRow(values ...interface{}) error {
+	record := make([]string, 0)
+	for _, value := range values {
+		record = append(record, fmt.Sprint(value))
+	}
+
+	return e.writer.Write(record)
+}
+
+// Close flush remaining rows, totals are left out to keep the file machine readable
+func (e *csvExporter) Close(totals ...interface{}) error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
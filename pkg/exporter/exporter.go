@@ -0,0 +1,80 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Column describe a single exported column, width is in spreadsheet character units
+type Column struct {
+	Key   string
+	Name  string
+	Width float64
+}
+
+// Exporter write rows with a fixed column set into a specific file format
+type Exporter interface {
+	// WriteRow write values in the same order as columns
+	WriteRow(values ...interface{}) error
+
+	// Close write the totals row when the format supports it and flush the output
+	Close(totals ...interface{}) error
+}
+
+// Format describe a supported export format
+type Format struct {
+	Name        string
+	Extension   string
+	ContentType string
+	new         func(w io.Writer, title string, columns []Column) (Exporter, error)
+}
+
+var formats = map[string]Format{
+	"csv": {
+		Name:        "csv",
+		Extension:   "csv",
+		ContentType: "text/csv",
+		new:         newCSV,
+	},
+	"jsonl": {
+		Name:        "jsonl",
+		Extension:   "jsonl",
+		ContentType: "application/x-ndjson",
+		new:         newJSONL,
+	},
+	"xlsx": {
+		Name:        "xlsx",
+		Extension:   "xlsx",
+		ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		new:         newXLSX,
+	},
+	"pdf": {
+		Name:        "pdf",
+		Extension:   "pdf",
+		ContentType: "application/pdf",
+		new:         newPDF,
+	},
+}
+
+// Lookup return format by name, name is case insensitive
+func Lookup(name string) (Format, error) {
+	f, ok := formats[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0)
+		for key := range formats {
+			names = append(names, "'"+key+"'")
+		}
+		sort.Strings(names)
+
+		return Format{}, fmt.Errorf("Invalid format. Available formats: %s", strings.Join(names, ", "))
+	}
+
+	return f, nil
+}
+
+// New create exporter of the format which writes to w
+func (f Format) New(w io.Writer, title string, columns []Column) (Exporter, error) {
+	return f.new(w, title, columns)
+}
@@ -0,0 +1,51 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+type jsonlExporter struct {
+	writer  *bufio.Writer
+	columns []Column
+}
+
+func newJSONL(w io.Writer, title string, columns []Column) (Exporter, error) {
+	return &jsonlExporter{
+		writer:  bufio.NewWriter(w),
+		columns: columns,
+	}, nil
+}
+
+// WriteRow write one json object per line, keys keep the column order
+func (e *jsonlExporter) WriteRow(values ...interface{}) error {
+	e.writer.WriteByte('{')
+	for i, column := range e.columns {
+		var value interface{}
+		if i < len(values) {
+			value = values[i]
+		}
+
+		key, _ := json.Marshal(column.Key)
+		val, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			e.writer.WriteByte(',')
+		}
+		e.writer.Write(key)
+		e.writer.WriteByte(':')
+		e.writer.Write(val)
+	}
+
+	_, err := e.writer.WriteString("}\n")
+	return err
+}
+
+// Close flush remaining rows, totals are left out to keep one record per line
+func (e *jsonlExporter) Close(totals ...interface{}) error {
+	return e.writer.Flush()
+}
@@ -0,0 +1,124 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfMargin     = 10.0
+	pdfRowHeight  = 7.0
+	pdfFontSize   = 8.0
+	pdfTitleSize  = 14.0
+	pdfHeaderFill = 0xf4
+)
+
+// pdfExporter write a printable landscape report, the document is kept in memory until closed
+type pdfExporter struct {
+	w         io.Writer
+	pdf       *fpdf.Fpdf
+	translate func(string) string
+	columns   []Column
+	widths    []float64
+}
+
+func newPDF(w io.Writer, title string, columns []Column) (Exporter, error) {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin+5)
+	pdf.AliasNbPages("")
+
+	e := &pdfExporter{
+		w:         w,
+		pdf:       pdf,
+		translate: pdf.UnicodeTranslatorFromDescriptor(""),
+		columns:   columns,
+	}
+
+	// scale column width to fill the printable page width
+	pageWidth, _ := pdf.GetPageSize()
+	var total float64
+	for _, column := range columns {
+		total += max(column.Width, 1)
+	}
+	for _, column := range columns {
+		e.widths = append(e.widths, max(column.Width, 1)/total*(pageWidth-2*pdfMargin))
+	}
+
+	generatedAt := time.Now().Format("2006-01-02 15:04:05")
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("Helvetica", "B", pdfTitleSize)
+		pdf.CellFormat(0, 8, e.translate(title), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", pdfFontSize)
+		pdf.CellFormat(0, 5, "Generated at "+generatedAt, "", 1, "L", false, 0, "")
+		pdf.Ln(2)
+
+		// repeat column headers on every page
+		pdf.SetFont("Helvetica", "B", pdfFontSize)
+		pdf.SetFillColor(pdfHeaderFill, 0xb0, 0x84)
+		for i, column := range e.columns {
+			pdf.CellFormat(e.widths[i], pdfRowHeight, e.fit(column.Name, e.widths[i]), "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", pdfFontSize)
+	})
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin - 2)
+		pdf.SetFont("Helvetica", "I", pdfFontSize)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	pdf.AddPage()
+
+	return e, pdf.Error()
+}
+
+// fit translate and truncate text so it fits in the cell width
+func (e *pdfExporter) fit(text string, width float64) string {
+	text = e.translate(text)
+	limit := width - 2
+	if e.pdf.GetStringWidth(text) <= limit {
+		return text
+	}
+
+	for len(text) > 0 && e.pdf.GetStringWidth(text+"...") > limit {
+		text = text[:len(text)-1]
+	}
+
+	return text + "..."
+}
+
+func (e *pdfExporter) writeCells(values []interface{}) {
+	for i := range e.columns {
+		var text, align string
+		if i < len(values) && values[i] != nil {
+			text = fmt.Sprint(values[i])
+			switch values[i].(type) {
+			case int, int64, float64:
+				align = "R"
+			}
+		}
+
+		e.pdf.CellFormat(e.widths[i], pdfRowHeight, e.fit(text, e.widths[i]), "1", 0, align, false, 0, "")
+	}
+	e.pdf.Ln(-1)
+}
+
+func (e *pdfExporter) WriteRow(values ...interface{}) error {
+	e.writeCells(values)
+	return e.pdf.Error()
+}
+
+// Close write the totals row in bold and output the document
+func (e *pdfExporter) Close(totals ...interface{}) error {
+	if len(totals) > 0 {
+		e.pdf.SetFont("Helvetica", "B", pdfFontSize)
+		e.writeCells(totals)
+	}
+
+	return e.pdf.Output(e.w)
+}
@@ -0,0 +1,37 @@
+package exporter
+
+import (
+	"io"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+)
+
+type xlsxExporter struct {
+	w      io.Writer
+	stream *lib.ExcelStream
+}
+
+func newXLSX(w io.Writer, title string, columns []Column) (Exporter, error) {
+	headers := make([]string, 0)
+	widths := make([]float64, 0)
+	for _, column := range columns {
+		headers = append(headers, column.Name)
+		widths = append(widths, column.Width)
+	}
+
+	stream, err := lib.NewExcelStream(title, headers, widths...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxExporter{w: w, stream: stream}, nil
+}
+
+func (e *xlsxExporter) WriteRow(values ...interface{}) error {
+	return e.stream.WriteRow(values...)
+}
+
+// Close write the workbook, totals are left out to keep the sheet filterable
+func (e *xlsxExporter) Close(totals ...interface{}) error {
+	return e.stream.Close(e.w)
+}
@@ -0,0 +1,139 @@
+// Package ical write RFC 5545 iCalendar feeds
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	ContentType = "text/calendar; charset=utf-8"
+
+	dateTimeLayout = "20060102T150405Z"
+
+	// content lines longer than this many octets are folded
+	maxLineLength = 75
+)
+
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Event is a timed event, times are written in UTC
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	URL          string
+	Start        time.Time
+	End          time.Time
+	LastModified time.Time
+	Alarm        *Alarm
+}
+
+// Alarm is a display reminder triggered before the start of its event
+type Alarm struct {
+	Before      time.Duration
+	Description string
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// Write render calendar as a VCALENDAR object
+func (cal *Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeLine(bw, name+":"+value)
+	}
+
+	stamp := time.Now().UTC().Format(dateTimeLayout)
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", cal.ProdID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		line("X-WR-CALNAME", text(cal.Name))
+	}
+
+	for _, e := range cal.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", stamp)
+		line("DTSTART", e.Start.UTC().Format(dateTimeLayout))
+		line("DTEND", e.End.UTC().Format(dateTimeLayout))
+		line("SUMMARY", text(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", text(e.Description))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		if !e.LastModified.IsZero() {
+			line("LAST-MODIFIED", e.LastModified.UTC().Format(dateTimeLayout))
+		}
+
+		if e.Alarm != nil {
+			line("BEGIN", "VALARM")
+			line("ACTION", "DISPLAY")
+			line("DESCRIPTION", text(e.Alarm.Description))
+			line("TRIGGER", "-"+duration(e.Alarm.Before))
+			line("END", "VALARM")
+		}
+
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+func text(value string) string {
+	return textEscaper.Replace(value)
+}
+
+// duration render a positive duration as PT#H#M, e.g. PT24H
+func duration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	if minutes <= 0 {
+		return "PT0M"
+	}
+
+	s := "PT"
+	if minutes >= 60 {
+		s += fmt.Sprintf("%dH", minutes/60)
+	}
+	if minutes%60 != 0 {
+		s += fmt.Sprintf("%dM", minutes%60)
+	}
+
+	return s
+}
+
+// writeLine write a content line, folding it without splitting a character
+func writeLine(w *bufio.Writer, s string) {
+	limit := maxLineLength
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+
+		// continuation lines start with a space
+		limit = maxLineLength - 1
+	}
+
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
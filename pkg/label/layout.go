@@ -0,0 +1,116 @@
+package label
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Layout describe a sheet of equally sized labels, sizes are in millimetres
+type Layout struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	PageWidth   float64 `json:"page_width" validate:"required,gt=0"`
+	PageHeight  float64 `json:"page_height" validate:"required,gt=0"`
+	MarginTop   float64 `json:"margin_top" validate:"min=0"`
+	MarginLeft  float64 `json:"margin_left" validate:"min=0"`
+	LabelWidth  float64 `json:"label_width" validate:"required,gt=0"`
+	LabelHeight float64 `json:"label_height" validate:"required,gt=0"`
+	Columns     int     `json:"columns" validate:"required,min=1,max=20"`
+	Rows        int     `json:"rows" validate:"required,min=1,max=40"`
+	ColumnPitch float64 `json:"column_pitch" validate:"min=0"` // distance between left edges, label width when 0
+	RowPitch    float64 `json:"row_pitch" validate:"min=0"`    // distance between top edges, label height when 0
+}
+
+const DefaultLayout = "avery-l7160"
+
+// Layouts are common Avery sheets, US Letter is 215.9 x 279.4 mm
+var Layouts = map[string]Layout{
+	"avery-l7160": {
+		Description: "A4, 21 labels of 63.5 x 38.1 mm",
+		PageWidth:   210, PageHeight: 297,
+		MarginTop: 15.1, MarginLeft: 7.2,
+		LabelWidth: 63.5, LabelHeight: 38.1,
+		Columns: 3, Rows: 7,
+		ColumnPitch: 66, RowPitch: 38.1,
+	},
+	"avery-l7651": {
+		Description: "A4, 65 labels of 38.1 x 21.2 mm",
+		PageWidth:   210, PageHeight: 297,
+		MarginTop: 10.7, MarginLeft: 4.7,
+		LabelWidth: 38.1, LabelHeight: 21.2,
+		Columns: 5, Rows: 13,
+		ColumnPitch: 40.6, RowPitch: 21.2,
+	},
+	"avery-l7159": {
+		Description: "A4, 24 labels of 63.5 x 33.9 mm",
+		PageWidth:   210, PageHeight: 297,
+		MarginTop: 12.9, MarginLeft: 6.4,
+		LabelWidth: 63.5, LabelHeight: 33.9,
+		Columns: 3, Rows: 8,
+		ColumnPitch: 66, RowPitch: 33.9,
+	},
+	"avery-5160": {
+		Description: "US Letter, 30 labels of 66.7 x 25.4 mm",
+		PageWidth:   215.9, PageHeight: 279.4,
+		MarginTop: 12.7, MarginLeft: 4.8,
+		LabelWidth: 66.7, LabelHeight: 25.4,
+		Columns: 3, Rows: 10,
+		ColumnPitch: 69.8, RowPitch: 25.4,
+	},
+	"avery-5163": {
+		Description: "US Letter, 10 labels of 101.6 x 50.8 mm",
+		PageWidth:   215.9, PageHeight: 279.4,
+		MarginTop: 12.7, MarginLeft: 4,
+		LabelWidth: 101.6, LabelHeight: 50.8,
+		Columns: 2, Rows: 5,
+		ColumnPitch: 106.3, RowPitch: 50.8,
+	},
+}
+
+// LookupLayout return predefined layout by case insensitive name
+func LookupLayout(name string) (Layout, error) {
+	name = strings.ToLower(name)
+	layout, ok := Layouts[name]
+	if !ok {
+		return Layout{}, fmt.Errorf("unknown label layout %q, must be one of %s", name, strings.Join(LayoutNames(), ", "))
+	}
+
+	layout.Name = name
+	return layout, nil
+}
+
+// LayoutNames return names of predefined layouts in alphabetical order
+func LayoutNames() []string {
+	names := make([]string, 0, len(Layouts))
+	for name := range Layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Check make sure every label of the grid fits inside the page
+func (l *Layout) Check() error {
+	right := l.MarginLeft + float64(l.Columns-1)*l.columnPitch() + l.LabelWidth
+	bottom := l.MarginTop + float64(l.Rows-1)*l.rowPitch() + l.LabelHeight
+	if right > l.PageWidth+0.5 || bottom > l.PageHeight+0.5 {
+		return fmt.Errorf("labels of %s do not fit on a %.1f x %.1f mm page", l.Name, l.PageWidth, l.PageHeight)
+	}
+
+	return nil
+}
+
+func (l *Layout) columnPitch() float64 {
+	return max(l.ColumnPitch, l.LabelWidth)
+}
+
+func (l *Layout) rowPitch() float64 {
+	return max(l.RowPitch, l.LabelHeight)
+}
+
+// PerPage is the number of labels on one sheet
+func (l *Layout) PerPage() int {
+	return l.Columns * l.Rows
+}
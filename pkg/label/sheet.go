@@ -0,0 +1,179 @@
+// Package label render printable sheets of spine labels with barcodes
+package label
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	ContentType = "application/pdf"
+
+	Code128 = "code128"
+	QR      = "qr"
+
+	padding = 2.0
+
+	// call number is printed one part per line, e.g. "823.914" and "PRA"
+	maxCallNumberLines = 3
+)
+
+// Label is the content of a single label, Code is encoded as barcode
+type Label struct {
+	CallNumber string
+	Title      string
+	Code       string
+}
+
+// Write render labels on as many sheets as needed,
+// skip leave the first labels of the sheet blank so partly used sheets can be reused
+func Write(w io.Writer, layout Layout, symbology string, labels []Label, skip int) error {
+	if err := layout.Check(); err != nil {
+		return err
+	}
+
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           fpdf.SizeType{Wd: layout.PageWidth, Ht: layout.PageHeight},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+
+	for i, l := range labels {
+		position := (i + skip) % layout.PerPage()
+		if i == 0 || position == 0 {
+			pdf.AddPage()
+		}
+
+		x := layout.MarginLeft + float64(position%layout.Columns)*layout.columnPitch()
+		y := layout.MarginTop + float64(position/layout.Columns)*layout.rowPitch()
+
+		if err := drawLabel(pdf, translate, x, y, layout.LabelWidth, layout.LabelHeight, symbology, &l); err != nil {
+			return err
+		}
+	}
+
+	if len(labels) == 0 {
+		pdf.AddPage()
+	}
+
+	return pdf.Output(w)
+}
+
+// drawLabel put the call number and title on the left of a QR code,
+// or above a Code128 barcode with its value printed underneath
+func drawLabel(pdf *fpdf.Fpdf, translate func(string) string, x, y, width, height float64, symbology string, l *Label) error {
+	x, y, width, height = x+padding, y+padding, width-2*padding, height-2*padding
+
+	textWidth, textHeight := width, height
+	switch symbology {
+	case QR:
+		code, err := qr.Encode(l.Code, qr.M, qr.Auto)
+		if err != nil {
+			return fmt.Errorf("failed to encode %q as QR code: %w", l.Code, err)
+		}
+
+		size := min(height, width/2)
+		drawBarcode(pdf, code, x+width-size, y, size, size)
+		textWidth = width - size - padding
+	case Code128:
+		code, err := code128.Encode(l.Code)
+		if err != nil {
+			return fmt.Errorf("failed to encode %q as Code128: %w", l.Code, err)
+		}
+
+		// barcode take the lower half, value is printed below it
+		barHeight := height/2 - 3
+		textHeight = height - barHeight - 3
+		drawBarcode(pdf, code, x, y+textHeight, width, barHeight)
+
+		pdf.SetFont("Courier", "", fontSize(3))
+		pdf.SetXY(x, y+height-3)
+		pdf.CellFormat(width, 3, fit(pdf, translate(l.Code), width), "", 0, "C", false, 0, "")
+	default:
+		return fmt.Errorf("unknown barcode %q, must be %s or %s", symbology, Code128, QR)
+	}
+
+	lines := strings.Fields(l.CallNumber)
+	if len(lines) > maxCallNumberLines {
+		lines = append(lines[:maxCallNumberLines-1], strings.Join(lines[maxCallNumberLines-1:], " "))
+	}
+
+	// share text height between call number lines and one title line
+	lineHeight := min(textHeight/float64(len(lines)+1), 6)
+
+	pdf.SetFont("Helvetica", "B", fontSize(lineHeight))
+	pdf.SetXY(x, y)
+	for _, line := range lines {
+		pdf.SetX(x)
+		pdf.CellFormat(textWidth, lineHeight, fit(pdf, translate(line), textWidth), "", 1, "L", false, 0, "")
+	}
+
+	pdf.SetFont("Helvetica", "", fontSize(lineHeight)*0.8)
+	pdf.SetX(x)
+	pdf.CellFormat(textWidth, lineHeight, fit(pdf, translate(l.Title), textWidth), "", 1, "L", false, 0, "")
+
+	return pdf.Error()
+}
+
+// drawBarcode draw dark modules as filled rectangles so the code stays sharp at any print resolution,
+// one dimensional codes are stretched to the full height
+func drawBarcode(pdf *fpdf.Fpdf, code barcode.Barcode, x, y, width, height float64) {
+	bounds := code.Bounds()
+	moduleWidth := width / float64(bounds.Dx())
+	moduleHeight := height / float64(bounds.Dy())
+
+	pdf.SetFillColor(0, 0, 0)
+	for row := bounds.Min.Y; row < bounds.Max.Y; row++ {
+		// merge horizontal runs of dark modules into one rectangle
+		start := -1
+		for col := bounds.Min.X; col <= bounds.Max.X; col++ {
+			dark := col < bounds.Max.X && isDark(code.At(col, row))
+			if dark && start < 0 {
+				start = col
+			}
+			if !dark && start >= 0 {
+				pdf.Rect(
+					x+float64(start-bounds.Min.X)*moduleWidth,
+					y+float64(row-bounds.Min.Y)*moduleHeight,
+					float64(col-start)*moduleWidth,
+					moduleHeight,
+					"F",
+				)
+				start = -1
+			}
+		}
+	}
+}
+
+func isDark(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r+g+b < 3*0x8000
+}
+
+// fontSize return font size in points of text filling a line of the given height in millimetres
+func fontSize(lineHeight float64) float64 {
+	return lineHeight / 0.3528 * 0.8
+}
+
+// fit truncate text so it fits in width
+func fit(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+
+	return text + "..."
+}
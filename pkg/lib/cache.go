@@ -1,6 +1,9 @@
 package lib
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 type fileCache struct {
 	sync.RWMutex
@@ -35,3 +38,70 @@ func (r *fileCache) DelFinished(filename string) {
 	delete(r.files, filename)
 	r.Unlock()
 }
+
+// TTLCache is a size bounded in-memory cache whose entries expire after ttl
+type TTLCache[K comparable, V any] struct {
+	sync.RWMutex
+	ttl     time.Duration
+	size    int
+	entries map[K]ttlEntry[V]
+}
+
+type ttlEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// NewTTLCache create cache holding at most size entries
+func NewTTLCache[K comparable, V any](ttl time.Duration, size int) *TTLCache[K, V] {
+	return &TTLCache[K, V]{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[K]ttlEntry[V]),
+	}
+}
+
+// Get return cached value when it exists and has not expired
+func (r *TTLCache[K, V]) Get(key K) (value V, ok bool) {
+	r.RLock()
+	entry, exists := r.entries[key]
+	r.RUnlock()
+
+	if !exists || time.Now().After(entry.expiresAt) {
+		return value, false
+	}
+
+	return entry.value, true
+}
+
+// Set store value, expired entries are dropped when the cache is full
+// and an arbitrary entry is evicted if it is still full
+func (r *TTLCache[K, V]) Set(key K, value V) {
+	r.Lock()
+	defer r.Unlock()
+
+	now := time.Now()
+	if _, exists := r.entries[key]; !exists && len(r.entries) >= r.size {
+		for k, entry := range r.entries {
+			if now.After(entry.expiresAt) {
+				delete(r.entries, k)
+			}
+		}
+
+		for k := range r.entries {
+			if len(r.entries) < r.size {
+				break
+			}
+			delete(r.entries, k)
+		}
+	}
+
+	r.entries[key] = ttlEntry[V]{value: value, expiresAt: now.Add(r.ttl)}
+}
+
+// Purge remove every entry
+func (r *TTLCache[K, V]) Purge() {
+	r.Lock()
+	r.entries = make(map[K]ttlEntry[V])
+	r.Unlock()
+}
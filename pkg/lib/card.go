@@ -0,0 +1,70 @@
+package lib
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+)
+
+// length of library card numbers including prefix and check digit
+const cardNumberLength = 14
+
+// NewCardNumber generate a random library card number starting with LIBRARY_CARD_PREFIX,
+// the last digit is a Luhn check digit so mistyped numbers are rejected
+func NewCardNumber() (string, error) {
+	prefix := utils.GetString("LIBRARY_CARD_PREFIX", "2900")
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	for b.Len() < cardNumberLength-1 {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(byte('0' + n.Int64()))
+	}
+
+	number := b.String()
+	return number + string(rune('0'+luhnCheckDigit(number))), nil
+}
+
+// NormalizeCardNumber remove spaces and dashes of a printed card number
+func NormalizeCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(number))
+}
+
+// ValidCardNumber report whether number only has digits and a valid check digit
+func ValidCardNumber(number string) bool {
+	if len(number) < 2 {
+		return false
+	}
+
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	last := len(number) - 1
+	return int(number[last]-'0') == luhnCheckDigit(number[:last])
+}
+
+func luhnCheckDigit(digits string) int {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+
+		// double every second digit starting from the rightmost
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+
+	return (10 - sum%10) % 10
+}
@@ -0,0 +1,133 @@
+package lib
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const ExcelContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// ExcelStream write a single styled sheet row by row,
+// rows are flushed to a temporary file once the buffer is large so memory stays bounded
+type ExcelStream struct {
+	file       *excelize.File
+	stream     *excelize.StreamWriter
+	bodyStyle  int
+	currentRow int
+}
+
+// NewExcelStream create a new sheet with styled headers,
+// widths are applied to columns in order and default to 20
+func NewExcelStream(sheetName string, headers []string, widths ...float64) (*ExcelStream, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", sheetName); err != nil {
+		return nil, err
+	}
+
+	headerStyle, err := file.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{
+			Horizontal: "center",
+			Vertical:   "center",
+		},
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{
+			Type:    "pattern",
+			Color:   []string{"#f4b084"},
+			Pattern: 1,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bodyStyle, err := file.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{WrapText: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := file.NewStreamWriter(sheetName)
+	if err != nil {
+		return nil, err
+	}
+
+	// column width must be set before any row is written
+	for i := range headers {
+		width := 20.0
+		if i < len(widths) {
+			width = widths[i]
+		}
+
+		if err := stream.SetColWidth(i+1, i+1, width); err != nil {
+			return nil, err
+		}
+	}
+
+	cells := make([]interface{}, 0)
+	for _, header := range headers {
+		cells = append(cells, excelize.Cell{StyleID: headerStyle, Value: header})
+	}
+
+	if err := stream.SetRow("A1", cells); err != nil {
+		return nil, err
+	}
+
+	return &ExcelStream{
+		file:       file,
+		stream:     stream,
+		bodyStyle:  bodyStyle,
+		currentRow: 1,
+	}, nil
+}
+
+// WriteRow append a styled row below the previous one
+func (e *ExcelStream) WriteRow(values ...interface{}) error {
+	e.currentRow++
+
+	cells := make([]interface{}, 0)
+	for _, value := range values {
+		cells = append(cells, excelize.Cell{StyleID: e.bodyStyle, Value: value})
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, e.currentRow)
+	if err != nil {
+		return err
+	}
+
+	return e.stream.SetRow(cell, cells)
+}
+
+// Close flush remaining rows and write the workbook to w
+func (e *ExcelStream) Close(w io.Writer) error {
+	defer e.file.Close()
+
+	if err := e.stream.Flush(); err != nil {
+		return err
+	}
+
+	return e.file.Write(w)
+}
+
+// WriteExcel write headers and rows into a single styled sheet
+func WriteExcel(sheetName string, headers []string, rows [][]interface{}) (*bytes.Buffer, error) {
+	stream, err := NewExcelStream(sheetName, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if err := stream.WriteRow(row...); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := stream.Close(&buf); err != nil {
+		return nil, err
+	}
+
+	return &buf, nil
+}
@@ -1 +1,105 @@
 package lib
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Message is a record sent to a topic, messages with the same key keep their order
+type Message struct {
+	Key     string
+	Value   []byte
+	Headers map[string]string
+}
+
+// Publisher send messages to a broker, Publish return once every message
+// is acknowledged so the caller may only then consider them delivered
+type Publisher interface {
+	Publish(c context.Context, msgs ...Message) error
+	Close() error
+}
+
+type KafkaConfig struct {
+	Brokers  []string
+	Topic    string
+	ClientID string
+}
+
+// KafkaPublisher publish to a single topic, messages are partitioned by key
+// and every in-sync replica must acknowledge a write
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaPublisher(cfg KafkaConfig) *KafkaPublisher {
+	transport := &kafka.Transport{
+		ClientID: cfg.ClientID,
+	}
+
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Brokers...),
+			Topic:        cfg.Topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: 10 * time.Millisecond,
+			Transport:    transport,
+		},
+	}
+}
+
+func (p *KafkaPublisher) Publish(c context.Context, msgs ...Message) error {
+	records := make([]kafka.Message, 0, len(msgs))
+	for _, m := range msgs {
+		record := kafka.Message{
+			Key:   []byte(m.Key),
+			Value: m.Value,
+		}
+		for k, v := range m.Headers {
+			record.Headers = append(record.Headers, kafka.Header{Key: k, Value: []byte(v)})
+		}
+		records = append(records, record)
+	}
+
+	return p.writer.WriteMessages(c, records...)
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// MemoryPublisher keep published messages in memory, for tests.
+// Set Err to make every Publish fail
+type MemoryPublisher struct {
+	Err error
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (p *MemoryPublisher) Publish(c context.Context, msgs ...Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return p.Err
+	}
+
+	p.messages = append(p.messages, msgs...)
+	return nil
+}
+
+// Messages return every message published so far, in publish order
+func (p *MemoryPublisher) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Message(nil), p.messages...)
+}
+
+func (p *MemoryPublisher) Close() error {
+	return nil
+}
@@ -89,3 +89,11 @@ func CreateNameCompany(companyName, companyLine string, branchName ...string) st
 
 	return name
 }
+
+// isbnReplacer remove separators commonly written inside ISBN
+var isbnReplacer = strings.NewReplacer("-", "", " ", "")
+
+// NormalizeISBN strip hyphens and spaces, a lower case check digit x is upper cased
+func NormalizeISBN(isbn string) string {
+	return strings.ToUpper(isbnReplacer.Replace(strings.TrimSpace(isbn)))
+}
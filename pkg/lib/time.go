@@ -1,7 +1,9 @@
 package lib
 
 import (
+	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
@@ -23,12 +25,96 @@ func TimeNow() time.Time {
 	return time.Now()
 }
 
+// TimeWIB return the time in Asia/Jakarta zone
 func TimeWIB(d ...time.Time) time.Time {
 	t := TimeNow()
 	if len(d) > 0 {
 		t = d[0]
 	}
-	return t.Add(7 * time.Hour)
+
+	loc, err := LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return t.Add(7 * time.Hour)
+	}
+	return t.In(loc)
+}
+
+var locations sync.Map
+
+// LoadLocation load IANA timezone by name, e.g. "Asia/Jakarta", loaded zones are cached
+func LoadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	// "Local" depends on the server, only accept explicit zone names
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("invalid timezone %q", name)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", name)
+	}
+
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// DefaultLocation return zone from DEFAULT_TIMEZONE env, fallback to UTC
+func DefaultLocation() *time.Location {
+	if loc, err := LoadLocation(utils.GetString("DEFAULT_TIMEZONE", "UTC")); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// OffsetLocation convert legacy hour offset into zone name, e.g. 7 into "Etc/GMT-7"
+func OffsetLocation(hours int) (*time.Location, error) {
+	if hours == 0 {
+		return time.UTC, nil
+	}
+
+	// Etc zones use inverted sign
+	if hours > 0 {
+		return LoadLocation(fmt.Sprintf("Etc/GMT-%d", hours))
+	}
+	return LoadLocation(fmt.Sprintf("Etc/GMT+%d", -hours))
+}
+
+// ParseDateIn parse date filter in the given zone,
+// date only value is treated as start of the day, or end of the day when endOfDay is true
+func ParseDateIn(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", value, loc); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", value, loc); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD or RFC3339", value)
+	}
+
+	if endOfDay {
+		// next midnight is used instead of adding 24 hours so DST days stay correct
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return t, nil
+}
+
+// TimePtrIn convert time pointer to given zone, nil stays nil
+func TimePtrIn(t *time.Time, loc *time.Location) *time.Time {
+	if t == nil {
+		return nil
+	}
+	return Pointer(t.In(loc))
 }
 
 // TimeNow func
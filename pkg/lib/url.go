@@ -0,0 +1,13 @@
+package lib
+
+import (
+	"strings"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/utils"
+)
+
+// PublicURL join path onto the public catalog address, path is returned as is when no address is configured
+func PublicURL(path ...string) string {
+	base := strings.TrimSuffix(utils.GetString("PUBLIC_URL"), "/")
+	return base + "/" + strings.TrimPrefix(strings.Join(path, "/"), "/")
+}
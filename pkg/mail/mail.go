@@ -0,0 +1,106 @@
+// Package mail send multipart text and HTML email
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender deliver a message, implementations must be safe for concurrent use
+type Sender interface {
+	Send(c context.Context, msg *Message) error
+}
+
+// Write render msg as RFC 5322 message with a multipart/alternative body
+func (msg *Message) Write(w io.Writer) error {
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %w", msg.From, err)
+	}
+
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+
+	mw := multipart.NewWriter(w)
+
+	domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+	headers := []string{
+		"From: " + from.String(),
+		"To: " + to.String(),
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: <" + randomID() + "@" + domain + ">",
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + mw.Boundary(),
+	}
+	if _, err := io.WriteString(w, strings.Join(headers, "\r\n")+"\r\n\r\n"); err != nil {
+		return err
+	}
+
+	// plain text first, clients show the last part they support
+	parts := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for _, p := range parts {
+		if p.body == "" {
+			continue
+		}
+
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := io.WriteString(qw, p.body); err != nil {
+			return err
+		}
+		if err := qw.Close(); err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// LogSender write messages to the log instead of sending them, used when no SMTP server is configured
+type LogSender struct {
+	Logger *zap.SugaredLogger
+}
+
+func (s *LogSender) Send(c context.Context, msg *Message) error {
+	s.Logger.Infow("email not sent, SMTP is not configured", "to", msg.To, "subject", msg.Subject)
+	return nil
+}
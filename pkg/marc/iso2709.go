@@ -0,0 +1,182 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type isoReader struct {
+	r     *bufio.Reader
+	index int
+}
+
+func newISOReader(r *bufio.Reader) *isoReader {
+	return &isoReader{r: r}
+}
+
+func (ir *isoReader) Read() (*Record, error) {
+	data, err := ir.r.ReadBytes(recordTerminator)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	// trailing line breaks after the last record
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, io.EOF
+	}
+
+	ir.index++
+	record, perr := parseISO(bytes.TrimLeft(data, "\r\n"))
+	if perr != nil {
+		return nil, &RecordError{Index: ir.index, Err: perr}
+	}
+
+	return record, nil
+}
+
+// parseDigits parse a fixed width number of the leader or directory, which is made of digits only,
+// strconv.Atoi alone would accept a sign
+func parseDigits(s string) (int, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, fmt.Errorf("invalid number %q", s)
+		}
+	}
+
+	return strconv.Atoi(s)
+}
+
+// parseISO decode a single record, data end with the record terminator
+func parseISO(data []byte) (*Record, error) {
+	if len(data) < leaderLength+1 {
+		return nil, errors.New("record is shorter than its leader")
+	}
+
+	if data[len(data)-1] != recordTerminator {
+		return nil, errors.New("record is not terminated")
+	}
+
+	leader := string(data[:leaderLength])
+	base, err := parseDigits(leader[12:17])
+	if err != nil || base <= leaderLength || base > len(data) {
+		return nil, fmt.Errorf("invalid base address of data %q", leader[12:17])
+	}
+
+	directory := data[leaderLength : base-1]
+	if len(directory)%directoryEntrySize != 0 {
+		return nil, errors.New("invalid directory length")
+	}
+
+	record := &Record{Leader: leader}
+	for i := 0; i < len(directory); i += directoryEntrySize {
+		entry := directory[i : i+directoryEntrySize]
+		tag := string(entry[:3])
+
+		length, err := parseDigits(string(entry[3:7]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid length of field %s", tag)
+		}
+
+		start, err := parseDigits(string(entry[7:12]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid start of field %s", tag)
+		}
+
+		if base+start+length > len(data) || length < 1 {
+			return nil, fmt.Errorf("field %s is out of record bounds", tag)
+		}
+
+		// drop field terminator
+		value := data[base+start : base+start+length-1]
+		field := Field{Tag: tag}
+
+		if field.IsControl() {
+			field.Value = string(value)
+			record.Fields = append(record.Fields, field)
+			continue
+		}
+
+		if len(value) < 2 {
+			return nil, fmt.Errorf("field %s has no indicators", tag)
+		}
+
+		field.Ind1, field.Ind2 = string(value[0]), string(value[1])
+		for _, sub := range bytes.Split(value[2:], []byte{subfieldDelimiter}) {
+			if len(sub) == 0 {
+				continue
+			}
+
+			field.Subfields = append(field.Subfields, Subfield{Code: string(sub[0]), Value: string(sub[1:])})
+		}
+
+		record.Fields = append(record.Fields, field)
+	}
+
+	return record, nil
+}
+
+// WriteISO encode record as ISO 2709, record length and base address of the leader are computed
+func WriteISO(w io.Writer, r *Record) error {
+	var directory, data bytes.Buffer
+
+	for _, f := range r.Fields {
+		start := data.Len()
+
+		if f.IsControl() {
+			data.WriteString(f.Value)
+		} else {
+			data.WriteString(indicator(f.Ind1))
+			data.WriteString(indicator(f.Ind2))
+			for _, s := range f.Subfields {
+				data.WriteByte(subfieldDelimiter)
+				data.WriteString(s.Code)
+				data.WriteString(s.Value)
+			}
+		}
+		data.WriteByte(fieldTerminator)
+
+		length := data.Len() - start
+		if length > 9999 || start > 99999 {
+			return fmt.Errorf("field %s does not fit in an ISO 2709 record", f.Tag)
+		}
+
+		fmt.Fprintf(&directory, "%3s%04d%05d", f.Tag, length, start)
+	}
+	directory.WriteByte(fieldTerminator)
+
+	base := leaderLength + directory.Len()
+	total := base + data.Len() + 1
+	if total > 99999 {
+		return errors.New("record does not fit in an ISO 2709 record")
+	}
+
+	leader := []byte(r.Leader)
+	if len(leader) != leaderLength {
+		leader = []byte(DefaultLeader)
+	}
+	copy(leader[0:5], fmt.Sprintf("%05d", total))
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+
+	// records are always written as UTF-8
+	leader[9] = 'a'
+
+	buf := bytes.NewBuffer(leader)
+	buf.Write(directory.Bytes())
+	buf.Write(data.Bytes())
+	buf.WriteByte(recordTerminator)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func indicator(value string) string {
+	if len(value) != 1 {
+		return " "
+	}
+
+	return value
+}
@@ -0,0 +1,174 @@
+// Package marc read and write MARC21 bibliographic records as ISO 2709 and MARCXML
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	recordTerminator   = 0x1D
+	fieldTerminator    = 0x1E
+	subfieldDelimiter  = 0x1F
+	leaderLength       = 24
+	directoryEntrySize = 12
+
+	// DefaultLeader is the leader of a new language material record, lengths are filled when written
+	DefaultLeader = "00000nam a2200000 i 4500"
+)
+
+type Subfield struct {
+	Code  string
+	Value string
+}
+
+// Field is a control field (tag below 010) holding value,
+// or a data field holding indicators and subfields
+type Field struct {
+	Tag       string
+	Value     string
+	Ind1      string
+	Ind2      string
+	Subfields []Subfield
+}
+
+// IsControl report whether field is a control field
+func (f Field) IsControl() bool {
+	return f.Tag < "010"
+}
+
+// Subfield return value of the first subfield with code
+func (f Field) Subfield(code string) string {
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			return s.Value
+		}
+	}
+
+	return ""
+}
+
+type Record struct {
+	Leader string
+	Fields []Field
+}
+
+// NewRecord create an empty record with default leader
+func NewRecord() *Record {
+	return &Record{Leader: DefaultLeader}
+}
+
+// AddControl append a control field
+func (r *Record) AddControl(tag, value string) {
+	r.Fields = append(r.Fields, Field{Tag: tag, Value: value})
+}
+
+// AddData append a data field, subfields are given as code and value pairs and empty values are skipped
+func (r *Record) AddData(tag, ind1, ind2 string, pairs ...string) {
+	f := Field{Tag: tag, Ind1: ind1, Ind2: ind2}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			f.Subfields = append(f.Subfields, Subfield{Code: pairs[i], Value: pairs[i+1]})
+		}
+	}
+
+	if len(f.Subfields) > 0 {
+		r.Fields = append(r.Fields, f)
+	}
+}
+
+// Control return value of the first control field with tag
+func (r *Record) Control(tag string) string {
+	for _, f := range r.Fields {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+
+	return ""
+}
+
+// DataFields return every data field with tag
+func (r *Record) DataFields(tag string) []Field {
+	fields := make([]Field, 0)
+	for _, f := range r.Fields {
+		if f.Tag == tag {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// Subfield return value of the first subfield with code in the first field with tag
+func (r *Record) Subfield(tag, code string) string {
+	for _, f := range r.DataFields(tag) {
+		if v := f.Subfield(code); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// RecordError is an error of a single record, reading may continue with the next record
+type RecordError struct {
+	Index int // 1-based position of the record in the file
+	Err   error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Index, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Reader read records one by one, Read return io.EOF after the last record
+type Reader interface {
+	Read() (*Record, error)
+}
+
+// NewReader detect whether r holds MARCXML or ISO 2709 records
+func NewReader(r io.Reader) Reader {
+	br := bufio.NewReader(r)
+
+	// skip byte order mark and leading spaces
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			break
+		}
+
+		if b[0] == '<' {
+			return newXMLReader(br)
+		}
+
+		if !bytes.ContainsRune([]byte(" \t\r\n\xef\xbb\xbf"), rune(b[0])) {
+			break
+		}
+		br.ReadByte()
+	}
+
+	return newISOReader(br)
+}
+
+// TrimPunctuation remove trailing ISBD punctuation such as " /", " :" or ",",
+// a final period is kept after an initial, e.g. "Rowling, J. K."
+func TrimPunctuation(value string) string {
+	value = strings.TrimRight(strings.TrimSpace(value), " /:;,=")
+
+	if strings.HasSuffix(value, ".") {
+		words := strings.Fields(value)
+		last := words[len(words)-1]
+		if len([]rune(last)) != 2 {
+			value = strings.TrimSuffix(value, ".")
+		}
+	}
+
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,150 @@
+package marc
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+const (
+	XMLNamespace   = "http://www.loc.gov/MARC21/slim"
+	XMLContentType = "application/marcxml+xml"
+)
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlRecord struct {
+	XMLName       xml.Name          `xml:"record"`
+	Xmlns         string            `xml:"xmlns,attr,omitempty"`
+	Leader        string            `xml:"leader"`
+	ControlFields []xmlControlField `xml:"controlfield"`
+	DataFields    []xmlDataField    `xml:"datafield"`
+}
+
+type xmlReader struct {
+	d     *xml.Decoder
+	index int
+	err   error
+}
+
+func newXMLReader(r io.Reader) *xmlReader {
+	return &xmlReader{d: xml.NewDecoder(r)}
+}
+
+// Read decode the next record element, records may be wrapped in a collection or stand alone
+func (xr *xmlReader) Read() (*Record, error) {
+	// malformed xml cannot be resumed
+	if xr.err != nil {
+		return nil, xr.err
+	}
+
+	for {
+		token, err := xr.d.Token()
+		if err != nil {
+			xr.err = err
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		xr.index++
+
+		var x xmlRecord
+		if err := xr.d.DecodeElement(&x, &start); err != nil {
+			xr.err = err
+			return nil, err
+		}
+
+		return fromXML(&x), nil
+	}
+}
+
+// fromXML keep control and data fields apart, their order within each group is kept
+func fromXML(x *xmlRecord) *Record {
+	r := &Record{Leader: x.Leader}
+	for _, cf := range x.ControlFields {
+		r.Fields = append(r.Fields, Field{Tag: cf.Tag, Value: cf.Value})
+	}
+
+	for _, df := range x.DataFields {
+		f := Field{Tag: df.Tag, Ind1: df.Ind1, Ind2: df.Ind2}
+		for _, s := range df.Subfields {
+			f.Subfields = append(f.Subfields, Subfield{Code: s.Code, Value: s.Value})
+		}
+		r.Fields = append(r.Fields, f)
+	}
+
+	return r
+}
+
+func toXML(r *Record) *xmlRecord {
+	x := &xmlRecord{Leader: r.Leader}
+	for _, f := range r.Fields {
+		if f.IsControl() {
+			x.ControlFields = append(x.ControlFields, xmlControlField{Tag: f.Tag, Value: f.Value})
+			continue
+		}
+
+		df := xmlDataField{Tag: f.Tag, Ind1: indicator(f.Ind1), Ind2: indicator(f.Ind2)}
+		for _, s := range f.Subfields {
+			df.Subfields = append(df.Subfields, xmlSubfield{Code: s.Code, Value: s.Value})
+		}
+		x.DataFields = append(x.DataFields, df)
+	}
+
+	return x
+}
+
+// WriteXML encode a single record as a standalone MARCXML document
+func WriteXML(w io.Writer, r *Record) error {
+	x := toXML(r)
+	x.Xmlns = XMLNamespace
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(x); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteXMLCollection encode records wrapped in a collection element
+func WriteXMLCollection(w io.Writer, records []*Record) error {
+	if _, err := io.WriteString(w, xml.Header+`<collection xmlns="`+XMLNamespace+`">`+"\n"); err != nil {
+		return err
+	}
+
+	e := xml.NewEncoder(w)
+	e.Indent("  ", "  ")
+	for _, r := range records {
+		if err := e.Encode(toXML(r)); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "\n</collection>\n")
+	return err
+}
@@ -0,0 +1,226 @@
+// Package membercard render printable library cards in ID-1 size (85.60 x 53.98 mm),
+// the QR code hold the card number so any scanner acting as keyboard can read it
+package membercard
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	width   = 85.6
+	height  = 53.98
+	padding = 4.0
+
+	// png is rendered at 300 dpi
+	dotsPerMM = 300 / 25.4
+)
+
+var accent = color.RGBA{0xf4, 0xb0, 0x84, 0xff}
+
+type Card struct {
+	Library string
+	Name    string
+	Number  string
+	Since   time.Time
+}
+
+// Format describe a supported card file format
+type Format struct {
+	Name        string
+	Extension   string
+	ContentType string
+	write       func(w io.Writer, card *Card) error
+}
+
+var formats = map[string]Format{
+	"pdf": {Name: "pdf", Extension: "pdf", ContentType: "application/pdf", write: WritePDF},
+	"png": {Name: "png", Extension: "png", ContentType: "image/png", write: WritePNG},
+}
+
+// Lookup return format by case insensitive name
+func Lookup(name string) (Format, error) {
+	f, ok := formats[strings.ToLower(name)]
+	if !ok {
+		return Format{}, fmt.Errorf("Invalid format. Available formats: 'pdf', 'png'")
+	}
+
+	return f, nil
+}
+
+// Write render card into w
+func (f Format) Write(w io.Writer, card *Card) error {
+	return f.write(w, card)
+}
+
+// WritePDF write the card on a page of its own size, ready for a card printer
+func WritePDF(w io.Writer, card *Card) error {
+	code, err := qr.Encode(card.Number, qr.M, qr.Auto)
+	if err != nil {
+		return fmt.Errorf("failed to encode card number: %w", err)
+	}
+
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		OrientationStr: "L",
+		UnitStr:        "mm",
+		Size:           fpdf.SizeType{Wd: height, Ht: width},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	// header band with library name
+	pdf.SetFillColor(int(accent.R), int(accent.G), int(accent.B))
+	pdf.Rect(0, 0, width, 12, "F")
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.SetXY(padding, 3)
+	pdf.CellFormat(width-2*padding, 6, translate(card.Library), "", 0, "L", false, 0, "")
+
+	// qr code on the right, details on the left
+	size := height - 12 - 2*padding
+	qrX, qrY := width-padding-size, 12+padding
+	bounds := code.Bounds()
+	module := size / float64(bounds.Dx())
+	pdf.SetFillColor(0, 0, 0)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if isDark(code.At(x, y)) {
+				pdf.Rect(qrX+float64(x-bounds.Min.X)*module, qrY+float64(y-bounds.Min.Y)*module, module, module, "F")
+			}
+		}
+	}
+
+	textWidth := qrX - 2*padding
+	pdf.SetXY(padding, 12+padding)
+	pdf.SetFont("Helvetica", "", 7)
+	pdf.CellFormat(textWidth, 4, "MEMBER", "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(textWidth, 6, translate(card.Name), "", 2, "L", false, 0, "")
+
+	pdf.Ln(2)
+	pdf.SetFont("Helvetica", "", 7)
+	pdf.CellFormat(textWidth, 4, "CARD NUMBER", "", 2, "L", false, 0, "")
+	pdf.SetFont("Courier", "B", 11)
+	pdf.CellFormat(textWidth, 6, GroupDigits(card.Number), "", 2, "L", false, 0, "")
+
+	pdf.SetXY(padding, height-padding-4)
+	pdf.SetFont("Helvetica", "", 7)
+	pdf.CellFormat(textWidth, 4, "Member since "+card.Since.Format("January 2006"), "", 0, "L", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+// WritePNG write the card as a 300 dpi image with the same layout as the PDF
+func WritePNG(w io.Writer, card *Card) error {
+	code, err := qr.Encode(card.Number, qr.M, qr.Auto)
+	if err != nil {
+		return fmt.Errorf("failed to encode card number: %w", err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, px(width), px(height)))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, px(width), px(12)), image.NewUniform(accent), image.Point{}, draw.Src)
+
+	size := px(height - 12 - 2*padding)
+	scaled, err := barcode.Scale(code, size, size)
+	if err != nil {
+		return fmt.Errorf("failed to scale QR code: %w", err)
+	}
+	qrX, qrY := px(width-padding)-size, px(12+padding)
+	draw.Draw(img, image.Rect(qrX, qrY, qrX+size, qrY+size), scaled, image.Point{}, draw.Src)
+
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return err
+	}
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return err
+	}
+
+	// baselines match the cells of the PDF layout
+	texts := []struct {
+		font  *opentype.Font
+		size  float64
+		x, y  float64
+		value string
+	}{
+		{bold, 12, padding, 7.5, card.Library},
+		{regular, 7, padding, 12 + padding + 3, "MEMBER"},
+		{bold, 11, padding, 12 + padding + 8.5, card.Name},
+		{regular, 7, padding, 12 + padding + 15, "CARD NUMBER"},
+		{bold, 11, padding, 12 + padding + 20.5, GroupDigits(card.Number)},
+		{regular, 7, padding, height - padding - 1, "Member since " + card.Since.Format("January 2006")},
+	}
+
+	for _, t := range texts {
+		face, err := opentype.NewFace(t.font, &opentype.FaceOptions{Size: t.size, DPI: 300, Hinting: font.HintingFull})
+		if err != nil {
+			return err
+		}
+
+		d := &font.Drawer{
+			Dst:  img,
+			Src:  image.Black,
+			Face: face,
+			Dot:  fixed.P(px(t.x), px(t.y)),
+		}
+		d.DrawString(fit(d, t.value, qrX-px(2*padding)))
+		face.Close()
+	}
+
+	return png.Encode(w, img)
+}
+
+// GroupDigits group card number digits by four for reading, e.g. 2900 1234 5678 90
+func GroupDigits(number string) string {
+	var b strings.Builder
+	for i, r := range number {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// fit truncate text so it fits in width pixels
+func fit(d *font.Drawer, text string, width int) string {
+	limit := fixed.I(width)
+	if d.MeasureString(text) <= limit {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && d.MeasureString(string(runes)+"...") > limit {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "..."
+}
+
+func px(mm float64) int {
+	return int(mm * dotsPerMM)
+}
+
+func isDark(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r+g+b < 3*0x8000
+}
@@ -0,0 +1,26 @@
+package book
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AvailabilityDay is projected number of copies on the shelf at the end of a day,
+// assuming every open loan is returned on its due date
+type AvailabilityDay struct {
+	Date      string `json:"date"`
+	Available int    `json:"available"`
+	Returning int    `json:"returning"` // loans due on this day
+}
+
+type Availability struct {
+	BookID          uuid.UUID         `json:"book_id"`
+	TotalCopies     int               `json:"total_copies"`
+	AvailableCopies int               `json:"available_copies"`
+	OpenLoans       int               `json:"open_loans"`
+	OverdueLoans    int               `json:"overdue_loans"` // not counted in the projection
+	QueueLength     int               `json:"queue_length"`  // holds are not supported yet, always 0
+	NextAvailable   *time.Time        `json:"next_available_date"`
+	Calendar        []AvailabilityDay `json:"calendar"`
+}
@@ -1,10 +1,15 @@
 package book
 
 import (
+	"time"
+
 	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/location"
+	"github.com/google/uuid"
 )
 
 type BookRequest struct {
+	ISBN            string `json:"isbn,omitempty" validate:"omitempty,isbn"`
 	Title           string `json:"title,omitempty" validate:"required"`
 	Author          string `json:"author,omitempty" validate:"required"`
 	Genre           string `json:"genre,omitempty" validate:"required"`
@@ -17,9 +22,60 @@ type BookRequest struct {
 	VideoURL        string `json:"video_url,omitempty"`
 	Summary         string `json:"summary,omitempty" validate:"required"`
 	Price           int    `json:"price,omitempty" validate:"required"`
+
+	// edition of a work, books without work stand alone
+	WorkID        *uuid.UUID `json:"work_id,omitempty"`
+	Publisher     string     `json:"publisher,omitempty" validate:"max=255"`
+	PublishedYear *int       `json:"published_year,omitempty" validate:"omitempty,min=1,max=9999"`
+	Language      string     `json:"language,omitempty" validate:"max=35"`
+	Edition       string     `json:"edition,omitempty" validate:"max=100"`
+
+	// shelf classification, DDC or LCC
+	ClassificationScheme string     `json:"classification_scheme,omitempty" validate:"omitempty,oneof=DDC LCC"`
+	CallNumber           string     `json:"call_number,omitempty" validate:"max=100"`
+	ShelfID              *uuid.UUID `json:"shelf_id,omitempty"`
 }
 
 type Book struct {
 	model.Base
 	BookRequest
+	RatingAverage float64 `json:"rating_average"` // average of published member reviews
+	RatingCount   int     `json:"rating_count"`
+
+	CallNumberSort string `json:"-"` // shelf order of call number
+}
+
+// BookDetail is book with data relative to the caller
+type BookDetail struct {
+	Book
+	ReadingLists []model.SimpleResponse `json:"reading_lists"` // caller's lists containing the book
+	Work         *Work                  `json:"work,omitempty"`
+	Editions     []Edition              `json:"editions,omitempty"` // other editions of the same work
+	NextVolume   *NextVolume            `json:"next_volume,omitempty"`
+	Location     []location.Location    `json:"location,omitempty"` // shelf of the book, floor first
+	JSONLD       *SchemaBook            `json:"json_ld"`
+}
+
+type BookQuery struct {
+	model.QueryParam
+	CollapseEditions bool      `query:"collapse_editions"` // show one edition per work
+	ShelfID          uuid.UUID `query:"shelf_id,omitempty"`
+	Genre            string    `query:"genre,omitempty"`  // exact genre, case insensitive
+	Author           string    `query:"author,omitempty"` // part of author name
+
+	// last modification range, used by catalog harvesting
+	ModifiedFrom  *time.Time `query:"-"`
+	ModifiedUntil *time.Time `query:"-"`
+
+	// keyset of catalog harvesting, only books after this (created_at, id) are listed
+	AfterCreatedAt *time.Time `query:"-"`
+	AfterID        uuid.UUID  `query:"-"`
+}
+
+// Suggestion is a single autocomplete entry, book id is only set for titles
+type Suggestion struct {
+	Type       string     `json:"type"` // title, author or genre
+	Value      string     `json:"value"`
+	BookID     *uuid.UUID `json:"book_id,omitempty"`
+	Popularity int        `json:"popularity"` // number of borrows
 }
@@ -0,0 +1,17 @@
+package book
+
+type GenreCount struct {
+	Genre string `json:"genre"`
+	Total int    `json:"total"`
+}
+
+type BorrowHistory struct {
+	Year             int          `json:"year"`
+	TotalLoans       int          `json:"total_loans"`
+	BooksRead        int          `json:"books_read"`
+	FavouriteGenres  []GenreCount `json:"favourite_genres"`
+	TotalSpent       int          `json:"total_spent"`
+	AverageLoanDays  float64      `json:"average_loan_days"`
+	ReturnedLoans    int          `json:"returned_loans"`
+	OnTimeReturnRate float64      `json:"on_time_return_rate"`
+}
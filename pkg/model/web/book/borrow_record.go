@@ -5,6 +5,7 @@ import (
 
 	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
 	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/event"
 	"github.com/google/uuid"
 )
 
@@ -12,6 +13,9 @@ type BorrowRequest struct {
 	BookIDs []uuid.UUID `json:"book_ids,omitempty" validate:"required"`
 	UserID  uuid.UUID   `json:"user_id,omitempty" validate:"required"`
 	DueDate time.Time   `json:"due_date,omitempty"`
+
+	// card scanned at the desk, staff lend to its holder instead of themselves
+	CardNumber string `json:"card_number,omitempty"`
 }
 
 type BorrowRecord struct {
@@ -49,8 +53,62 @@ type BorrowQuery struct {
 	UserID    uuid.UUID `query:"user_id,omitempty"`
 	BookID    uuid.UUID `query:"book_id,omitempty"`
 	Status    string    `query:"status,omitempty"`
-	StartDate time.Time `query:"start_date,omitempty"`
-	EndDate   time.Time `query:"end_date,omitempty"`
+	StartDate time.Time `query:"-"`
+	EndDate   time.Time `query:"-"`
+	Timezone  string    `query:"-"` // IANA zone used for date filters and output
+
+	// raw date filter, interpreted in Timezone by Localize
+	RawStartDate string `query:"start_date,omitempty" json:"-"`
+	RawEndDate   string `query:"end_date,omitempty" json:"-"`
+}
+
+// Localize parse raw date filters in the given zone,
+// a date only end_date includes the whole day
+func (q *BorrowQuery) Localize(loc *time.Location) error {
+	q.Timezone = loc.String()
+
+	if q.RawStartDate != "" {
+		t, err := lib.ParseDateIn(q.RawStartDate, loc, false)
+		if err != nil {
+			return err
+		}
+		q.StartDate = t
+	}
+
+	if q.RawEndDate != "" {
+		t, err := lib.ParseDateIn(q.RawEndDate, loc, true)
+		if err != nil {
+			return err
+		}
+		q.EndDate = t
+	}
+
+	return nil
+}
+
+// Location return zone of the query, fallback to default zone
+func (q *BorrowQuery) Location() *time.Location {
+	if loc, err := lib.LoadLocation(q.Timezone); err == nil {
+		return loc
+	}
+	return lib.DefaultLocation()
+}
+
+// In render every date of the response in the given zone
+func (r *BorrowResponse) In(loc *time.Location) *BorrowResponse {
+	r.BorrowDate = r.BorrowDate.In(loc)
+	r.DueDate = r.DueDate.In(loc)
+	r.ReturnedDate = lib.TimePtrIn(r.ReturnedDate, loc)
+	r.CreatedAt = lib.TimePtrIn(r.CreatedAt, loc)
+	r.UpdatedAt = lib.TimePtrIn(r.UpdatedAt, loc)
+	return r
+}
+
+// In render every date of the response in the given zone
+func (r *BorrowUserResponse) In(loc *time.Location) *BorrowUserResponse {
+	r.BorrowDate = r.BorrowDate.In(loc)
+	r.DueDate = r.DueDate.In(loc)
+	return r
 }
 
 func (req *BorrowRequest) ToBorrowRecord() []BorrowRecord {
@@ -127,3 +185,17 @@ func ModelToUserResponse(borrows []BorrowRecord) *BorrowUserResponse {
 
 	return DtoToUserResponse(dtos)
 }
+
+// ToEvent return the payload of borrow events
+func (r *BorrowRecord) ToEvent() event.Borrow {
+	return event.Borrow{
+		ID:         r.ID,
+		BookID:     r.BookID,
+		UserID:     r.UserID,
+		BorrowDate: r.BorrowDate,
+		DueDate:    r.DueDate,
+		ReturnDate: r.ReturnedDate,
+		Status:     r.Status,
+		TotalPrice: r.TotalPrice,
+	}
+}
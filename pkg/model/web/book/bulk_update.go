@@ -0,0 +1,20 @@
+package book
+
+import "github.com/google/uuid"
+
+// BulkUpdateRow describe the outcome of a single sheet row,
+// row is the 1-based row number in the sheet including the header row
+type BulkUpdateRow struct {
+	Row     int        `json:"row"`
+	ID      *uuid.UUID `json:"id,omitempty"`
+	Title   string     `json:"title,omitempty"`
+	Changes []string   `json:"changes,omitempty"`
+	Reason  string     `json:"reason,omitempty"`
+}
+
+type BulkUpdateResult struct {
+	Created   []BulkUpdateRow `json:"created"`
+	Updated   []BulkUpdateRow `json:"updated"`
+	Unchanged []BulkUpdateRow `json:"unchanged"`
+	Rejected  []BulkUpdateRow `json:"rejected"`
+}
@@ -0,0 +1,17 @@
+package book
+
+import (
+	"github.com/dikyayodihamzah/library-management-api/pkg/label"
+	"github.com/google/uuid"
+)
+
+type LabelRequest struct {
+	BookIDs []uuid.UUID `json:"book_ids" validate:"required,min=1,max=1000"`
+	Copies  int         `json:"copies" validate:"min=0,max=100"` // labels printed per book, 1 when empty
+	Barcode string      `json:"barcode" validate:"omitempty,oneof=code128 qr"`
+	Skip    int         `json:"skip" validate:"min=0"` // labels already used on the first sheet
+
+	// predefined sheet, ignored when a custom layout is given
+	Layout       string        `json:"layout"`
+	CustomLayout *label.Layout `json:"custom_layout"`
+}
@@ -0,0 +1,24 @@
+package book
+
+import "github.com/google/uuid"
+
+// MarcImportRequest hold values MARC records do not carry, they are applied to every imported book
+type MarcImportRequest struct {
+	TotalCopies int `form:"total_copies" validate:"min=0"` // default to 1
+	Rating      int `form:"rating" validate:"min=0,max=5"` // default to 3
+	Price       int `form:"price" validate:"required"`
+}
+
+// MarcImportRow describe the outcome of a single record,
+// record is the 1-based position of the record in the file
+type MarcImportRow struct {
+	Record int        `json:"record"`
+	ID     *uuid.UUID `json:"id,omitempty"`
+	Title  string     `json:"title,omitempty"`
+	Errors []string   `json:"errors,omitempty"`
+}
+
+type MarcImportResult struct {
+	Created  []MarcImportRow `json:"created"`
+	Rejected []MarcImportRow `json:"rejected"`
+}
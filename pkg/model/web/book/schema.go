@@ -0,0 +1,137 @@
+package book
+
+import (
+	"strconv"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/citation"
+	"github.com/dikyayodihamzah/library-management-api/pkg/lib"
+)
+
+// SchemaBook is a schema.org Book embedded as JSON-LD in public catalog pages
+type SchemaBook struct {
+	Context         string        `json:"@context"`
+	Type            string        `json:"@type"`
+	ID              string        `json:"@id"`
+	URL             string        `json:"url"`
+	Name            string        `json:"name"`
+	Author          []SchemaThing `json:"author,omitempty"`
+	ISBN            string        `json:"isbn,omitempty"`
+	Genre           string        `json:"genre,omitempty"`
+	Description     string        `json:"description,omitempty"`
+	Image           string        `json:"image,omitempty"`
+	Publisher       *SchemaThing  `json:"publisher,omitempty"`
+	DatePublished   string        `json:"datePublished,omitempty"`
+	BookEdition     string        `json:"bookEdition,omitempty"`
+	InLanguage      string        `json:"inLanguage,omitempty"`
+	AggregateRating *SchemaRating `json:"aggregateRating,omitempty"`
+	Offers          *SchemaOffer  `json:"offers,omitempty"`
+}
+
+type SchemaThing struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type SchemaRating struct {
+	Type        string  `json:"@type"`
+	RatingValue float64 `json:"ratingValue"`
+	RatingCount int     `json:"ratingCount"`
+	BestRating  int     `json:"bestRating"`
+	WorstRating int     `json:"worstRating"`
+}
+
+// SchemaOffer describe lending of the book, price is the loan price per day
+type SchemaOffer struct {
+	Type          string `json:"@type"`
+	Price         int    `json:"price"`
+	PriceCurrency string `json:"priceCurrency"`
+	Availability  string `json:"availability"`
+}
+
+// Schema return schema.org representation of the book
+func (b *Book) Schema() *SchemaBook {
+	url := lib.PublicURL("books", b.ID.String())
+
+	s := &SchemaBook{
+		Context:     "https://schema.org",
+		Type:        "Book",
+		ID:          url,
+		URL:         url,
+		Name:        b.Title,
+		ISBN:        b.ISBN,
+		Genre:       b.Genre,
+		Description: b.Summary,
+		Image:       lib.AssignMinioPrefix(b.CoverURL),
+		BookEdition: b.Edition,
+		InLanguage:  b.Language,
+		Offers: &SchemaOffer{
+			Type:          "Offer",
+			Price:         b.Price,
+			PriceCurrency: "IDR",
+			Availability:  "https://schema.org/InStock",
+		},
+	}
+
+	for _, name := range citation.SplitAuthors(b.Author) {
+		s.Author = append(s.Author, SchemaThing{Type: "Person", Name: name})
+	}
+
+	if b.Publisher != "" {
+		s.Publisher = &SchemaThing{Type: "Organization", Name: b.Publisher}
+	}
+
+	if b.PublishedYear != nil {
+		s.DatePublished = strconv.Itoa(*b.PublishedYear)
+	}
+
+	if b.RatingCount > 0 {
+		s.AggregateRating = &SchemaRating{
+			Type:        "AggregateRating",
+			RatingValue: b.RatingAverage,
+			RatingCount: b.RatingCount,
+			BestRating:  5,
+			WorstRating: 1,
+		}
+	}
+
+	if b.AvailableCopies == 0 {
+		s.Offers.Availability = "https://schema.org/OutOfStock"
+	}
+
+	return s
+}
+
+// Citation return bibliographic data of the book for citation export
+func (b *Book) Citation() citation.Work {
+	w := citation.Work{
+		ID:        b.ID.String(),
+		Title:     b.Title,
+		Authors:   citation.SplitAuthors(b.Author),
+		Publisher: b.Publisher,
+		Edition:   b.Edition,
+		ISBN:      b.ISBN,
+		Language:  b.Language,
+		URL:       lib.PublicURL("books", b.ID.String()),
+		Abstract:  b.Summary,
+	}
+
+	if b.PublishedYear != nil {
+		w.Year = *b.PublishedYear
+	}
+
+	if b.Genre != "" {
+		w.Keywords = []string{b.Genre}
+	}
+
+	return w
+}
+
+// Citations return bibliographic data of every book in the given order
+func Citations(books []Book) []citation.Work {
+	works := make([]citation.Work, 0, len(books))
+	for i := range books {
+		works = append(works, books[i].Citation())
+	}
+
+	return works
+}
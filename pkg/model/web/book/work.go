@@ -0,0 +1,59 @@
+package book
+
+import (
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+type SeriesRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description"`
+}
+
+type Series struct {
+	model.Base
+	SeriesRequest
+}
+
+type SeriesDetail struct {
+	Series
+	Works []Work `json:"works"` // ordered by volume number
+}
+
+type WorkRequest struct {
+	Title        string     `json:"title" validate:"required,max=255"`
+	Author       string     `json:"author" validate:"required,max=255"`
+	SeriesID     *uuid.UUID `json:"series_id,omitempty"`
+	VolumeNumber *int       `json:"volume_number,omitempty" validate:"omitempty,min=1"`
+}
+
+// Work group every edition of the same text
+type Work struct {
+	model.Base
+	WorkRequest
+	SeriesName *string `json:"series_name,omitempty"`
+}
+
+type WorkDetail struct {
+	Work
+	Editions []Edition `json:"editions"`
+}
+
+// Edition is a book seen as one edition of its work
+type Edition struct {
+	ID              uuid.UUID `json:"id"`
+	Title           string    `json:"title"`
+	Publisher       string    `json:"publisher"`
+	PublishedYear   *int      `json:"published_year"`
+	Language        string    `json:"language"`
+	Edition         string    `json:"edition"`
+	AvailableCopies int       `json:"available_copies"`
+}
+
+// NextVolume is the following work of a series with one of its editions to borrow
+type NextVolume struct {
+	WorkID       uuid.UUID  `json:"work_id"`
+	Title        string     `json:"title"`
+	VolumeNumber int        `json:"volume_number"`
+	BookID       *uuid.UUID `json:"book_id,omitempty"`
+}
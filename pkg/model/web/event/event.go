@@ -0,0 +1,64 @@
+package event
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is a domain event, it is kept in the outbox until the relay publish it.
+// Delivery is at least once, consumers should skip IDs they have already seen
+type Event struct {
+	Seq           int64           `json:"-"` // outbox order
+	ID            uuid.UUID       `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"data"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	PublishedAt   *time.Time      `json:"-"`
+}
+
+// New create an event of the aggregate, aggregate type is the prefix of event type,
+// e.g. book for book.created
+func New(eventType string, aggregateID uuid.UUID, data interface{}) (Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+
+	aggregateType, _, _ := strings.Cut(eventType, ".")
+
+	return Event{
+		ID:            uuid.New(),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       payload,
+		OccurredAt:    time.Now(),
+	}, nil
+}
+
+// Borrow is the payload of borrow events
+type Borrow struct {
+	ID         uuid.UUID  `json:"id"`
+	BookID     uuid.UUID  `json:"book_id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	BorrowDate time.Time  `json:"borrow_date"`
+	DueDate    time.Time  `json:"due_date"`
+	ReturnDate *time.Time `json:"return_date,omitempty"`
+	Status     string     `json:"status"`
+	TotalPrice int        `json:"total_price"`
+}
+
+// User is the payload of user events, credentials are never published
+type User struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	Email      string    `json:"email"`
+	Role       string    `json:"role"`
+	CardNumber string    `json:"card_number,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,49 @@
+package export
+
+import (
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/constant"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/book"
+	"github.com/google/uuid"
+)
+
+type Job struct {
+	model.Base
+	UserID    uuid.UUID        `json:"user_id"`
+	Status    string           `json:"status"`
+	Format    string           `json:"format"`
+	Filter    book.BorrowQuery `json:"filter"`
+	Timezone  string           `json:"timezone"`
+	Progress  int              `json:"progress"` // written rows
+	Total     int              `json:"total"`    // total rows to write
+	FileName  string           `json:"file_name"`
+	Error     *string          `json:"error,omitempty"`
+	ExpiresAt *time.Time       `json:"expires_at,omitempty"`
+}
+
+type JobResponse struct {
+	Job
+	Percentage  float64 `json:"percentage"`
+	DownloadURL string  `json:"download_url,omitempty"`
+}
+
+func (j *Job) ToResponse() *JobResponse {
+	res := &JobResponse{Job: *j}
+	if j.Total > 0 {
+		res.Percentage = float64(j.Progress) / float64(j.Total) * 100
+	} else if j.Status == constant.ExportStatus_Finished {
+		res.Percentage = 100
+	}
+
+	if j.Status == constant.ExportStatus_Finished {
+		res.DownloadURL = "/borrows/exports/" + j.ID.String() + "/download"
+	}
+
+	return res
+}
+
+func (j *Job) IsExpired() bool {
+	return j.ExpiresAt != nil && j.ExpiresAt.Before(time.Now())
+}
@@ -0,0 +1,55 @@
+// Package feed hold Atom 1.0 documents, see RFC 4287
+package feed
+
+import "encoding/xml"
+
+const (
+	Namespace   = "http://www.w3.org/2005/Atom"
+	ContentType = "application/atom+xml; charset=utf-8"
+
+	// dates are RFC 3339 timestamps
+	DateLayout = "2006-01-02T15:04:05Z07:00"
+)
+
+type Feed struct {
+	XMLName xml.Name `xml:"feed"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Author  *Person  `xml:"author,omitempty"`
+	Links   []Link   `xml:"link"`
+	Entries []Entry  `xml:"entry"`
+}
+
+type Entry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published,omitempty"`
+	Authors   []Person   `xml:"author"`
+	Category  []Category `xml:"category"`
+	Links     []Link     `xml:"link"`
+	Summary   *Text      `xml:"summary,omitempty"`
+	Content   *Text      `xml:"content,omitempty"`
+}
+
+type Person struct {
+	Name string `xml:"name"`
+}
+
+type Category struct {
+	Term string `xml:"term,attr"`
+}
+
+type Link struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+// Text is a text construct, Type is text or html
+type Text struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
@@ -0,0 +1,36 @@
+package job
+
+import (
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+// Job is a scheduled job and its current state
+type Job struct {
+	Name      string     `json:"name"`
+	Schedule  string     `json:"schedule"`
+	Paused    bool       `json:"paused"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	LastRun   *Run       `json:"last_run,omitempty"`
+}
+
+// Run is one execution of a job
+type Run struct {
+	ID         uuid.UUID  `json:"id"`
+	JobName    string     `json:"job_name"`
+	Trigger    string     `json:"trigger"`
+	Status     string     `json:"status"`
+	Result     *string    `json:"result,omitempty"`
+	Error      *string    `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type RunQuery struct {
+	model.QueryParam
+	JobName string `query:"job_name,omitempty"`
+	Status  string `query:"status,omitempty"`
+	Trigger string `query:"trigger,omitempty"`
+}
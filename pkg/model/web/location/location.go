@@ -0,0 +1,31 @@
+package location
+
+import (
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+type LocationRequest struct {
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+	Type     string     `json:"type" validate:"required,oneof=FLOOR SECTION SHELF"`
+	Code     string     `json:"code" validate:"required,max=50"`
+	Name     string     `json:"name" validate:"max=255"`
+}
+
+type Location struct {
+	model.Base
+	LocationRequest
+}
+
+type LocationQuery struct {
+	model.QueryParam
+	ParentID uuid.UUID `query:"parent_id,omitempty"`
+	Type     string    `query:"type,omitempty"`
+}
+
+// LocationDetail is location with its ancestors, floor first
+type LocationDetail struct {
+	Location
+	Path     []Location `json:"path"`
+	Children []Location `json:"children"`
+}
@@ -0,0 +1,69 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+// Notification is a rendered message and its delivery state
+type Notification struct {
+	model.Base
+	UserID        uuid.UUID  `json:"user_id"`
+	Type          string     `json:"type"`
+	Channel       string     `json:"channel"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	BodyText      string     `json:"-"`
+	BodyHTML      string     `json:"-"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     *string    `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
+
+type NotificationQuery struct {
+	model.QueryParam
+	UserID  uuid.UUID `query:"user_id,omitempty"`
+	Type    string    `query:"type,omitempty"`
+	Channel string    `query:"channel,omitempty"`
+	Status  string    `query:"status,omitempty"`
+}
+
+type Preference struct {
+	Type    string `json:"type" validate:"required"`
+	Channel string `json:"channel" validate:"required"`
+	Enabled bool   `json:"enabled"`
+}
+
+type PreferenceRequest struct {
+	Preferences []Preference `json:"preferences" validate:"required,dive"`
+}
+
+// LoanData is rendered by loan_confirmation templates
+type LoanData struct {
+	Books      []model.SimpleResponse
+	BorrowDate time.Time
+	DueDate    time.Time
+	TotalPrice int
+}
+
+// ReturnData is rendered by return_receipt templates
+type ReturnData struct {
+	Books      []model.SimpleResponse
+	ReturnedAt time.Time
+}
+
+// DueData is rendered by due_soon and overdue templates
+type DueData struct {
+	Loans []DueLoan
+}
+
+type DueLoan struct {
+	BookID      uuid.UUID
+	Title       string
+	DueDate     time.Time
+	DaysOverdue int
+}
@@ -0,0 +1,140 @@
+// Package oai hold OAI-PMH 2.0 responses, see http://www.openarchives.org/OAI/2.0/openarchivesprotocol.htm
+package oai
+
+import "encoding/xml"
+
+const (
+	Namespace      = "http://www.openarchives.org/OAI/2.0/"
+	SchemaLocation = Namespace + " http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd"
+	XSINamespace   = "http://www.w3.org/2001/XMLSchema-instance"
+
+	DCPrefix         = "oai_dc"
+	DCNamespace      = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	DCSchema         = "http://www.openarchives.org/OAI/2.0/oai_dc.xsd"
+	DCElementsNS     = "http://purl.org/dc/elements/1.1/"
+	DCSchemaLocation = DCNamespace + " " + DCSchema
+)
+
+// Request echo the arguments of a valid request, arguments are left out when the request is rejected
+type Request struct {
+	Verb            string `xml:"verb,attr,omitempty"`
+	Identifier      string `xml:"identifier,attr,omitempty"`
+	MetadataPrefix  string `xml:"metadataPrefix,attr,omitempty"`
+	From            string `xml:"from,attr,omitempty"`
+	Until           string `xml:"until,attr,omitempty"`
+	Set             string `xml:"set,attr,omitempty"`
+	ResumptionToken string `xml:"resumptionToken,attr,omitempty"`
+	BaseURL         string `xml:",chardata"`
+}
+
+type Error struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+type Response struct {
+	XMLName        xml.Name `xml:"OAI-PMH"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	XmlnsXSI       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	ResponseDate   string   `xml:"responseDate"`
+	Request        Request  `xml:"request"`
+	Errors         []Error  `xml:"error,omitempty"`
+
+	Identify            *Identify            `xml:"Identify,omitempty"`
+	ListMetadataFormats *ListMetadataFormats `xml:"ListMetadataFormats,omitempty"`
+	ListIdentifiers     *ListIdentifiers     `xml:"ListIdentifiers,omitempty"`
+	ListRecords         *ListRecords         `xml:"ListRecords,omitempty"`
+	GetRecord           *GetRecord           `xml:"GetRecord,omitempty"`
+}
+
+type Identify struct {
+	RepositoryName    string `xml:"repositoryName"`
+	BaseURL           string `xml:"baseURL"`
+	ProtocolVersion   string `xml:"protocolVersion"`
+	AdminEmail        string `xml:"adminEmail"`
+	EarliestDatestamp string `xml:"earliestDatestamp"`
+	DeletedRecord     string `xml:"deletedRecord"`
+	Granularity       string `xml:"granularity"`
+}
+
+type MetadataFormat struct {
+	MetadataPrefix    string `xml:"metadataPrefix"`
+	Schema            string `xml:"schema"`
+	MetadataNamespace string `xml:"metadataNamespace"`
+}
+
+type ListMetadataFormats struct {
+	MetadataFormats []MetadataFormat `xml:"metadataFormat"`
+}
+
+type Header struct {
+	Identifier string `xml:"identifier"`
+	Datestamp  string `xml:"datestamp"`
+}
+
+type Record struct {
+	Header   Header   `xml:"header"`
+	Metadata Metadata `xml:"metadata"`
+}
+
+type Metadata struct {
+	DC DublinCore `xml:"oai_dc:dc"`
+}
+
+// DublinCore is a record in oai_dc format, namespaces are declared on the element itself
+type DublinCore struct {
+	XmlnsOAIDC     string   `xml:"xmlns:oai_dc,attr"`
+	XmlnsDC        string   `xml:"xmlns:dc,attr"`
+	XmlnsXSI       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Title          string   `xml:"dc:title"`
+	Creator        string   `xml:"dc:creator,omitempty"`
+	Subject        string   `xml:"dc:subject,omitempty"`
+	Description    string   `xml:"dc:description,omitempty"`
+	Publisher      string   `xml:"dc:publisher,omitempty"`
+	Date           string   `xml:"dc:date,omitempty"`
+	Type           string   `xml:"dc:type"`
+	Identifiers    []string `xml:"dc:identifier"`
+	Language       string   `xml:"dc:language,omitempty"`
+}
+
+// ResumptionToken is empty on the last page of an incomplete list
+type ResumptionToken struct {
+	CompleteListSize int    `xml:"completeListSize,attr"`
+	Cursor           int    `xml:"cursor,attr"`
+	Value            string `xml:",chardata"`
+}
+
+type ListIdentifiers struct {
+	Headers         []Header         `xml:"header"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+type ListRecords struct {
+	Records         []Record         `xml:"record"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+type GetRecord struct {
+	Record Record `xml:"record"`
+}
+
+// error codes defined by the protocol
+const (
+	ErrBadArgument             = "badArgument"
+	ErrBadResumptionToken      = "badResumptionToken"
+	ErrBadVerb                 = "badVerb"
+	ErrCannotDisseminateFormat = "cannotDisseminateFormat"
+	ErrIDDoesNotExist          = "idDoesNotExist"
+	ErrNoRecordsMatch          = "noRecordsMatch"
+	ErrNoSetHierarchy          = "noSetHierarchy"
+)
+
+func NewError(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
@@ -0,0 +1,45 @@
+package readinglist
+
+import (
+	"time"
+
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+type ListRequest struct {
+	Name       string `json:"name" validate:"required,max=100"`
+	Visibility string `json:"visibility" validate:"omitempty,oneof=PRIVATE PUBLIC"`
+}
+
+type ItemRequest struct {
+	BookID uuid.UUID `json:"book_id" validate:"required"`
+}
+
+type ReorderRequest struct {
+	BookIDs []uuid.UUID `json:"book_ids" validate:"required,min=1,dive,required"`
+}
+
+type ReadingList struct {
+	model.Base
+	UserID     uuid.UUID `json:"user_id"`
+	Name       string    `json:"name"`
+	IsDefault  bool      `json:"is_default"`
+	Visibility string    `json:"visibility"`
+	ShareToken *string   `json:"share_token,omitempty"`
+	TotalItems int       `json:"total_items"`
+}
+
+type Item struct {
+	BookID   uuid.UUID `json:"book_id"`
+	Title    string    `json:"title"`
+	Author   string    `json:"author"`
+	CoverURL string    `json:"cover_url"`
+	Position int       `json:"position"`
+	AddedAt  time.Time `json:"added_at"`
+}
+
+type ReadingListDetail struct {
+	ReadingList
+	Items []Item `json:"items"`
+}
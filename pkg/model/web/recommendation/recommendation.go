@@ -0,0 +1,16 @@
+package recommendation
+
+import (
+	"github.com/google/uuid"
+)
+
+type Recommendation struct {
+	BookID     uuid.UUID `json:"book_id"`
+	Title      string    `json:"title"`
+	Author     string    `json:"author"`
+	Genre      string    `json:"genre"`
+	CoverURL   string    `json:"cover_url"`
+	CoverColor string    `json:"cover_color"`
+	Score      float64   `json:"score"`
+	Reason     string    `json:"reason"`
+}
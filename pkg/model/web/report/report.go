@@ -0,0 +1,129 @@
+package report
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReportQuery struct {
+	StartDate   time.Time `query:"start_date,omitempty"`
+	EndDate     time.Time `query:"end_date,omitempty"`
+	GroupBy     string    `query:"group_by,omitempty"` // day, week or month
+	BookID      uuid.UUID `query:"book_id,omitempty"`
+	Page        int       `query:"page,omitempty"`
+	Limit       int       `query:"limit,omitempty"`
+	DormantDays int       `query:"dormant_days,omitempty"`
+}
+
+// Row is a report row that can be written to a spreadsheet
+type Row interface {
+	ExcelHeaders() []string
+	ExcelRow() []interface{}
+}
+
+type MostBorrowed struct {
+	Period       *time.Time `json:"period,omitempty"`
+	BookID       uuid.UUID  `json:"book_id"`
+	Title        string     `json:"title"`
+	Author       string     `json:"author"`
+	TotalBorrows int        `json:"total_borrows"`
+	Revenue      int        `json:"revenue"`
+}
+
+type Revenue struct {
+	Period       time.Time `json:"period"`
+	TotalBorrows int       `json:"total_borrows"`
+	Revenue      int       `json:"revenue"`
+}
+
+type MemberActivity struct {
+	Period        time.Time `json:"period"`
+	NewMembers    int       `json:"new_members"`
+	ActiveMembers int       `json:"active_members"`
+}
+
+type MemberSummary struct {
+	TotalMembers   int              `json:"total_members"`
+	ActiveMembers  int              `json:"active_members"`
+	DormantMembers int              `json:"dormant_members"`
+	DormantDays    int              `json:"dormant_days"`
+	Periods        []MemberActivity `json:"periods"`
+}
+
+type ReturnTime struct {
+	Period          time.Time `json:"period"`
+	ReturnedLoans   int       `json:"returned_loans"`
+	AverageDays     float64   `json:"average_days"`
+	OnTimeReturns   int       `json:"on_time_returns"`
+	OnTimeReturnPct float64   `json:"on_time_return_pct"`
+}
+
+type Utilization struct {
+	Period          time.Time `json:"period"`
+	BookID          uuid.UUID `json:"book_id"`
+	Title           string    `json:"title"`
+	TotalCopies     int       `json:"total_copies"`
+	OnLoan          int       `json:"on_loan"`
+	AvailableCopies int       `json:"available_copies"`
+	Availability    float64   `json:"availability"` // available_copies / total_copies
+	Utilization     float64   `json:"utilization"`  // on_loan / total_copies
+}
+
+// ToSheet convert report rows into spreadsheet headers and values
+func ToSheet[T Row](rows []T) ([]string, [][]interface{}) {
+	var zero T
+	values := make([][]interface{}, 0)
+	for _, row := range rows {
+		values = append(values, row.ExcelRow())
+	}
+
+	return zero.ExcelHeaders(), values
+}
+
+func formatPeriod(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func (MostBorrowed) ExcelHeaders() []string {
+	return []string{"Period", "Book ID", "Title", "Author", "Total Borrows", "Revenue (IDR)"}
+}
+
+func (r MostBorrowed) ExcelRow() []interface{} {
+	return []interface{}{formatPeriod(r.Period), r.BookID.String(), r.Title, r.Author, r.TotalBorrows, r.Revenue}
+}
+
+func (Revenue) ExcelHeaders() []string {
+	return []string{"Period", "Total Borrows", "Revenue (IDR)"}
+}
+
+func (r Revenue) ExcelRow() []interface{} {
+	return []interface{}{formatPeriod(&r.Period), r.TotalBorrows, r.Revenue}
+}
+
+func (MemberActivity) ExcelHeaders() []string {
+	return []string{"Period", "New Members", "Active Members"}
+}
+
+func (r MemberActivity) ExcelRow() []interface{} {
+	return []interface{}{formatPeriod(&r.Period), r.NewMembers, r.ActiveMembers}
+}
+
+func (ReturnTime) ExcelHeaders() []string {
+	return []string{"Period", "Returned Loans", "Average Days", "On Time Returns", "On Time Return (%)"}
+}
+
+func (r ReturnTime) ExcelRow() []interface{} {
+	return []interface{}{formatPeriod(&r.Period), r.ReturnedLoans, r.AverageDays, r.OnTimeReturns, r.OnTimeReturnPct}
+}
+
+func (Utilization) ExcelHeaders() []string {
+	return []string{"Period", "Book ID", "Title", "Total Copies", "On Loan", "Available Copies", "Availability", "Utilization"}
+}
+
+func (r Utilization) ExcelRow() []interface{} {
+	return []interface{}{formatPeriod(&r.Period), r.BookID.String(), r.Title, r.TotalCopies, r.OnLoan, r.AvailableCopies, r.Availability, r.Utilization}
+}
@@ -0,0 +1,39 @@
+package review
+
+import (
+	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+type ReviewRequest struct {
+	Rating  int    `json:"rating" validate:"required,min=1,max=5"`
+	Content string `json:"content" validate:"max=5000"`
+}
+
+type ModerateRequest struct {
+	Status string `json:"status" validate:"required,oneof=PUBLISHED HIDDEN"`
+	Note   string `json:"note"`
+}
+
+type Review struct {
+	model.Base
+	BookID         uuid.UUID `json:"book_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	Rating         int       `json:"rating"`
+	Content        string    `json:"content"`
+	Status         string    `json:"status"`
+	ModerationNote *string   `json:"moderation_note,omitempty"`
+}
+
+type ReviewDTO struct {
+	Review
+	UserName  string `json:"user_name"`
+	BookTitle string `json:"book_title"`
+}
+
+type ReviewQuery struct {
+	model.QueryParam
+	BookID uuid.UUID `query:"book_id,omitempty"`
+	UserID uuid.UUID `query:"user_id,omitempty"`
+	Status string    `query:"status,omitempty"`
+}
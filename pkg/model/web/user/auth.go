@@ -15,3 +15,8 @@ type LoginResponse struct {
 	RefreshToken string `json:"refresh_token,omitempty"`
 	UserData     *User  `json:"user_data,omitempty"`
 }
+
+type ProfileRequest struct {
+	Name     string `json:"name,omitempty" validate:"required"`
+	Timezone string `json:"timezone,omitempty"`
+}
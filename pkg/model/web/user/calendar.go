@@ -0,0 +1,7 @@
+package user
+
+// CalendarToken is shown once when created, only its hash is stored
+type CalendarToken struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
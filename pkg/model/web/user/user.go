@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/dikyayodihamzah/library-management-api/pkg/model"
+	"github.com/dikyayodihamzah/library-management-api/pkg/model/web/event"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -13,6 +14,8 @@ type User struct {
 	SignUpRequest
 	Role             string    `json:"role" db:"role"`
 	LastActivityDate time.Time `json:"last_activity_date" db:"last_activity_date"`
+	Timezone         string    `json:"timezone,omitempty" db:"timezone"` // IANA zone, empty mean default zone
+	CardNumber       string    `json:"card_number,omitempty" db:"card_number"`
 }
 
 func (u *User) GenerateID() {
@@ -28,3 +31,18 @@ func (u *User) HashPassword() error {
 	u.Password = string(hash)
 	return nil
 }
+
+// ToEvent return the payload of user events
+func (u *User) ToEvent() event.User {
+	e := event.User{
+		ID:         u.ID,
+		Name:       u.Name,
+		Email:      u.Email,
+		Role:       u.Role,
+		CardNumber: u.CardNumber,
+	}
+	if u.CreatedAt != nil {
+		e.CreatedAt = *u.CreatedAt
+	}
+	return e
+}
@@ -0,0 +1,190 @@
+// Package sip2 encode and decode 3M Standard Interchange Protocol 2.0 messages
+package sip2
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// message identifiers
+const (
+	PatronStatusRequest      = "23"
+	PatronStatusResponse     = "24"
+	CheckinRequest           = "09"
+	CheckinResponse          = "10"
+	CheckoutRequest          = "11"
+	CheckoutResponse         = "12"
+	ItemInformationRequest   = "17"
+	ItemInformationResponse  = "18"
+	PatronInformationRequest = "63"
+	PatronInformationResp    = "64"
+	LoginRequest             = "93"
+	LoginResponse            = "94"
+	RequestSCResend          = "96"
+	RequestACSResend         = "97"
+	ACSStatus                = "98"
+	SCStatus                 = "99"
+)
+
+// DateLayout is the 18 character transaction date, zone is left blank for local time
+const DateLayout = "20060102    150405"
+
+// fixed field length of each request, fields are read in order before the variable fields
+var fixedLengths = map[string][]int{
+	LoginRequest:             {1, 1},
+	SCStatus:                 {1, 3, 4},
+	PatronStatusRequest:      {3, 18},
+	PatronInformationRequest: {3, 18, 10},
+	CheckoutRequest:          {1, 1, 18, 18},
+	CheckinRequest:           {1, 18, 18},
+	ItemInformationRequest:   {18},
+	RequestACSResend:         {},
+}
+
+var ErrChecksum = errors.New("checksum mismatch")
+
+// Message is a decoded request or a response being built,
+// variable fields are kept in order and identified by their two letter code
+type Message struct {
+	ID       string
+	Fixed    []string
+	Fields   []Field
+	Sequence int // -1 when the message carries no error detection
+}
+
+type Field struct {
+	Code  string
+	Value string
+}
+
+// NewMessage start a response with its fixed fields
+func NewMessage(id string, fixed ...string) *Message {
+	return &Message{ID: id, Fixed: fixed, Sequence: -1}
+}
+
+// Add append a variable field
+func (m *Message) Add(code, value string) *Message {
+	m.Fields = append(m.Fields, Field{Code: code, Value: value})
+	return m
+}
+
+// Get return the first variable field with code
+func (m *Message) Get(code string) string {
+	for _, f := range m.Fields {
+		if f.Code == code {
+			return f.Value
+		}
+	}
+
+	return ""
+}
+
+// Decode parse a single message without its terminating carriage return,
+// checksum is verified when the message carries one
+func Decode(line string) (*Message, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) < 2 {
+		return nil, errors.New("message is too short")
+	}
+
+	m := &Message{ID: line[:2], Sequence: -1}
+
+	// error detection trail "AY<seq>AZ<checksum>"
+	if i := strings.LastIndex(line, "AY"); i >= 0 && len(line) == i+9 && line[i+3:i+5] == "AZ" {
+		if Checksum(line[:i+5]) != line[i+5:] {
+			return nil, ErrChecksum
+		}
+
+		seq, err := strconv.Atoi(line[i+2 : i+3])
+		if err != nil {
+			return nil, errors.New("invalid sequence number")
+		}
+
+		m.Sequence = seq
+		line = line[:i]
+	}
+
+	lengths, ok := fixedLengths[m.ID]
+	if !ok {
+		return nil, fmt.Errorf("unsupported message %q", m.ID)
+	}
+
+	rest := line[2:]
+	for _, n := range lengths {
+		if len(rest) < n {
+			return nil, fmt.Errorf("message %s is missing fixed fields", m.ID)
+		}
+
+		m.Fixed = append(m.Fixed, rest[:n])
+		rest = rest[n:]
+	}
+
+	for _, part := range strings.Split(rest, "|") {
+		if len(part) < 2 {
+			continue
+		}
+
+		m.Fields = append(m.Fields, Field{Code: part[:2], Value: part[2:]})
+	}
+
+	return m, nil
+}
+
+// Encode render the message terminated by a carriage return,
+// sequence and checksum are appended when sequence is not negative
+func (m *Message) Encode() string {
+	var b strings.Builder
+	b.WriteString(m.ID)
+	for _, f := range m.Fixed {
+		b.WriteString(f)
+	}
+
+	for _, f := range m.Fields {
+		b.WriteString(f.Code)
+		// field delimiter is not allowed inside values
+		b.WriteString(strings.ReplaceAll(f.Value, "|", " "))
+		b.WriteString("|")
+	}
+
+	if m.Sequence >= 0 {
+		b.WriteString("AY")
+		b.WriteString(strconv.Itoa(m.Sequence % 10))
+		b.WriteString("AZ")
+		b.WriteString(Checksum(b.String()))
+	}
+
+	b.WriteString("\r")
+	return b.String()
+}
+
+// Checksum is the two's complement of the sum of every byte, as four upper case hex digits
+func Checksum(s string) string {
+	var sum uint16
+	for i := 0; i < len(s); i++ {
+		sum += uint16(s[i])
+	}
+
+	return fmt.Sprintf("%04X", -sum)
+}
+
+// Date format t as transaction date in its own zone
+func Date(t time.Time) string {
+	return t.Format(DateLayout)
+}
+
+// Bool render a Y or N flag
+func Bool(v bool) string {
+	if v {
+		return "Y"
+	}
+
+	return "N"
+}
+
+// Count render a fixed four digit count
+func Count(n int) string {
+	return fmt.Sprintf("%04d", min(n, 9999))
+}
@@ -0,0 +1,38 @@
+package transaction
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TryLock take a session level advisory lock without waiting. The lock is held
+// by a dedicated connection of the pool until unlock is called
+func TryLock(c context.Context, db *pgxpool.Pool, key string) (unlock func() error, ok bool, err error) {
+	conn, err := db.Acquire(c)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := conn.QueryRow(c, `SELECT pg_try_advisory_lock(hashtext($1))`, key).Scan(&ok); err != nil {
+		conn.Release()
+		return nil, false, err
+	}
+
+	if !ok {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	unlock = func() error {
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, key); err != nil {
+			// closing the session is the only other way to release the lock
+			conn.Hijack().Close(context.Background())
+			return err
+		}
+		conn.Release()
+		return nil
+	}
+
+	return unlock, true, nil
+}
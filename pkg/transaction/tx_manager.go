@@ -22,7 +22,7 @@ func New(db *pgxpool.Pool) Manager {
 	return &manager{db: db}
 }
 
-func (m *manager) WithTx(c context.Context, callback func(tx pgx.Tx) error) error {
+func (m *manager) WithTx(c context.Context, callback func(tx pgx.Tx) error) (err error) {
 	// Start a transaction
 	tx, err := m.db.Begin(c)
 	if err != nil {
@@ -38,16 +38,12 @@ func (m *manager) WithTx(c context.Context, callback func(tx pgx.Tx) error) erro
 			tx.Rollback(c)
 		} else {
 			// Commit the transaction if everything is successful
-			if err := tx.Commit(c); err != nil {
+			if err = tx.Commit(c); err != nil {
 				tx.Rollback(c)
 			}
 		}
 	}()
 
 	// Execute the callback function with the transaction
-	if err := callback(tx); err != nil {
-		return err
-	}
-
-	return nil
+	return callback(tx)
 }